@@ -0,0 +1,168 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+var (
+	cacheCmd = &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the local node and location catalog",
+		Long: `Manage the local node and location catalog. The catalog is used to validate
+locations given with --from before a test is run and is refreshed automatically
+once a day.`,
+		Example: `perfops cache refresh`,
+	}
+
+	cacheRefreshCmd = &cobra.Command{
+		Use:   "refresh",
+		Short: "Download the node and location catalog",
+		Long:  `Download the node and location catalog and store it locally.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newPerfOpsClient()
+			if err != nil {
+				return err
+			}
+			return chkRunError(runCacheRefresh(c))
+		},
+	}
+
+	cacheClearCmd = &cobra.Command{
+		Use:   "clear",
+		Short: "Remove the local node and location catalog",
+		Long:  `Remove the local node and location catalog.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCacheClear()
+		},
+	}
+)
+
+func initCacheCmd(parentCmd *cobra.Command) {
+	cacheCmd.AddCommand(cacheRefreshCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	parentCmd.AddCommand(cacheCmd)
+}
+
+func runCacheRefresh(c *perfops.Client) error {
+	f := internal.NewFormatter(debug)
+	f.StartSpinner()
+	cat, err := refreshCatalog(c)
+	f.StopSpinner()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Catalog refreshed: %d nodes, %d countries, %d cities\n", len(cat.Nodes), len(cat.Countries), len(cat.Cities))
+	return nil
+}
+
+func runCacheClear() error {
+	p, err := catalogPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// catalogPath returns the path of the cached catalog file.
+func catalogPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "perfops", "catalog.json"), nil
+}
+
+// refreshCatalog fetches the catalog and stores it in the cache.
+func refreshCatalog(c *perfops.Client) (*internal.Catalog, error) {
+	p, err := catalogPath()
+	if err != nil {
+		return nil, err
+	}
+	cat, err := internal.FetchCatalog(context.Background(), c)
+	if err != nil {
+		return nil, err
+	}
+	return cat, cat.Save(p)
+}
+
+// loadCatalog returns the cached catalog and refreshes it once it has
+// expired. A stale catalog is returned if it cannot be refreshed, e.g.,
+// while offline.
+func loadCatalog(c *perfops.Client) (*internal.Catalog, error) {
+	p, err := catalogPath()
+	if err != nil {
+		return nil, err
+	}
+	cat, err := internal.LoadCatalog(p)
+	if err == nil && !cat.Expired(internal.CatalogTTL) {
+		return cat, nil
+	}
+	fresh, ferr := refreshCatalog(c)
+	if ferr != nil {
+		if cat != nil {
+			return cat, nil
+		}
+		return nil, ferr
+	}
+	return fresh, nil
+}
+
+// validateFrom checks the location given with --from against the
+// catalog before a test is run. No validation is done if no catalog is
+// available.
+func validateFrom(cmd *cobra.Command, args []string) error {
+	f := cmd.Flags().Lookup("from")
+	if f == nil || !f.Changed {
+		return nil
+	}
+	location, quotas, err := internal.ParseLocation(from)
+	if err != nil {
+		return err
+	}
+	c, err := newPerfOpsClient()
+	if err != nil {
+		return err
+	}
+	cat, err := loadCatalog(c)
+	if err != nil {
+		if debug {
+			fmt.Fprintf(os.Stderr, "Skipping location validation: %v\n", err)
+		}
+		return nil
+	}
+	if len(quotas) == 0 {
+		return cat.ValidateLocation(location)
+	}
+	for _, q := range quotas {
+		if err := cat.ValidateLocation(q.Location); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+)
+
+func TestInitCacheCmd(t *testing.T) {
+	parent := &cobra.Command{}
+	initCacheCmd(parent)
+	for _, name := range []string{"refresh", "clear"} {
+		if c, _, err := cacheCmd.Find([]string{name}); err != nil || c.Name() != name {
+			t.Fatalf("expected %v subcommand; got %v", name, err)
+		}
+	}
+}
+
+func TestRunCacheRefresh(t *testing.T) {
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	tr := &recordingTransport{}
+	c, err := newTestPerfopsClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	runCacheRefresh(c)
+	if got, exp := tr.req.URL.Path, "/nodes"; got != exp {
+		t.Fatalf("expected %v; got %v", exp, got)
+	}
+}
+
+func TestLoadCatalog(t *testing.T) {
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	tr := &recordingTransport{}
+	c, err := newTestPerfopsClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := loadCatalog(c); err == nil {
+		t.Fatal("expected error without cached catalog; got nil")
+	}
+
+	// A stale catalog is used if it cannot be refreshed.
+	p, err := catalogPath()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	stale := &internal.Catalog{Updated: time.Now().Add(-2 * internal.CatalogTTL)}
+	if err := stale.Save(p); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	cat, err := loadCatalog(c)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !cat.Updated.Equal(stale.Updated) {
+		t.Fatalf("expected stale catalog; got %v", cat.Updated)
+	}
+
+	if err := runCacheClear(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := os.Stat(p); !os.IsNotExist(err) {
+		t.Fatalf("expected catalog to be removed; got %v", err)
+	}
+}
@@ -0,0 +1,378 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+// completionAnnotation is the flag and command annotation naming the
+// completer for flag values and arguments.
+const completionAnnotation = "perfops_completion"
+
+const bashCompletion = `# bash completion for perfops
+
+__perfops_complete()
+{
+    local IFS=$'\n'
+    local candidates c
+    candidates=$("${COMP_WORDS[0]}" __complete "${COMP_WORDS[@]:1:$COMP_CWORD}" 2>/dev/null | cut -f1)
+    COMPREPLY=()
+    for c in $candidates; do
+        COMPREPLY+=("$(printf '%q' "$c")")
+    done
+}
+
+complete -o default -F __perfops_complete perfops
+`
+
+const zshCompletion = `#compdef perfops
+
+_perfops()
+{
+    local -a candidates
+    local line value desc
+    for line in "${(@f)$("${words[1]}" __complete "${(@)words[2,$CURRENT]}" 2>/dev/null)}"; do
+        [[ -z "$line" ]] && continue
+        value="${line%%$'\t'*}"
+        desc="${line#*$'\t'}"
+        if [[ "$desc" == "$line" ]]; then
+            candidates+=("${value//:/\\:}")
+        else
+            candidates+=("${value//:/\\:}:$desc")
+        fi
+    done
+    if (( ${#candidates} == 0 )); then
+        _files
+        return
+    fi
+    _describe -t values perfops candidates
+}
+
+if [[ "$funcstack[1]" == "_perfops" ]]; then
+    _perfops "$@"
+else
+    compdef _perfops perfops
+fi
+`
+
+const fishCompletion = `# fish completion for perfops
+
+function __perfops_complete
+    set -l args (commandline -opc)
+    set -e args[1]
+    perfops __complete $args (commandline -ct) 2>/dev/null
+end
+
+complete -c perfops -f -a '(__perfops_complete)'
+`
+
+var (
+	completionCmd = &cobra.Command{
+		Use:   "completion [bash|zsh|fish]",
+		Short: "Generate the shell completion script",
+		Long: `Generate the shell completion script for bash, zsh or fish.
+
+To load the completions in the current bash session run:
+
+  source <(perfops completion bash)
+
+For zsh, save the output as _perfops in a directory of your $fpath. For fish,
+save it as ~/.config/fish/completions/perfops.fish.
+
+Locations and node IDs are completed from the local catalog, see 'perfops cache'.`,
+		Example:   `perfops completion bash > /etc/bash_completion.d/perfops`,
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		Args:      cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCompletion(cmd.OutOrStdout(), args[0])
+		},
+	}
+
+	completeCmd = &cobra.Command{
+		Use:                "__complete [args]",
+		Short:              "Print the completion candidates for a command line",
+		Hidden:             true,
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, c := range completions(rootCmd, args) {
+				fmt.Fprintln(cmd.OutOrStdout(), c)
+			}
+			return nil
+		},
+	}
+
+	// completers return the candidates for a prefix. Each candidate may
+	// be followed by a tab and a description.
+	completers = map[string]func(prefix string) []string{
+		"color":     func(prefix string) []string { return filterPrefix(internal.ColorModes, prefix) },
+		"country":   completeCountries,
+		"groupkey":  func(prefix string) []string { return filterPrefix(internal.GroupKeys, prefix) },
+		"location":  completeLocations,
+		"nodeid":    completeNodeIDs,
+		"output":    func(prefix string) []string { return filterPrefix(internal.OutputFormats, prefix) },
+		"querytype": completeQueryTypes,
+		"sortkey":   func(prefix string) []string { return filterPrefix(internal.SortKeys, prefix) },
+		"viewmode":  func(prefix string) []string { return filterPrefix(internal.ViewModes, prefix) },
+		"testid":    completeTestIDs,
+		"testtype":  func(prefix string) []string { return filterPrefix(testTypes, prefix) },
+	}
+)
+
+func initCompletionCmd(parentCmd *cobra.Command) {
+	parentCmd.AddCommand(completionCmd)
+	parentCmd.AddCommand(completeCmd)
+}
+
+func runCompletion(w io.Writer, shell string) error {
+	switch shell {
+	case "bash":
+		_, err := io.WriteString(w, bashCompletion)
+		return err
+	case "zsh":
+		_, err := io.WriteString(w, zshCompletion)
+		return err
+	case "fish":
+		_, err := io.WriteString(w, fishCompletion)
+		return err
+	}
+	return fmt.Errorf("unsupported shell '%s'", shell)
+}
+
+// setFlagCompletion sets the completer used for the values of a flag.
+func setFlagCompletion(flags *flag.FlagSet, name, completer string) {
+	flags.SetAnnotation(name, completionAnnotation, []string{completer})
+}
+
+// completions returns the completion candidates for the arguments of a
+// command line. The last argument is the word being completed.
+func completions(root *cobra.Command, args []string) []string {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	cur, prev := args[len(args)-1], args[:len(args)-1]
+	cmd, _, _ := root.Find(prev)
+	if cmd == nil {
+		cmd = root
+	}
+	// Merges the inherited persistent flags into cmd.Flags().
+	cmd.InheritedFlags()
+	flags := cmd.Flags()
+
+	// --flag=value
+	if strings.HasPrefix(cur, "--") && strings.Contains(cur, "=") {
+		i := strings.Index(cur, "=")
+		f := flags.Lookup(cur[2:i])
+		if f == nil {
+			return nil
+		}
+		var res []string
+		for _, c := range flagValues(f, cur[i+1:]) {
+			res = append(res, cur[:i+1]+c)
+		}
+		return res
+	}
+	// --flag value, bash splits --flag=value into three words
+	if n := len(prev); n > 0 {
+		word := prev[n-1]
+		if word == "=" && n > 1 {
+			word = prev[n-2]
+		}
+		if f := valueFlag(flags, word); f != nil {
+			return flagValues(f, cur)
+		}
+	}
+	if strings.HasPrefix(cur, "-") {
+		var res []string
+		flags.VisitAll(func(f *flag.Flag) {
+			if name := "--" + f.Name; !f.Hidden && strings.HasPrefix(name, cur) {
+				res = append(res, name+"\t"+f.Usage)
+			}
+		})
+		return res
+	}
+
+	var res []string
+	for _, sub := range cmd.Commands() {
+		if sub.IsAvailableCommand() && strings.HasPrefix(sub.Name(), cur) {
+			res = append(res, sub.Name()+"\t"+sub.Short)
+		}
+	}
+	res = append(res, filterPrefix(cmd.ValidArgs, cur)...)
+	if complete, ok := completers[cmd.Annotations[completionAnnotation]]; ok {
+		res = append(res, complete(cur)...)
+	}
+	return res
+}
+
+// valueFlag returns the flag named by word if it expects a value.
+func valueFlag(flags *flag.FlagSet, word string) *flag.Flag {
+	var f *flag.Flag
+	if strings.HasPrefix(word, "--") {
+		f = flags.Lookup(word[2:])
+	} else if len(word) == 2 && word[0] == '-' {
+		f = flags.ShorthandLookup(word[1:])
+	}
+	if f == nil || f.NoOptDefVal != "" {
+		return nil
+	}
+	return f
+}
+
+// flagValues returns the candidates for the value of a flag. For list
+// flags only the last element is completed.
+func flagValues(f *flag.Flag, cur string) []string {
+	a := f.Annotations[completionAnnotation]
+	if len(a) == 0 {
+		return nil
+	}
+	complete, ok := completers[a[0]]
+	if !ok {
+		return nil
+	}
+	head := ""
+	if strings.HasSuffix(f.Value.Type(), "Slice") || perfops.IsLocationQuotas(cur) {
+		if i := strings.LastIndex(cur, ","); i >= 0 {
+			head, cur = cur[:i+1], cur[i+1:]
+		}
+	}
+	var res []string
+	for _, c := range complete(cur) {
+		res = append(res, head+c)
+	}
+	return res
+}
+
+func filterPrefix(values []string, prefix string) []string {
+	var res []string
+	for _, v := range values {
+		if hasPrefixFold(v, prefix) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// cachedCatalog returns the local catalog without refreshing it.
+func cachedCatalog() *internal.Catalog {
+	p, err := catalogPath()
+	if err != nil {
+		return nil
+	}
+	cat, err := internal.LoadCatalog(p)
+	if err != nil {
+		return nil
+	}
+	return cat
+}
+
+func completeLocations(prefix string) []string {
+	cat := cachedCatalog()
+	if cat == nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	var res []string
+	add := func(name, kind string) {
+		k := strings.ToLower(name)
+		if name == "" || seen[k] || !hasPrefixFold(name, prefix) {
+			return
+		}
+		seen[k] = true
+		res = append(res, name+"\t"+kind)
+	}
+	for _, n := range cat.Nodes {
+		add(n.ContinentName(), "continent")
+		add(n.SubRegion, "region")
+		add(n.CountryName(), "country")
+		add(n.City, "city, "+n.CountryName())
+	}
+	sort.Strings(res)
+	return res
+}
+
+func completeCountries(prefix string) []string {
+	cat := cachedCatalog()
+	if cat == nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	var res []string
+	for _, n := range cat.Nodes {
+		name := n.CountryName()
+		if name == "" || seen[name] || !hasPrefixFold(name, prefix) {
+			continue
+		}
+		seen[name] = true
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func completeNodeIDs(prefix string) []string {
+	cat := cachedCatalog()
+	if cat == nil {
+		return nil
+	}
+	var res []string
+	for _, n := range cat.Nodes {
+		if id := strconv.Itoa(n.ID); strings.HasPrefix(id, prefix) {
+			res = append(res, fmt.Sprintf("%s\t%s, %s (AS%d)", id, n.City, n.CountryName(), n.AsNumber))
+		}
+	}
+	return res
+}
+
+// completeQueryTypes completes the last query type of a comma separated
+// list.
+func completeQueryTypes(prefix string) []string {
+	list := ""
+	if i := strings.LastIndex(prefix, ","); i >= 0 {
+		list, prefix = prefix[:i+1], prefix[i+1:]
+	}
+	var res []string
+	for _, t := range filterPrefix(append(append([]string{}, internal.QueryTypes...), internal.QueryTypeAll), prefix) {
+		res = append(res, list+t)
+	}
+	return res
+}
+
+func completeTestIDs(prefix string) []string {
+	entries, err := readHistory()
+	if err != nil {
+		return nil
+	}
+	var res []string
+	for _, e := range recentHistory(entries, 20) {
+		if strings.HasPrefix(string(e.ID), prefix) {
+			res = append(res, fmt.Sprintf("%s\t%s %s (%s)", e.ID, e.Type, e.Target, e.Time.Local().Format("2006-01-02 15:04")))
+		}
+	}
+	return res
+}
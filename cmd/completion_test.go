@@ -0,0 +1,123 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func TestCompletions(t *testing.T) {
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+	os.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CONFIG_HOME")
+
+	p, err := catalogPath()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	cat := &internal.Catalog{Updated: time.Now(), Nodes: []*perfops.Node{
+		{ID: 5, AsNumber: 3320, City: "Frankfurt", SubRegion: "Western Europe",
+			Country: &perfops.Country{Name: "Germany", ISO: "DE", Continent: &perfops.Continent{Name: "Europe", ISO: "EU"}}},
+		{ID: 27, AsNumber: 9304, City: "Hong Kong", SubRegion: "Eastern Asia",
+			Country: &perfops.Country{Name: "Hong Kong", ISO: "HK", Continent: &perfops.Continent{Name: "Asia", ISO: "AS"}}},
+	}}
+	if err := cat.Save(p); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	hp, err := historyPath()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	ran := time.Date(2026, 10, 18, 10, 0, 0, 0, time.Local)
+	if err := internal.AppendHistory(hp, &internal.HistoryEntry{ID: "abc123", Type: "ping", Target: "example.com", Time: ran}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	root := &cobra.Command{Use: "perfops"}
+	pingCmd.ResetFlags()
+	initPingCmd(root)
+	dnsResolveCmd.ResetFlags()
+	initDNSResolveCmd(root)
+	fetchCmd.ResetFlags()
+	initFetchCmd(root)
+	initCompletionCmd(root)
+
+	testCases := map[string]struct {
+		args []string
+		exp  []string
+	}{
+		"Commands":        {[]string{"pi"}, []string{"ping\tRun a ping test on a domain name or IP address"}},
+		"Hidden":          {[]string{"__"}, nil},
+		"Flags":           {[]string{"ping", "--fr"}, []string{"--from\tA continent, region (e.g eastern europe), country, US state or city, or per-location node limits, e.g., Europe:5,Asia:3"}},
+		"Location":        {[]string{"ping", "--from", "fr"}, []string{"Frankfurt\tcity, Germany"}},
+		"Location short":  {[]string{"ping", "-F", "Eu"}, []string{"Europe\tcontinent"}},
+		"Location equals": {[]string{"ping", "--from=Ea"}, []string{"--from=Eastern Asia\tregion"}},
+		"Bash equals":     {[]string{"ping", "--from", "=", "Ea"}, []string{"Eastern Asia\tregion"}},
+		"Location quotas": {[]string{"ping", "--from", "Europe:5,Ea"}, []string{"Europe:5,Eastern Asia\tregion"}},
+		"Node IDs":        {[]string{"ping", "--nodeid", "5,2"}, []string{"5,27\tHong Kong, Hong Kong (AS9304)"}},
+		"Query type":      {[]string{"resolve", "--type", "a"}, []string{"A", "AAAA", "ALL"}},
+		"Query type list": {[]string{"resolve", "--type", "A,M"}, []string{"A,MX"}},
+		"Test IDs":        {[]string{"fetch", ""}, []string{"abc123\tping example.com (2026-10-18 10:00)"}},
+		"Test types":      {[]string{"fetch", "--type", "tr"}, []string{"traceroute"}},
+		"Shells":          {[]string{"completion", "z"}, []string{"zsh"}},
+		"Bool flag":       {[]string{"ping", "--json", ""}, nil},
+		"Sort keys":       {[]string{"ping", "--sort", "c"}, []string{"city", "country"}},
+		"Group keys":      {[]string{"ping", "--group-by", "a"}, []string{"asn"}},
+		"View modes":      {[]string{"ping", "--view", "m"}, []string{"map"}},
+		"Output formats":  {[]string{"fetch", "--output", "g"}, []string{"geojson"}},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if got := completions(root, tc.args); !reflect.DeepEqual(got, tc.exp) {
+				t.Fatalf("expected %q; got %q", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestRunCompletion(t *testing.T) {
+	testCases := map[string]struct {
+		shell string
+		exp   string
+	}{
+		"bash": {"bash", "complete -o default -F __perfops_complete perfops\n"},
+		"zsh":  {"zsh", "compdef _perfops perfops\n"},
+		"fish": {"fish", "complete -c perfops -f -a '(__perfops_complete)'\n"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := runCompletion(&b, tc.shell); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if got := b.String(); !strings.Contains(got, tc.exp) {
+				t.Fatalf("expected %q in %q", tc.exp, got)
+			}
+		})
+	}
+	if err := runCompletion(&bytes.Buffer{}, "tcsh"); err == nil {
+		t.Fatal("expected error for unsupported shell; got nil")
+	}
+}
@@ -15,7 +15,12 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -25,37 +30,162 @@ import (
 
 var (
 	creditsCmd = &cobra.Command{
-		Use:     "credits",
-		Short:   "Displays the remaing credits",
-		Long:    `Displays the remaing credits.`,
-		Example: `perfops remaining-credits`,
+		Use:   "credits",
+		Short: "Displays the remaing credits",
+		Long: `Displays the remaing credits, the plan and the time the credits are reset, if available.
+With --watch the remaining credits are shown whenever they change.`,
+		Example: `perfops credits --watch --interval 10s`,
+		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c, err := newPerfOpsClient()
 			if err != nil {
 				return err
 			}
-			return chkRunError(runCredits(c))
+			return chkRunError(runCredits(c, creditsWatch, creditsInterval))
 		},
 	}
+
+	creditsWatch    bool
+	creditsInterval time.Duration
+
+	// maxCredits is the maximum number of credits a test may use.
+	maxCredits int
 )
 
 func initCreditsCmd(parentCmd *cobra.Command) {
+	creditsCmd.Flags().BoolVarP(&creditsWatch, "watch", "w", false, "Show the remaining credits whenever they change")
+	creditsCmd.Flags().DurationVarP(&creditsInterval, "interval", "i", 30*time.Second, "The interval to check the remaining credits in with --watch")
+	creditsCmd.Flags().BoolVarP(&outputJSON, "json", "J", false, "Print the result of a command in JSON format")
 	parentCmd.AddCommand(creditsCmd)
 }
 
-func runCredits(c *perfops.Client) error {
+func runCredits(c *perfops.Client, watch bool, interval time.Duration) error {
 	ctx := context.Background()
+	if watch {
+		if interval <= 0 {
+			return fmt.Errorf("invalid interval %v", interval)
+		}
+		return watchCredits(ctx, os.Stdout, interval, func() (*perfops.Credits, error) {
+			return c.Account.Credits(ctx)
+		})
+	}
 
 	spinner := internal.NewSpinner()
-	fmt.Println("")
+	if internal.Progress() {
+		fmt.Println("")
+	}
 	spinner.Start()
 
-	credits, err := c.DNS.RemainingCredits(ctx)
+	credits, err := c.Account.Credits(ctx)
 	spinner.Stop()
 	if err != nil {
 		return err
 	}
 
+	if outputJSON {
+		return internal.PrintOutputJSON(credits)
+	}
 	fmt.Printf("Remaining credits: %v\n", credits)
+	if credits.Plan != "" {
+		fmt.Printf("Plan: %s\n", credits.Plan)
+	}
+	if credits.ResetAt != nil {
+		fmt.Printf("Reset: %s\n", credits.ResetAt.Local().Format("2006-01-02 15:04:05"))
+	}
 	return nil
 }
+
+// watchCredits writes the remaining credits to w whenever they change
+// until ctx is done. Failing to retrieve the credits only fails the first
+// time.
+func watchCredits(ctx context.Context, w io.Writer, interval time.Duration, fetch func() (*perfops.Credits, error)) error {
+	var last *perfops.Credits
+	for {
+		credits, err := fetch()
+		if err != nil {
+			if last == nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Failed to retrieve the remaining credits: %v\n", err)
+		} else if last == nil || credits.String() != last.String() {
+			line := fmt.Sprintf("%s  Remaining credits: %v", time.Now().Format("2006-01-02 15:04:05"), credits)
+			if last != nil && !credits.Unlimited && !last.Unlimited {
+				line += fmt.Sprintf(" (%+d)", credits.Remaining-last.Remaining)
+			}
+			if outputJSON {
+				b, err := json.Marshal(credits)
+				if err != nil {
+					return err
+				}
+				line = string(b)
+			}
+			fmt.Fprintln(w, line)
+			last = credits
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// remainingCredits returns the remaining credits of the API key. Unlimited
+// credits are reported as an error as they cannot be accounted for.
+func remainingCredits(c *perfops.Client) (int, error) {
+	credits, err := c.Account.Credits(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	if credits.Unlimited {
+		return 0, errors.New("unlimited credits")
+	}
+	return credits.Remaining, nil
+}
+
+// creditAccount returns the credit account of a test. It limits the
+// credits of the test to --max-credits and the rest of the daily budget of
+// the config file, and records the credits used in the history. The
+// credits of the test are estimated with the costs recorded in the history
+// and the credit_costs of the config file.
+func creditAccount(c *perfops.Client) (*internal.CreditAccount, error) {
+	if maxCredits < 0 {
+		return nil, fmt.Errorf("invalid maximum of credits %d", maxCredits)
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := readHistory()
+	if err != nil {
+		return nil, err
+	}
+	costs := internal.CreditCosts(entries)
+	for t, c := range cfg.CreditCosts {
+		costs[t] = c
+	}
+	return &internal.CreditAccount{
+		MaxCredits:          maxCredits,
+		DailyBudget:         cfg.DailyCreditBudget,
+		UsedToday:           internal.CreditsUsedOn(entries, time.Now()),
+		Costs:               costs,
+		CheckRemaining:      dryRun,
+		SettleFromRemaining: cfg.SettleFromRemaining,
+		Remaining: func() (int, error) {
+			return remainingCredits(c)
+		},
+		Record: recordCredits,
+	}, nil
+}
+
+// recordCredits records the credits a test used and the number of its
+// nodes in the local history. Failing to do so does not fail the test.
+func recordCredits(testID perfops.TestID, credits, nodes int) {
+	p, err := historyPath()
+	if err == nil {
+		err = internal.SetHistoryCredits(p, testID, credits, nodes)
+	}
+	if err != nil && debug {
+		fmt.Fprintf(os.Stderr, "Failed to record credits in history: %v\n", err)
+	}
+}
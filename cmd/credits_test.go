@@ -14,9 +14,16 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
 )
 
 func TestInitCredits(t *testing.T) {
@@ -45,8 +52,37 @@ func TestRunCredits(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error %v", err)
 	}
-	runCredits(c)
+	runCredits(c, false, 0)
 	if got, exp := tr.req.URL.Path, "/remaining-credits"; got != exp {
 		t.Fatalf("expected %v; got %v", exp, got)
 	}
 }
+
+func TestWatchCredits(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	remaining := []int{10, 10, 7, 7}
+	fetch := func() (*perfops.Credits, error) {
+		if len(remaining) == 0 {
+			cancel()
+			return nil, errors.New("done")
+		}
+		c := &perfops.Credits{Remaining: remaining[0]}
+		remaining = remaining[1:]
+		return c, nil
+	}
+	var buf bytes.Buffer
+	if err := watchCredits(ctx, &buf, time.Millisecond, fetch); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "Remaining credits: 10") || !strings.HasSuffix(lines[1], "Remaining credits: 7 (-3)") {
+		t.Fatalf("expected a line per change; got %q", buf.String())
+	}
+
+	exp := errors.New("failed")
+	err := watchCredits(context.Background(), &buf, time.Millisecond, func() (*perfops.Credits, error) { return nil, exp })
+	if err != exp {
+		t.Fatalf("expected %v; got %v", exp, err)
+	}
+}
@@ -15,6 +15,8 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -56,6 +58,7 @@ func initCurlCmd(parentCmd *cobra.Command) {
 	curlCmd.Flags().IntVarP(&curlLimit, "limit", "L", 1, "The maximum number of nodes to use")
 	curlCmd.Flags().StringVarP(&fileOut, "file", "f", "", "output to file")
 	curlCmd.Flags().BoolVarP(&curlIpv6, "ipv6", "6", false, "Use IPv6")
+	curlCmd.Flags().BoolVarP(&resultView.Aggregate, "aggregate", "", false, aggregateUsage)
 
 	parentCmd.AddCommand(curlCmd)
 }
@@ -67,26 +70,83 @@ func runCurl(c *perfops.Client, target string, head, insecure, http2 bool, from
 	if ipv6 {
 		ipversion = 6
 	}
+	from, nodeIDs, limit, err := selectNodes(c, "curl", from, nodeIDs, limit)
+	if err != nil {
+		return err
+	}
+	if err := prepareView(c); err != nil {
+		return err
+	}
+	location, quotas, err := internal.ParseLocation(from)
+	if err != nil {
+		return err
+	}
 	curlReq := &perfops.CurlRequest{
 		Target:    target,
 		Head:      head,
 		Insecure:  insecure,
 		HTTP2:     http2,
-		Location:  from,
+		Location:  location,
 		Nodes:     nodeIDs,
 		Limit:     limit,
+		Quotas:    quotas,
 		IPVersion: ipversion,
 	}
 
-	f := internal.NewFormatter(debug && !outputJSON)
+	if dryRun {
+		return printPlan(c, "curl", target, from, nodeIDs, limit, curlReq)
+	}
+	if err := resultView.Credits.Check(internal.NewPlan("curl", target, location, quotas, nodeIDs, limit)); err != nil {
+		return err
+	}
+
+	format := outputFormat()
+	f := internal.NewFormatter(debug && format == internal.OutputText)
 
 	f.StartSpinner()
+	started := time.Now()
 	testID, err := c.Run.Curl(ctx, curlReq)
 	f.StopSpinner()
+	// Started sub-tests are recorded if a later one failed to start.
+	recordHistory("curl", target, testID)
 	if err != nil {
 		return err
 	}
 
+	var o *perfops.RunOutput
+	if resultView.TUI {
+		title := fmt.Sprintf("curl %s (%s)", target, testID)
+		if o, err = internal.WatchRun(ctx, title, "curl", testID, c.Run.CurlOutput); err != nil {
+			return err
+		}
+		if o == nil || !o.IsFinished() {
+			internal.PrintStillRunning(os.Stdout, testID)
+			return nil
+		}
+	} else if o, err = waitCurlOutput(ctx, c, f, format, testID); err != nil {
+		return err
+	}
+	if len(fileOut) > 0 {
+		f.StopSpinner()
+		internal.OutputToFile(f, o, fileOut)
+	}
+	f.StopSpinner()
+	return internal.FinishTest(&internal.FinishedTest{
+		ID:               testID,
+		Type:             "curl",
+		Target:           target,
+		Started:          started,
+		CreditsWithdrawn: o.CreditsWithdrawn,
+		Nodes:            len(o.NodeIDs()),
+		Results:          internal.RunResults("curl", o),
+		JSON:             internal.NewRunJSON("curl", o),
+		PrintResults:     resultView.TUI,
+	}, format, &resultView)
+}
+
+// waitCurlOutput polls the output of a curl test until it finished. Text
+// output is printed as it arrives.
+func waitCurlOutput(ctx context.Context, c *perfops.Client, f *internal.Formatter, format string, testID perfops.TestID) (*perfops.RunOutput, error) {
 	res := &internal.RunOutputResult{}
 	go func() {
 		for {
@@ -102,30 +162,25 @@ func runCurl(c *perfops.Client, target string, head, insecure, http2 bool, from
 	}()
 
 	f.StartSpinner()
-	var o *perfops.RunOutput
 	for {
 		select {
 		case <-time.After(100 * time.Millisecond):
 		}
-		if o, err = res.Output(); err != nil {
-			return err
+		o, err := res.Output()
+		if err != nil {
+			return nil, err
 		}
 
-		if !outputJSON && o != nil {
+		if format == internal.OutputText && o != nil && (!internal.Quiet() || o.IsFinished()) {
 			f.StopSpinner()
-			internal.PrintOutput(f, o)
+			if o.IsFinished() && resultView.IsSet() {
+				internal.PrintOutputView(f, "curl", o, &resultView)
+			} else {
+				internal.PrintOutput(f, o)
+			}
 		}
 		if o != nil && o.IsFinished() {
-			break
+			return o, nil
 		}
 	}
-	if len(fileOut) > 0 {
-		f.StopSpinner()
-		internal.OutputToFile(f, o, fileOut)
-	}
-	if outputJSON {
-		f.StopSpinner()
-		internal.PrintOutputJSON(o)
-	}
-	return nil
 }
@@ -0,0 +1,147 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+var dnsCmd = &cobra.Command{
+	Use:   "dns",
+	Short: "Check DNS records from many nodes",
+	Long:  `Check DNS records from many nodes, e.g., the propagation of a change or the answers of each region.`,
+}
+
+func initDNSCmd(parentCmd *cobra.Command) {
+	initDNSPropagationCmd(dnsCmd)
+	initDNSGeoMapCmd(dnsCmd)
+	parentCmd.AddCommand(dnsCmd)
+}
+
+// checkDNSResolveTests validates DNS resolve tests run on the same nodes
+// and checks their estimated credits. With --dry-run their plan is printed
+// instead and done is set.
+func checkDNSResolveTests(c *perfops.Client, target, from string, nodeIDs []int, limit int, reqs []*perfops.DNSResolveRequest) (done bool, err error) {
+	tests := make([]interface{}, len(reqs))
+	for i, req := range reqs {
+		tests[i] = req
+	}
+	return checkDNSTests(c, "resolve", target, from, nodeIDs, limit, tests)
+}
+
+// checkDNSTests validates DNS tests of a type run on the same nodes and
+// checks their estimated credits. With --dry-run their plan is printed
+// instead and done is set.
+func checkDNSTests(c *perfops.Client, testType, target, from string, nodeIDs []int, limit int, reqs []interface{}) (done bool, err error) {
+	if dryRun {
+		return true, printTestsPlan(c, testType, target, from, nodeIDs, limit, reqs)
+	}
+	for _, req := range reqs {
+		if err := c.Run.Validate(req); err != nil {
+			return false, err
+		}
+	}
+	location, quotas, err := internal.ParseLocation(from)
+	if err != nil {
+		return false, err
+	}
+	return false, resultView.Credits.Check(internal.NewPlan(testType, target, location, quotas, nodeIDs, limit).Repeat(len(reqs)))
+}
+
+// runDNSResolveTests submits a DNS resolve test per request and waits for
+// them to finish. The tests are recorded in the history and their credits
+// settled.
+func runDNSResolveTests(c *perfops.Client, reqs []*perfops.DNSResolveRequest) ([]*perfops.DNSTestOutput, error) {
+	runs := make([]func(ctx context.Context) (perfops.TestID, error), len(reqs))
+	for i, req := range reqs {
+		req := req
+		runs[i] = func(ctx context.Context) (perfops.TestID, error) {
+			return c.Run.DNSResolve(ctx, req)
+		}
+	}
+	return runDNSTests("resolve", reqs[0].Target, runs, c.Run.DNSResolveOutput)
+}
+
+// runDNSPerfTests submits a DNS perf test per request and waits for them to
+// finish. The tests are recorded in the history and their credits settled.
+func runDNSPerfTests(c *perfops.Client, reqs []*perfops.DNSPerfRequest) ([]*perfops.DNSTestOutput, error) {
+	runs := make([]func(ctx context.Context) (perfops.TestID, error), len(reqs))
+	for i, req := range reqs {
+		req := req
+		runs[i] = func(ctx context.Context) (perfops.TestID, error) {
+			return c.Run.DNSPerf(ctx, req)
+		}
+	}
+	return runDNSTests("dnsperf", reqs[0].Target, runs, c.Run.DNSPerfOutput)
+}
+
+// runDNSTests submits a DNS test of a type per run function and waits for
+// them to finish.
+func runDNSTests(testType, target string, runs []func(ctx context.Context) (perfops.TestID, error), getOutput func(ctx context.Context, testID perfops.TestID) (*perfops.DNSTestOutput, error)) ([]*perfops.DNSTestOutput, error) {
+	ctx := context.Background()
+	spinner := internal.NewSpinner()
+	if internal.Progress() {
+		fmt.Println("")
+	}
+	spinner.Start()
+	testIDs := make([]perfops.TestID, len(runs))
+	for i, run := range runs {
+		testID, err := run(ctx)
+		recordHistory(testType, target, testID)
+		if err != nil {
+			spinner.Stop()
+			return nil, err
+		}
+		testIDs[i] = testID
+	}
+	spinner.Stop()
+	if debug && outputFormat() == internal.OutputText {
+		fmt.Printf("Test IDs: %v\n", testIDs)
+	}
+
+	outputs := make([]*perfops.DNSTestOutput, len(runs))
+	withdrawn := make([]*int, len(runs))
+	nodes := make([]int, len(runs))
+	for i, testID := range testIDs {
+		output, err := waitDNSOutput(ctx, spinner, testID, false, getOutput, nil)
+		if err != nil {
+			return nil, err
+		}
+		outputs[i], withdrawn[i], nodes[i] = output, output.CreditsWithdrawn, len(output.NodeIDs())
+	}
+	resultView.Credits.SettleTests(testIDs, withdrawn, nodes)
+	return outputs, nil
+}
+
+// splitDNSServers returns the DNS servers of a comma separated list.
+func splitDNSServers(servers []string) []string {
+	var res []string
+	seen := map[string]bool{}
+	for _, s := range servers {
+		for _, v := range strings.Split(s, ",") {
+			if v = strings.TrimSpace(v); v != "" && !seen[v] {
+				seen[v] = true
+				res = append(res, v)
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,152 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+)
+
+// dnsTransport runs DNS resolve and DNS perf tests with the test ID being
+// the DNS server and the query type of resolve tests, e.g., "8.8.8.8_A" or
+// "8.8.8.8", and answers from the outputs by test ID.
+type dnsTransport struct {
+	outputs map[string]string
+	servers []string
+	params  []string
+	nodes   []string
+}
+
+func (t *dnsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, code := `{"error":"not found"}`, http.StatusNotFound
+	switch {
+	case req.Method == "POST" && (req.URL.Path == "/run/dns-resolve" || req.URL.Path == "/run/dns-perf"):
+		var r struct {
+			DNSServer string `json:"dnsServer"`
+			Param     string `json:"param"`
+			Nodes     string `json:"nodes"`
+		}
+		json.NewDecoder(req.Body).Decode(&r)
+		t.servers = append(t.servers, r.DNSServer)
+		t.params = append(t.params, r.Param)
+		t.nodes = append(t.nodes, r.Nodes)
+		id := r.DNSServer
+		if r.Param != "" {
+			id += "_" + r.Param
+		}
+		body, code = `{"id":"`+id+`"}`, http.StatusOK
+	case strings.HasPrefix(req.URL.Path, "/run/dns-resolve/") || strings.HasPrefix(req.URL.Path, "/run/dns-perf/"):
+		id := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+		server := strings.Split(id, "_")[0]
+		body = `{"id":"` + id + `","finished":true,"items":[{"id":"1","result":{"dnsServer":"` + server + `","output":` + t.outputs[id] + `,"node":{"id":5}}}]}`
+		code = http.StatusOK
+	}
+	return &http.Response{
+		StatusCode: code,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestInitDNSCmd(t *testing.T) {
+	parent := &cobra.Command{}
+	initDNSCmd(parent)
+	cmd, _, err := parent.Find([]string{"dns", "propagation"})
+	if err != nil || cmd != dnsPropagationCmd {
+		t.Fatalf("expected propagation command; got %v, %v", cmd, err)
+	}
+	if cmd, _, err := parent.Find([]string{"dns", "geomap"}); err != nil || cmd != dnsGeoMapCmd {
+		t.Fatalf("expected geomap command; got %v, %v", cmd, err)
+	}
+	if err := dnsPropagationCmd.ParseFlags([]string{"--expect", "1.2.3.4", "--dns-server", "127.0.0.1,8.8.8.8", "--type", "AAAA"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got, exp := strings.Join(dnsPropagationServers, ","), "127.0.0.1,8.8.8.8"; got != exp {
+		t.Fatalf("expected %v; got %v", exp, got)
+	}
+	if dnsPropagationType != "AAAA" || dnsPropagationLimit != 20 || len(dnsPropagationExpect) != 1 {
+		t.Fatalf("unexpected flags %v, %v, %v", dnsPropagationType, dnsPropagationLimit, dnsPropagationExpect)
+	}
+}
+
+func TestRunDNSPropagation(t *testing.T) {
+	os.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CONFIG_HOME")
+	tr := &dnsTransport{outputs: map[string]string{
+		"127.0.0.1_A": `["1.2.3.4"]`,
+		"8.8.8.8_A":   `["5.6.7.8"]`,
+	}}
+	c, err := newTestPerfopsClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	outputJSON = true
+	defer func() { outputJSON = false }()
+	if err := runDNSPropagation(c, "example.com", "a", []string{"1.2.3.4"}, []string{"127.0.0.1, 8.8.8.8", "8.8.8.8"}, "", []int{5, 12}, 20); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got, exp := strings.Join(tr.servers, ","), "127.0.0.1,8.8.8.8"; got != exp {
+		t.Fatalf("expected a test per DNS server %v; got %v", exp, got)
+	}
+	if got, exp := strings.Join(tr.nodes, ","), "5,12,5,12"; got != exp {
+		t.Fatalf("expected the same nodes %v; got %v", exp, got)
+	}
+	entries, err := readHistory()
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected the tests in the history; got %v, %v", entries, err)
+	}
+
+	if err := runDNSPropagation(c, "example.com", "A", nil, []string{"127.0.0.1"}, "", nil, 20); err == nil {
+		t.Fatal("expected an error without expected value")
+	}
+}
+
+func TestRunDNSGeoMap(t *testing.T) {
+	os.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CONFIG_HOME")
+	tr := &dnsTransport{outputs: map[string]string{
+		"127.0.0.1_AAAA": `["2606:2800:220:1::"]`,
+	}}
+	c, err := newTestPerfopsClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	outputJSON = true
+	defer func() { outputJSON = false }()
+	if err := runDNSGeoMap(c, "example.com", "aaaa", "127.0.0.1", "", []int{5, 12}, 50); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got, exp := strings.Join(tr.params, ","), "AAAA"; got != exp {
+		t.Fatalf("expected %v; got %v", exp, got)
+	}
+	if got, exp := strings.Join(tr.nodes, ","), "5,12"; got != exp {
+		t.Fatalf("expected nodes %v; got %v", exp, got)
+	}
+
+	outputJSON = false
+	testOutput = internal.OutputKML
+	defer func() { testOutput = internal.OutputText }()
+	if err := runDNSGeoMap(c, "example.com", "A", "127.0.0.1", "", []int{5}, 50); err == nil {
+		t.Fatal("expected an error for KML output")
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+var (
+	dnsGeoMapCmd = &cobra.Command{
+		Use:   "geomap [name]",
+		Short: "Show which answers of a DNS record each region gets",
+		Long: `Show which answers of a DNS record each region gets, e.g., for geo-steered or anycast DNS.
+
+The record is resolved from nodes spread evenly over the continents and
+countries, and the nodes are grouped by the answers they got. Each answer set
+is shown with the countries, cities and autonomous systems receiving it, as a
+list, on a world map with --view map or as GeoJSON with --output geojson.`,
+		Example: `perfops dns geomap --limit 60 www.example.com
+perfops dns geomap --type AAAA --view map www.example.com
+perfops dns geomap --output geojson www.example.com > answers.geojson`,
+		Args: requireTarget(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newPerfOpsClient()
+			if err != nil {
+				return err
+			}
+			return chkRunError(runDNSGeoMap(c, args[0], dnsGeoMapType, dnsGeoMapDNSServer, from, nodeIDs, dnsGeoMapLimit))
+		},
+	}
+
+	dnsGeoMapType      string
+	dnsGeoMapDNSServer string
+	dnsGeoMapLimit     int
+)
+
+func initDNSGeoMapCmd(parentCmd *cobra.Command) {
+	addNodeFlags(dnsGeoMapCmd)
+	dnsGeoMapCmd.Flags().StringVarP(&dnsGeoMapType, "type", "T", "A", "The DNS query type. One of: A, AAAA, CNAME, MX, NAPTR, NS, PTR, SOA, SPF, SRV, TXT.")
+	dnsGeoMapCmd.Flags().StringVarP(&dnsGeoMapDNSServer, "dns-server", "S", "127.0.0.1", "The DNS server to query, 127.0.0.1 for the local resolver of each node")
+	dnsGeoMapCmd.Flags().IntVarP(&dnsGeoMapLimit, "limit", "L", 50, "The maximum number of nodes to use")
+	dnsGeoMapCmd.Flags().BoolVarP(&outputJSON, "json", "J", false, "Print the result of a command in JSON format")
+	dnsGeoMapCmd.Flags().StringVarP(&testOutput, "output", "o", internal.OutputText, "The output format. One of: text, json, geojson")
+	dnsGeoMapCmd.Flags().StringVarP(&resultView.Mode, "view", "", internal.ViewList, "Show the answer sets as a list or on a world map, one of: "+strings.Join(internal.ViewModes, ", "))
+	dnsGeoMapCmd.Flags().IntVarP(&maxCredits, "max-credits", "", 0, "Refuse to run the test if it needs more than this number of credits")
+	dnsGeoMapCmd.Flags().BoolVarP(&dryRun, "dry-run", "", false, "Validate the test, select its nodes and estimate its credits without running it")
+
+	setFlagCompletion(dnsGeoMapCmd.Flags(), "type", "querytype")
+	setFlagCompletion(dnsGeoMapCmd.Flags(), "view", "viewmode")
+
+	parentCmd.AddCommand(dnsGeoMapCmd)
+}
+
+func runDNSGeoMap(c *perfops.Client, target, queryType, dnsServer, from string, nodeIDs []int, limit int) error {
+	queryType = strings.ToUpper(queryType)
+	format := outputFormat()
+	switch format {
+	case internal.OutputText, internal.OutputJSON, internal.OutputGeoJSON:
+	default:
+		return fmt.Errorf("unsupported output format '%s'", format)
+	}
+	if resultView.Mode != internal.ViewList && resultView.Mode != internal.ViewMap {
+		return fmt.Errorf("unsupported view '%s'", resultView.Mode)
+	}
+	credits, err := creditAccount(c)
+	if err != nil {
+		return err
+	}
+	resultView.Credits = credits
+	nodeIDs, err = balancedNodes(c, "resolve", from, nodeIDs, limit)
+	if err != nil {
+		return err
+	}
+	reqs := []*perfops.DNSResolveRequest{{
+		Target:    target,
+		Param:     queryType,
+		DNSServer: dnsServer,
+		Nodes:     nodeIDs,
+		Limit:     len(nodeIDs),
+	}}
+	if done, err := checkDNSResolveTests(c, target, "", nodeIDs, len(nodeIDs), reqs); done || err != nil {
+		return err
+	}
+
+	outputs, err := runDNSResolveTests(c, reqs)
+	if err != nil {
+		return err
+	}
+	g := internal.NewGeoMap(target, queryType, dnsServer, outputs[0])
+	var nodes []*perfops.Node
+	if format == internal.OutputGeoJSON || resultView.Mode == internal.ViewMap {
+		cat, err := loadCatalog(c)
+		if err != nil {
+			return err
+		}
+		nodes = cat.Nodes
+	}
+	switch {
+	case format == internal.OutputJSON:
+		g.CreditsUsed = resultView.Credits.Used()
+		return internal.PrintOutputJSON(g)
+	case format == internal.OutputGeoJSON:
+		return internal.WriteGeoMapGeoJSON(os.Stdout, g, nodes)
+	case resultView.Mode == internal.ViewMap:
+		if err := internal.PrintGeoMapMap(os.Stdout, g, nodes); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+	if err := internal.PrintGeoMap(os.Stdout, g); err != nil {
+		return err
+	}
+	return internal.PrintCreditsUsed(os.Stdout, resultView.Credits)
+}
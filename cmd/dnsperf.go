@@ -15,7 +15,9 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -26,11 +28,16 @@ import (
 
 var (
 	dnsPerfCmd = &cobra.Command{
-		Use:     "dnsperf [target]",
-		Short:   "Find the time it takes to resolve a DNS record on a target",
-		Long:    `Find the time it takes to resolve a DNS record on a target, e.g., google.com.`,
-		Example: `perfops dnsperf bing.com`,
-		Args:    requireTarget(),
+		Use:   "dnsperf [target]",
+		Short: "Find the time it takes to resolve a DNS record on a target",
+		Long: `Find the time it takes to resolve a DNS record on a target, e.g., google.com.
+
+With a comma separated list of DNS servers the target is resolved with each
+of them from the same nodes and the DNS servers are ranked by their median
+resolve time, compared by continent and by node.`,
+		Example: `perfops dnsperf bing.com
+perfops dnsperf --dns-server 8.8.8.8,1.1.1.1,9.9.9.9,127.0.0.1 --limit 20 bing.com`,
+		Args: requireTarget(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c, err := newPerfOpsClient()
 			if err != nil {
@@ -48,9 +55,10 @@ var (
 func initDNSPerfCmd(parentCmd *cobra.Command) {
 	addCommonFlags(dnsPerfCmd)
 
-	dnsPerfCmd.Flags().StringVarP(&dnsPerfDNSServer, "dns-server", "S", "", "The DNS server to use to query for the test. You can use 127.0.0.1 to use the local resolver for location based benchmarking.")
+	dnsPerfCmd.Flags().StringVarP(&dnsPerfDNSServer, "dns-server", "S", "", "The DNS server to use to query for the test. You can use 127.0.0.1 to use the local resolver for location based benchmarking. A comma separated list compares the DNS servers from the same nodes.")
 	dnsPerfCmd.Flags().IntVarP(&dnsPerfLimit, "limit", "L", 1, "The maximum number of nodes to use")
 	dnsPerfCmd.Flags().BoolVarP(&dnsPerfIpv6, "ipv6", "6", false, "Use IPv6")
+	dnsPerfCmd.Flags().BoolVarP(&resultView.Aggregate, "aggregate", "", false, aggregateUsage)
 
 	parentCmd.AddCommand(dnsPerfCmd)
 }
@@ -61,54 +69,138 @@ func runDNSPerf(c *perfops.Client, target, dnsServer, from string, nodeIDs []int
 	if ipv6 {
 		ipversion = 6
 	}
+	servers := splitDNSServers([]string{dnsServer})
+	switch {
+	case len(servers) > 1:
+		return runDNSPerfServers(c, target, servers, from, nodeIDs, limit, ipversion)
+	case len(servers) == 1:
+		dnsServer = servers[0]
+	case dnsServer != "":
+		return fmt.Errorf("no DNS server in '%s'", dnsServer)
+	}
+	from, nodeIDs, limit, err := selectNodes(c, "dnsperf", from, nodeIDs, limit)
+	if err != nil {
+		return err
+	}
+	if err := prepareView(c); err != nil {
+		return err
+	}
+	location, quotas, err := internal.ParseLocation(from)
+	if err != nil {
+		return err
+	}
 	dnsPerfReq := &perfops.DNSPerfRequest{
 		Target:    target,
 		DNSServer: dnsServer,
-		Location:  from,
+		Location:  location,
 		Nodes:     nodeIDs,
 		Limit:     limit,
+		Quotas:    quotas,
 		IPVersion: ipversion,
 	}
 
+	if dryRun {
+		return printPlan(c, "dnsperf", target, from, nodeIDs, limit, dnsPerfReq)
+	}
+	if err := resultView.Credits.Check(internal.NewPlan("dnsperf", target, location, quotas, nodeIDs, limit)); err != nil {
+		return err
+	}
+
 	spinner := internal.NewSpinner()
-	fmt.Println("")
+	if internal.Progress() {
+		fmt.Println("")
+	}
 	spinner.Start()
 
+	started := time.Now()
 	testID, err := c.Run.DNSPerf(ctx, dnsPerfReq)
 	spinner.Stop()
+	// Started sub-tests are recorded if a later one failed to start.
+	recordHistory("dnsperf", target, testID)
 	if err != nil {
 		return err
 	}
 
-	if debug && !outputJSON {
+	format := outputFormat()
+	if debug && format == internal.OutputText {
 		fmt.Printf("Test ID: %v\n", testID)
 	}
 
 	var output *perfops.DNSTestOutput
-	printedIDs := map[string]bool{}
-	for {
-		spinner.Start()
-		select {
-		case <-time.After(500 * time.Millisecond):
+	if resultView.TUI {
+		title := fmt.Sprintf("dnsperf %s (%s)", target, testID)
+		if output, err = internal.WatchDNSTest(ctx, title, "dnsperf", testID, c.Run.DNSPerfOutput); err != nil {
+			return err
 		}
-
-		output, err = c.Run.DNSPerfOutput(ctx, testID)
-		spinner.Stop()
+		if output == nil || !output.IsFinished() {
+			internal.PrintStillRunning(os.Stdout, testID)
+			return nil
+		}
+	} else {
+		output, err = waitDNSOutput(ctx, spinner, testID, format == internal.OutputText && !resultView.IsSet() && !internal.Quiet(), c.Run.DNSPerfOutput, func(r *perfops.DNSTestResult) string {
+			return r.PerfOutput()
+		})
 		if err != nil {
 			return err
 		}
+	}
+	return internal.FinishTest(&internal.FinishedTest{
+		ID:               testID,
+		Type:             "dnsperf",
+		Target:           target,
+		Started:          started,
+		CreditsWithdrawn: output.CreditsWithdrawn,
+		Nodes:            len(output.NodeIDs()),
+		Results:          internal.DNSResults("dnsperf", output),
+		JSON:             internal.NewDNSTestJSON("dnsperf", output),
+		PrintResults:     resultView.IsSet() || resultView.TUI || internal.Quiet(),
+	}, format, &resultView)
+}
 
-		if !outputJSON {
-			printPartialDNSOutput(fmt.Printf, output, printedIDs, func(r *perfops.DNSTestResult) string {
-				return r.PerfOutput()
-			})
-		}
-		if output.IsFinished() {
-			break
+// runDNSPerfServers runs a DNS perf test against each DNS server from the
+// same nodes and prints their comparison.
+func runDNSPerfServers(c *perfops.Client, target string, dnsServers []string, from string, nodeIDs []int, limit, ipversion int) error {
+	if err := prepareView(c); err != nil {
+		return err
+	}
+	format := outputFormat()
+	if format != internal.OutputText && format != internal.OutputJSON {
+		return fmt.Errorf("--output %s is not supported with several DNS servers", format)
+	}
+	if resultView.IsSet() || resultView.TUI || resultView.Report != "" || resultView.Aggregate {
+		return errors.New("the result view, report and aggregate flags are not supported with several DNS servers")
+	}
+	nodeIDs, err := pinNodes(c, "dnsperf", from, nodeIDs, limit)
+	if err != nil {
+		return err
+	}
+	reqs := make([]*perfops.DNSPerfRequest, len(dnsServers))
+	tests := make([]interface{}, len(dnsServers))
+	for i, s := range dnsServers {
+		reqs[i] = &perfops.DNSPerfRequest{
+			Target:    target,
+			DNSServer: s,
+			Nodes:     nodeIDs,
+			Limit:     len(nodeIDs),
+			IPVersion: ipversion,
 		}
+		tests[i] = reqs[i]
 	}
-	if outputJSON {
-		internal.PrintOutputJSON(output)
+	if done, err := checkDNSTests(c, "dnsperf", target, "", nodeIDs, len(nodeIDs), tests); done || err != nil {
+		return err
+	}
+
+	outputs, err := runDNSPerfTests(c, reqs)
+	if err != nil {
+		return err
+	}
+	rc := internal.NewResolverComparison(target, dnsServers, outputs)
+	if format == internal.OutputJSON {
+		rc.CreditsUsed = resultView.Credits.Used()
+		return internal.PrintOutputJSON(rc)
+	}
+	if err := internal.PrintResolverComparison(os.Stdout, rc); err != nil {
+		return err
 	}
-	return nil
+	return internal.PrintCreditsUsed(os.Stdout, resultView.Credits)
 }
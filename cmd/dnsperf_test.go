@@ -14,7 +14,9 @@
 package cmd
 
 import (
+	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -58,14 +60,17 @@ func TestInitDNSPerfCmd(t *testing.T) {
 
 func TestRunDNSPerf(t *testing.T) {
 	testCases := map[string]struct {
-		from    string
-		nodeIDs []int
-		ipv6    bool
-		exp     string
+		dnsServer string
+		from      string
+		nodeIDs   []int
+		ipv6      bool
+		exp       string
 	}{
-		"Location": {"From here", []int{}, false, `{"target":"example.com","dnsServer":"127.0.0.1","location":"From here","limit":12,"ipversion":4}`},
-		"NodeID":   {"", []int{123}, false, `{"target":"example.com","dnsServer":"127.0.0.1","nodes":"123","limit":12,"ipversion":4}`},
-		"IPv6":     {"", []int{}, true, `{"target":"example.com","dnsServer":"127.0.0.1","limit":12,"ipversion":6}`},
+		"Location":  {"127.0.0.1", "From here", []int{}, false, `{"target":"example.com","dnsServer":"127.0.0.1","location":"From here","limit":12,"ipversion":4}`},
+		"NodeID":    {"127.0.0.1", "", []int{123}, false, `{"target":"example.com","dnsServer":"127.0.0.1","nodes":"123","limit":12,"ipversion":4}`},
+		"IPv6":      {"127.0.0.1", "", []int{}, true, `{"target":"example.com","dnsServer":"127.0.0.1","limit":12,"ipversion":6}`},
+		"Cleaned":   {" 8.8.8.8,", "", []int{}, false, `{"target":"example.com","dnsServer":"8.8.8.8","limit":12,"ipversion":4}`},
+		"No server": {"", "", []int{}, false, `{"target":"example.com","limit":12,"ipversion":4}`},
 	}
 	// We're only interested in the first HTTP call, e.g., the one to get the test ID
 	// to validate our parameters got passed properly.
@@ -76,7 +81,7 @@ func TestRunDNSPerf(t *testing.T) {
 	}
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
-			runDNSPerf(c, "example.com", "127.0.0.1", tc.from, tc.nodeIDs, 12, tc.ipv6)
+			runDNSPerf(c, "example.com", tc.dnsServer, tc.from, tc.nodeIDs, 12, tc.ipv6)
 			if got, exp := tr.req.URL.Path, "/run/dns-perf"; got != exp {
 				t.Fatalf("expected %v; got %v", exp, got)
 			}
@@ -85,4 +90,44 @@ func TestRunDNSPerf(t *testing.T) {
 			}
 		})
 	}
+
+	tr.req = nil
+	if err := runDNSPerf(c, "example.com", " , ", "", []int{}, 12, false); err == nil || tr.req != nil {
+		t.Fatalf("expected an error and no request for an empty list; got %v", err)
+	}
+}
+
+func TestRunDNSPerfServers(t *testing.T) {
+	os.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CONFIG_HOME")
+	tr := &dnsTransport{outputs: map[string]string{
+		"8.8.8.8":   `"12.5"`,
+		"1.1.1.1":   `"4.2"`,
+		"127.0.0.1": `"0.8"`,
+	}}
+	c, err := newTestPerfopsClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	outputJSON = true
+	defer func() { outputJSON = false }()
+	if err := runDNSPerf(c, "example.com", "8.8.8.8, 1.1.1.1,127.0.0.1,8.8.8.8", "", []int{5, 12}, 2, false); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got, exp := strings.Join(tr.servers, ","), "8.8.8.8,1.1.1.1,127.0.0.1"; got != exp {
+		t.Fatalf("expected a test per DNS server %v; got %v", exp, got)
+	}
+	if got, exp := strings.Join(tr.nodes, ","), "5,12,5,12,5,12"; got != exp {
+		t.Fatalf("expected the same nodes %v; got %v", exp, got)
+	}
+	entries, err := readHistory()
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("expected the tests in the history; got %v, %v", entries, err)
+	}
+
+	resultView.TUI = true
+	defer func() { resultView.TUI = false }()
+	if err := runDNSPerf(c, "example.com", "8.8.8.8,1.1.1.1", "", []int{5}, 1, false); err == nil {
+		t.Fatal("expected an error for the TUI")
+	}
 }
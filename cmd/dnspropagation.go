@@ -0,0 +1,130 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+var (
+	dnsPropagationCmd = &cobra.Command{
+		Use:   "propagation [name]",
+		Short: "Check the propagation of a DNS record",
+		Long: `Check the propagation of a changed DNS record by resolving it from many nodes.
+
+Each node queries its local resolver (127.0.0.1) and any further DNS servers
+given with --dns-server. A node sees the new value if its answers contain every
+expected value. An MX or SRV value without preference or priority, e.g.,
+mail.example.com, matches the exchange or target only, the full record data,
+e.g., "10 mail.example.com", has to match as a whole. The TTLs are shown if the
+DNS server reports them.`,
+		Example: `perfops dns propagation --type A --expect 93.184.216.34 example.com
+perfops dns propagation --expect mail.example.com --type MX --dns-server 127.0.0.1,8.8.8.8,1.1.1.1 example.com`,
+		Args: requireTarget(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newPerfOpsClient()
+			if err != nil {
+				return err
+			}
+			return chkRunError(runDNSPropagation(c, args[0], dnsPropagationType, dnsPropagationExpect, dnsPropagationServers, from, nodeIDs, dnsPropagationLimit))
+		},
+	}
+
+	dnsPropagationType    string
+	dnsPropagationExpect  []string
+	dnsPropagationServers []string
+	dnsPropagationLimit   int
+)
+
+func initDNSPropagationCmd(parentCmd *cobra.Command) {
+	addNodeFlags(dnsPropagationCmd)
+	dnsPropagationCmd.Flags().StringVarP(&dnsPropagationType, "type", "T", "A", "The DNS query type. One of: A, AAAA, CNAME, MX, NAPTR, NS, PTR, SOA, SPF, SRV, TXT.")
+	dnsPropagationCmd.Flags().StringSliceVarP(&dnsPropagationExpect, "expect", "E", []string{}, "A comma separated list of the new values of the record")
+	dnsPropagationCmd.Flags().StringSliceVarP(&dnsPropagationServers, "dns-server", "S", []string{"127.0.0.1"}, "A comma separated list of the DNS servers to query, 127.0.0.1 for the local resolver of each node")
+	dnsPropagationCmd.Flags().IntVarP(&dnsPropagationLimit, "limit", "L", 20, "The maximum number of nodes to use")
+	dnsPropagationCmd.Flags().BoolVarP(&outputJSON, "json", "J", false, "Print the result of a command in JSON format")
+	dnsPropagationCmd.Flags().IntVarP(&maxCredits, "max-credits", "", 0, "Refuse to run the test if it needs more than this number of credits")
+	dnsPropagationCmd.Flags().BoolVarP(&dryRun, "dry-run", "", false, "Validate the test, select its nodes and estimate its credits without running it")
+
+	setFlagCompletion(dnsPropagationCmd.Flags(), "type", "querytype")
+
+	dnsPropagationCmd.MarkFlagRequired("expect")
+
+	parentCmd.AddCommand(dnsPropagationCmd)
+}
+
+func runDNSPropagation(c *perfops.Client, target, queryType string, expect, dnsServers []string, from string, nodeIDs []int, limit int) error {
+	queryType = strings.ToUpper(queryType)
+	dnsServers = splitDNSServers(dnsServers)
+	if len(expect) == 0 {
+		return errors.New("no expected value specified")
+	}
+	if len(dnsServers) == 0 {
+		return errors.New("no DNS server specified")
+	}
+	credits, err := creditAccount(c)
+	if err != nil {
+		return err
+	}
+	resultView.Credits = credits
+	// Pin the nodes to compare the DNS servers on the same nodes.
+	if len(dnsServers) > 1 {
+		if nodeIDs, err = pinNodes(c, "resolve", from, nodeIDs, limit); err != nil {
+			return err
+		}
+		from, limit = "", len(nodeIDs)
+	} else if from, nodeIDs, limit, err = selectNodes(c, "resolve", from, nodeIDs, limit); err != nil {
+		return err
+	}
+	location, quotas, err := internal.ParseLocation(from)
+	if err != nil {
+		return err
+	}
+	reqs := make([]*perfops.DNSResolveRequest, len(dnsServers))
+	for i, s := range dnsServers {
+		reqs[i] = &perfops.DNSResolveRequest{
+			Target:    target,
+			Param:     queryType,
+			DNSServer: s,
+			Location:  location,
+			Nodes:     nodeIDs,
+			Limit:     limit,
+			Quotas:    quotas,
+		}
+	}
+	if done, err := checkDNSResolveTests(c, target, from, nodeIDs, limit, reqs); done || err != nil {
+		return err
+	}
+
+	outputs, err := runDNSResolveTests(c, reqs)
+	if err != nil {
+		return err
+	}
+	p := internal.NewPropagation(target, queryType, expect, dnsServers, outputs)
+	if outputFormat() == internal.OutputJSON {
+		p.CreditsUsed = resultView.Credits.Used()
+		return internal.PrintOutputJSON(p)
+	}
+	if err := internal.PrintPropagation(os.Stdout, p); err != nil {
+		return err
+	}
+	return internal.PrintCreditsUsed(os.Stdout, resultView.Credits)
+}
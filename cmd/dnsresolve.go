@@ -15,7 +15,9 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -27,11 +29,15 @@ import (
 
 var (
 	dnsResolveCmd = &cobra.Command{
-		Use:     "resolve [target]",
-		Short:   "Resolve a DNS record on a domain name",
-		Long:    `Resolve a DNS record on a target, e.g., google.com.`,
-		Example: `perfops resolve --dns-server 8.8.8.8 --type A bing.com`,
-		Args:    requireTarget(),
+		Use:   "resolve [target]",
+		Short: "Resolve a DNS record on a domain name",
+		Long: `Resolve a DNS record on a target, e.g., google.com.
+
+Several query types, e.g., A,AAAA,MX, are resolved from the same nodes and the
+records are shown by node.`,
+		Example: `perfops resolve --dns-server 8.8.8.8 --type A bing.com
+perfops resolve --dns-server 127.0.0.1 --type ALL --limit 5 bing.com`,
+		Args: requireTarget(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c, err := newPerfOpsClient()
 			if err != nil {
@@ -49,10 +55,12 @@ var (
 func initDNSResolveCmd(parentCmd *cobra.Command) {
 	addCommonFlags(dnsResolveCmd)
 
-	dnsResolveCmd.Flags().StringVarP(&dnsResolveType, "type", "T", "", "The DNS query type. On of: A, AAAA, CNAME, MX, NAPTR, NS, PTR, SOA, SPF, SRV, TXT.")
+	dnsResolveCmd.Flags().StringVarP(&dnsResolveType, "type", "T", "", "The DNS query type. One of: A, AAAA, CNAME, MX, NAPTR, NS, PTR, SOA, SPF, SRV, TXT. A comma separated list of query types or ALL for "+strings.Join(internal.CommonQueryTypes, ", ")+" resolves each from the same nodes.")
 	dnsResolveCmd.Flags().StringVarP(&dnsResolveDNSServer, "dns-server", "S", "", "The DNS server to use to query for the test. You can use 127.0.0.1 to use the local resolver for location based benchmarking.")
 	dnsResolveCmd.Flags().IntVarP(&dnsResolveLimit, "limit", "L", 1, "The maximum number of nodes to use")
 
+	setFlagCompletion(dnsResolveCmd.Flags(), "type", "querytype")
+
 	dnsResolveCmd.MarkFlagRequired("type")
 	dnsResolveCmd.MarkFlagRequired("dns-server")
 
@@ -61,30 +69,143 @@ func initDNSResolveCmd(parentCmd *cobra.Command) {
 
 func runDNSResolve(c *perfops.Client, target, queryType, dnsServer, from string, nodeIDs []int, limit int) error {
 	ctx := context.Background()
+	types, err := internal.ParseQueryTypes(queryType)
+	if err != nil {
+		return err
+	}
+	if len(types) > 1 {
+		return runDNSResolveTypes(c, target, types, dnsServer, from, nodeIDs, limit)
+	}
+	queryType = types[0]
+	from, nodeIDs, limit, err = selectNodes(c, "resolve", from, nodeIDs, limit)
+	if err != nil {
+		return err
+	}
+	if err := prepareView(c); err != nil {
+		return err
+	}
+	location, quotas, err := internal.ParseLocation(from)
+	if err != nil {
+		return err
+	}
 	dnsResolveReq := &perfops.DNSResolveRequest{
 		Target:    target,
 		Param:     queryType,
 		DNSServer: dnsServer,
-		Location:  from,
+		Location:  location,
 		Nodes:     nodeIDs,
 		Limit:     limit,
+		Quotas:    quotas,
+	}
+
+	if dryRun {
+		return printPlan(c, "resolve", target, from, nodeIDs, limit, dnsResolveReq)
+	}
+	if err := resultView.Credits.Check(internal.NewPlan("resolve", target, location, quotas, nodeIDs, limit)); err != nil {
+		return err
 	}
 
 	spinner := internal.NewSpinner()
-	fmt.Println("")
+	if internal.Progress() {
+		fmt.Println("")
+	}
 	spinner.Start()
 
+	started := time.Now()
 	testID, err := c.Run.DNSResolve(ctx, dnsResolveReq)
 	spinner.Stop()
+	// Started sub-tests are recorded if a later one failed to start.
+	recordHistory("resolve", target, testID)
 	if err != nil {
 		return err
 	}
 
-	if debug && !outputJSON {
+	format := outputFormat()
+	if debug && format == internal.OutputText {
 		fmt.Printf("Test ID: %v\n", testID)
 	}
 
 	var output *perfops.DNSTestOutput
+	if resultView.TUI {
+		title := fmt.Sprintf("resolve %s (%s)", target, testID)
+		if output, err = internal.WatchDNSTest(ctx, title, "resolve", testID, c.Run.DNSResolveOutput); err != nil {
+			return err
+		}
+		if output == nil || !output.IsFinished() {
+			internal.PrintStillRunning(os.Stdout, testID)
+			return nil
+		}
+	} else {
+		output, err = waitDNSOutput(ctx, spinner, testID, format == internal.OutputText && !resultView.IsSet() && !internal.Quiet(), c.Run.DNSResolveOutput, func(r *perfops.DNSTestResult) string {
+			o := r.ResolveOutput()
+			return strings.Join(o, "\n")
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return internal.FinishTest(&internal.FinishedTest{
+		ID:               testID,
+		Type:             "resolve",
+		Target:           target,
+		Started:          started,
+		CreditsWithdrawn: output.CreditsWithdrawn,
+		Nodes:            len(output.NodeIDs()),
+		Results:          internal.DNSResults("resolve", output),
+		JSON:             internal.NewResolveJSON(queryType, output),
+		PrintResults:     resultView.IsSet() || resultView.TUI || internal.Quiet(),
+	}, format, &resultView)
+}
+
+// runDNSResolveTypes resolves several query types from the same nodes and
+// prints the record sets of each node.
+func runDNSResolveTypes(c *perfops.Client, target string, types []string, dnsServer, from string, nodeIDs []int, limit int) error {
+	if err := prepareView(c); err != nil {
+		return err
+	}
+	format := outputFormat()
+	if format != internal.OutputText && format != internal.OutputJSON {
+		return fmt.Errorf("--output %s is not supported with several query types", format)
+	}
+	if resultView.IsSet() || resultView.TUI || resultView.Report != "" {
+		return errors.New("the result view and report flags are not supported with several query types")
+	}
+	nodeIDs, err := pinNodes(c, "resolve", from, nodeIDs, limit)
+	if err != nil {
+		return err
+	}
+	reqs := make([]*perfops.DNSResolveRequest, len(types))
+	for i, t := range types {
+		reqs[i] = &perfops.DNSResolveRequest{
+			Target:    target,
+			Param:     t,
+			DNSServer: dnsServer,
+			Nodes:     nodeIDs,
+			Limit:     len(nodeIDs),
+		}
+	}
+	if done, err := checkDNSResolveTests(c, target, "", nodeIDs, len(nodeIDs), reqs); done || err != nil {
+		return err
+	}
+
+	outputs, err := runDNSResolveTests(c, reqs)
+	if err != nil {
+		return err
+	}
+	rs := internal.NewRecordSets(target, dnsServer, types, outputs)
+	if format == internal.OutputJSON {
+		rs.CreditsUsed = resultView.Credits.Used()
+		return internal.PrintOutputJSON(rs)
+	}
+	if err := internal.PrintRecordSets(os.Stdout, rs); err != nil {
+		return err
+	}
+	return internal.PrintCreditsUsed(os.Stdout, resultView.Credits)
+}
+
+// waitDNSOutput polls the output of a DNS test until it finished. The
+// output of each node is printed as it arrives if partial is set.
+func waitDNSOutput(ctx context.Context, spinner *internal.Spinner, testID perfops.TestID, partial bool, dnsOutput func(ctx context.Context, testID perfops.TestID) (*perfops.DNSTestOutput, error), getOutput func(r *perfops.DNSTestResult) string) (*perfops.DNSTestOutput, error) {
 	printedIDs := map[string]bool{}
 	for {
 		spinner.Start()
@@ -92,26 +213,19 @@ func runDNSResolve(c *perfops.Client, target, queryType, dnsServer, from string,
 		case <-time.After(500 * time.Millisecond):
 		}
 
-		output, err = c.Run.DNSResolveOutput(ctx, testID)
+		output, err := dnsOutput(ctx, testID)
 		spinner.Stop()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		if !outputJSON {
-			printPartialDNSOutput(fmt.Printf, output, printedIDs, func(r *perfops.DNSTestResult) string {
-				o := r.ResolveOutput()
-				return strings.Join(o, "\n")
-			})
+		if partial {
+			printPartialDNSOutput(fmt.Printf, output, printedIDs, getOutput)
 		}
 		if output.IsFinished() {
-			break
+			return output, nil
 		}
 	}
-	if outputJSON {
-		internal.PrintOutputJSON(output)
-	}
-	return nil
 }
 
 func printPartialDNSOutput(printf func(format string, a ...interface{}) (n int, err error), output *perfops.DNSTestOutput, printedIDs map[string]bool, getOutput func(r *perfops.DNSTestResult) string) {
@@ -121,16 +235,14 @@ func printPartialDNSOutput(printf func(format string, a ...interface{}) (n int,
 		}
 		r := item.Result
 		n := r.Node
-		if r.Message == "" {
-			printedIDs[item.ID] = true
-			o := getOutput(r)
-			if o == "-2" {
-				o = "The command timed-out. It either took too long to execute or we could not connect to your target at all."
-			}
-			printf("Node%d, AS%d, %s, %s\n%s\n", n.ID, n.AsNumber, n.City, n.Country.Name, o)
-		} else if r.Message != "NO DATA" {
-			printedIDs[item.ID] = true
-			printf("Node%d, AS%d, %s, %s\n%s\n", n.ID, n.AsNumber, n.City, n.Country.Name, r.Message)
+		status, reason := r.Status()
+		switch status {
+		case perfops.StatusPending, perfops.StatusNoData:
+			continue
+		case perfops.StatusOK:
+			reason = getOutput(r)
 		}
+		printedIDs[item.ID] = true
+		printf("Node%d, AS%d, %s, %s\n%s\n", n.ID, n.AsNumber, n.City, n.Country.Name, reason)
 	}
 }
@@ -14,10 +14,14 @@
 package cmd
 
 import (
+	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
 )
 
 func TestInitDNSResolveCmd(t *testing.T) {
@@ -84,3 +88,31 @@ func TestRunDNSResolve(t *testing.T) {
 		})
 	}
 }
+
+func TestRunDNSResolveTypes(t *testing.T) {
+	os.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CONFIG_HOME")
+	tr := &dnsTransport{outputs: map[string]string{
+		"8.8.8.8_A":  `["93.184.216.34"]`,
+		"8.8.8.8_MX": `["10 mail.example.com."]`,
+	}}
+	c, err := newTestPerfopsClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := runDNSResolve(c, "example.com", "a, mx,A", "8.8.8.8", "", []int{5}, 1); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got, exp := strings.Join(tr.params, ","), "A,MX"; got != exp {
+		t.Fatalf("expected a test per query type %v; got %v", exp, got)
+	}
+	if got, exp := strings.Join(tr.nodes, ","), "5,5"; got != exp {
+		t.Fatalf("expected the same nodes %v; got %v", exp, got)
+	}
+
+	testOutput = internal.OutputMarkdown
+	defer func() { testOutput = internal.OutputText }()
+	if err := runDNSResolve(c, "example.com", "ALL", "8.8.8.8", "", []int{5}, 1); err == nil {
+		t.Fatal("expected an error for markdown output")
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+// dryRun is set to print the plan of a test instead of running it.
+var dryRun bool
+
+// printPlan validates the request of a test and prints the nodes it would
+// run on and its estimated credits instead of running it. An error is
+// returned if the test needs more credits than remaining or allowed.
+func printPlan(c *perfops.Client, testType, target, from string, nodeIDs []int, limit int, req interface{}) error {
+	return printTestsPlan(c, testType, target, from, nodeIDs, limit, []interface{}{req})
+}
+
+// printTestsPlan is like printPlan for tests run on the same nodes, e.g.,
+// one per DNS server.
+func printTestsPlan(c *perfops.Client, testType, target, from string, nodeIDs []int, limit int, reqs []interface{}) error {
+	for _, req := range reqs {
+		if err := c.Run.Validate(req); err != nil {
+			return err
+		}
+	}
+	location, quotas, err := internal.ParseLocation(from)
+	if err != nil {
+		return err
+	}
+	p := internal.NewPlan(testType, target, location, quotas, nodeIDs, limit).Repeat(len(reqs))
+	checkErr := resultView.Credits.Check(p)
+	p.RemainingCredits = resultView.Credits.RemainingBefore()
+
+	if outputFormat() == internal.OutputJSON {
+		if err := internal.PrintOutputJSON(p); err != nil {
+			return err
+		}
+	} else {
+		var nodes []*perfops.Node
+		if len(nodeIDs) > 0 {
+			if cat, err := loadCatalog(c); err == nil {
+				nodes = cat.Nodes
+			}
+		}
+		if err := internal.PrintPlan(os.Stdout, p, nodes); err != nil {
+			return err
+		}
+	}
+	return checkErr
+}
@@ -0,0 +1,137 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+// creditsTransport responds to remaining credits requests and records the
+// paths of all requests.
+type creditsTransport struct {
+	credits string
+	paths   []string
+}
+
+func (t *creditsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.paths = append(t.paths, req.URL.Path)
+	body := `{"error":"not found"}`
+	code := http.StatusNotFound
+	if req.URL.Path == "/remaining-credits" {
+		body, code = `{"remaining_credits":`+t.credits+`}`, http.StatusOK
+	}
+	return &http.Response{
+		StatusCode: code,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestDryRun(t *testing.T) {
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+	os.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CONFIG_HOME")
+	defer func() { dryRun, maxCredits = false, 0 }()
+	dryRun = true
+
+	testCases := map[string]struct {
+		run    func(c *perfops.Client) error
+		max    int
+		expErr string
+	}{
+		"Ping": {func(c *perfops.Client) error { return runPing(c, "example.com", "Europe", []int{}, 5, false) }, 0, ""},
+		"Ping quotas": {func(c *perfops.Client) error {
+			return runPing(c, "example.com", "Europe:10,Asia:5", []int{}, 1, false)
+		}, 0, "the test needs an estimated 15 credits, but only 12 are remaining"},
+		"Curl nodes": {func(c *perfops.Client) error {
+			return runCurl(c, "example.com", true, false, false, "", []int{1, 2}, 1, "", false)
+		}, 0, ""},
+		"DNS perf limit": {func(c *perfops.Client) error { return runDNSPerf(c, "example.com", "", "", []int{}, 13, false) }, 0, "the test needs an estimated 13 credits, but only 12 are remaining"},
+		"DNS perf servers": {func(c *perfops.Client) error {
+			return runDNSPerf(c, "example.com", "8.8.8.8,1.1.1.1,9.9.9.9,127.0.0.1", "", []int{1, 2, 3, 4}, 4, false)
+		}, 0, "the test needs an estimated 16 credits, but only 12 are remaining"},
+		"Resolve invalid": {func(c *perfops.Client) error { return runDNSResolve(c, "meep", "A", "127.0.0.1", "", []int{}, 1) }, 0, "invalid argument: target"},
+		"Max credits":     {func(c *perfops.Client) error { return runMTR(c, "example.com", "", []int{}, 3, false) }, 2, "the test needs an estimated 3 credits, more than the maximum of 2"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			maxCredits = tc.max
+			tr := &creditsTransport{credits: "12"}
+			c, err := newTestPerfopsClient(tr)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			err = tc.run(c)
+			if got := errString(err); got != tc.expErr {
+				t.Fatalf("expected error %q; got %q", tc.expErr, got)
+			}
+			for _, p := range tr.paths {
+				if strings.HasPrefix(p, "/run/") {
+					t.Fatalf("expected no test to be run; got %s", p)
+				}
+			}
+		})
+	}
+}
+
+func TestDailyCreditBudget(t *testing.T) {
+	cfgDir := t.TempDir()
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+	os.Setenv("XDG_CONFIG_HOME", cfgDir)
+	defer os.Unsetenv("XDG_CONFIG_HOME")
+
+	if err := os.MkdirAll(filepath.Join(cfgDir, "perfops"), 0755); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(cfgDir, "perfops", "config.json"), []byte(`{"daily_credit_budget":4}`), 0644); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := internal.AppendHistory(filepath.Join(cfgDir, "perfops", "history.jsonl"), &internal.HistoryEntry{ID: "abc", Type: "ping", Target: "example.com", Time: time.Now(), Credits: 3}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	tr := &creditsTransport{credits: "12"}
+	c, err := newTestPerfopsClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	err = runPing(c, "example.com", "", []int{}, 2, false)
+	if got, exp := errString(err), "the test needs an estimated 2 credits, but only 1 of the daily budget of 4 are left"; got != exp {
+		t.Fatalf("expected error %q; got %q", exp, got)
+	}
+	for _, p := range tr.paths {
+		if strings.HasPrefix(p, "/run/") {
+			t.Fatalf("expected no test to be run; got %s", p)
+		}
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
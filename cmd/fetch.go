@@ -0,0 +1,139 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+// testTypes lists the types of tests that can be run.
+var testTypes = []string{"curl", "dnsperf", "latency", "mtr", "ping", "resolve", "traceroute"}
+
+var (
+	fetchCmd = &cobra.Command{
+		Use:   "fetch [test-id]",
+		Short: "Show the results of a previously run test",
+		Long: `Show the results of a previously run test. The type of the test is looked up
+in the local history unless it is given with --type.`,
+		Example: `perfops fetch --type ping 706fc55e3377104da01f05569e35a30b`,
+		Args:    cobra.ExactArgs(1),
+		Annotations: map[string]string{
+			completionAnnotation: "testid",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newPerfOpsClient()
+			if err != nil {
+				return err
+			}
+			return chkRunError(runFetch(c, perfops.TestID(args[0]), fetchType))
+		},
+	}
+
+	fetchType string
+)
+
+func initFetchCmd(parentCmd *cobra.Command) {
+	fetchCmd.Flags().StringVarP(&fetchType, "type", "T", "", "The type of the test. One of: "+strings.Join(testTypes, ", "))
+	fetchCmd.Flags().BoolVarP(&outputJSON, "json", "J", false, "Print the result of a command in JSON format")
+	fetchCmd.Flags().StringVarP(&testOutput, "output", "o", internal.OutputText, "The output format. One of: "+strings.Join(internal.OutputFormats, ", "))
+	setFlagCompletion(fetchCmd.Flags(), "type", "testtype")
+	setFlagCompletion(fetchCmd.Flags(), "output", "output")
+	parentCmd.AddCommand(fetchCmd)
+}
+
+func runFetch(c *perfops.Client, testID perfops.TestID, testType string) error {
+	if testType == "" {
+		entries, err := readHistory()
+		if err != nil {
+			return err
+		}
+		e := internal.FindHistory(entries, testID)
+		if e == nil {
+			return fmt.Errorf("test '%s' not found in history, please specify its type with --type", testID)
+		}
+		testType = e.Type
+	}
+	if err := prepareView(c); err != nil {
+		return err
+	}
+	format := outputFormat()
+	name := testType + " " + string(testID)
+
+	ctx := context.Background()
+	switch testType {
+	case "dnsperf", "resolve":
+		getOutput := c.Run.DNSPerfOutput
+		printOutput := func(r *perfops.DNSTestResult) string { return r.PerfOutput() }
+		if testType == "resolve" {
+			getOutput = c.Run.DNSResolveOutput
+			printOutput = func(r *perfops.DNSTestResult) string { return strings.Join(r.ResolveOutput(), "\n") }
+		}
+		output, err := getOutput(ctx, testID)
+		if err != nil {
+			return err
+		}
+		results := internal.DNSResults(testType, output)
+		if format != internal.OutputText {
+			j := internal.NewDNSTestJSON(testType, output)
+			if testType == "resolve" {
+				// The query type is not known, the record types are inferred.
+				j = internal.NewResolveJSON("", output)
+			}
+			return internal.PrintOutputFormat(format, name, j, results, resultView.Nodes)
+		}
+		printPartialDNSOutput(fmt.Printf, output, map[string]bool{}, printOutput)
+		return internal.PrintResultsSummary(os.Stdout, testType, results)
+	}
+
+	getOutput := runOutputFunc(c, testType)
+	if getOutput == nil {
+		return fmt.Errorf("unsupported test type '%s'", testType)
+	}
+	output, err := getOutput(ctx, testID)
+	if err != nil {
+		return err
+	}
+	results := internal.RunResults(testType, output)
+	if format != internal.OutputText {
+		return internal.PrintOutputFormat(format, name, internal.NewRunJSON(testType, output), results, resultView.Nodes)
+	}
+	internal.PrintOutput(internal.NewFormatter(debug), output)
+	return internal.PrintResultsSummary(os.Stdout, testType, results)
+}
+
+// runOutputFunc returns the function retrieving the output of a test
+// type or nil for DNS and unknown tests.
+func runOutputFunc(c *perfops.Client, testType string) func(ctx context.Context, testID perfops.TestID) (*perfops.RunOutput, error) {
+	switch testType {
+	case "curl":
+		return c.Run.CurlOutput
+	case "latency":
+		return c.Run.LatencyOutput
+	case "mtr":
+		return c.Run.MTROutput
+	case "ping":
+		return c.Run.PingOutput
+	case "traceroute":
+		return c.Run.TracerouteOutput
+	}
+	return nil
+}
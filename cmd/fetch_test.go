@@ -0,0 +1,81 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func TestRunFetch(t *testing.T) {
+	os.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CONFIG_HOME")
+	recordHistory("resolve", "example.com", perfops.TestID("456"))
+
+	testCases := map[string]struct {
+		testID   string
+		testType string
+		exp      string
+	}{
+		"Type":    {"123", "ping", "/run/ping/123"},
+		"Curl":    {"123", "curl", "/run/curl/123"},
+		"DNS":     {"123", "dnsperf", "/run/dns-perf/123"},
+		"History": {"456", "", "/run/dns-resolve/456"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tr := &recordingTransport{}
+			c, err := newTestPerfopsClient(tr)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			runFetch(c, perfops.TestID(tc.testID), tc.testType)
+			if got, exp := tr.req.URL.Path, tc.exp; got != exp {
+				t.Fatalf("expected %v; got %v", exp, got)
+			}
+		})
+	}
+
+	c, err := newTestPerfopsClient(&recordingTransport{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := runFetch(c, perfops.TestID("789"), ""); err == nil {
+		t.Fatal("expected error for unknown test; got nil")
+	}
+	if err := runFetch(c, perfops.TestID("789"), "meep"); err == nil {
+		t.Fatal("expected error for unknown test type; got nil")
+	}
+}
+
+func TestWithHistory(t *testing.T) {
+	os.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CONFIG_HOME")
+	run := withHistory("ping", func(ctx context.Context, req *perfops.RunRequest) (perfops.TestID, error) {
+		if req.Target == "partial.example.com" {
+			return "a1,b2", &perfops.FanOutError{TestID: "a1,b2", Location: "Africa", Err: errors.New("meep")}
+		}
+		return "", errors.New("meep")
+	})
+	run(context.Background(), &perfops.RunRequest{Target: "example.com"})
+	run(context.Background(), &perfops.RunRequest{Target: "partial.example.com"})
+	entries, err := readHistory()
+	if err != nil || len(entries) != 1 || entries[0].ID != "a1,b2" {
+		t.Fatalf("expected the started sub-tests in the history; got %v, %v", entries, err)
+	}
+}
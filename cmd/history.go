@@ -0,0 +1,124 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+var (
+	historyCmd = &cobra.Command{
+		Use:     "history",
+		Short:   "Show the recently run tests",
+		Long:    `Show the recently run tests, most recent first. Use 'perfops fetch' to show the results of a test again.`,
+		Example: `perfops history --limit 5`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistory(historyLimit)
+		},
+	}
+
+	historyLimit int
+)
+
+func initHistoryCmd(parentCmd *cobra.Command) {
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "L", 20, "The maximum number of tests to show")
+	historyCmd.Flags().BoolVarP(&outputJSON, "json", "J", false, "Print the result of a command in JSON format")
+	parentCmd.AddCommand(historyCmd)
+}
+
+func runHistory(limit int) error {
+	entries, err := readHistory()
+	if err != nil {
+		return err
+	}
+	entries = recentHistory(entries, limit)
+	if outputJSON {
+		return internal.PrintOutputJSON(entries)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTIME\tTYPE\tTARGET\tCREDITS")
+	for _, e := range entries {
+		credits := "-"
+		if e.Credits > 0 {
+			credits = strconv.Itoa(e.Credits)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", e.ID, e.Time.Local().Format("2006-01-02 15:04:05"), e.Type, e.Target, credits)
+	}
+	return tw.Flush()
+}
+
+// historyPath returns the path of the local test history.
+func historyPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "perfops", "history.jsonl"), nil
+}
+
+func readHistory() ([]*internal.HistoryEntry, error) {
+	p, err := historyPath()
+	if err != nil {
+		return nil, err
+	}
+	return internal.ReadHistory(p)
+}
+
+// recentHistory returns up to limit entries, most recent first.
+func recentHistory(entries []*internal.HistoryEntry, limit int) []*internal.HistoryEntry {
+	var res []*internal.HistoryEntry
+	for i := len(entries) - 1; i >= 0 && len(res) < limit; i-- {
+		res = append(res, entries[i])
+	}
+	return res
+}
+
+// recordHistory adds a test to the local history, unless the test ID is
+// empty, e.g., of a test that failed to start. Failing to do so does
+// not fail the test.
+func recordHistory(testType, target string, testID perfops.TestID) {
+	if testID == "" {
+		return
+	}
+	p, err := historyPath()
+	if err == nil {
+		err = internal.AppendHistory(p, &internal.HistoryEntry{ID: testID, Type: testType, Target: target, Time: time.Now()})
+	}
+	if err != nil && debug {
+		fmt.Fprintf(os.Stderr, "Failed to record test in history: %v\n", err)
+	}
+}
+
+// withHistory returns a run function recording the submitted tests in
+// the local history, including the started sub-tests of a test with
+// location quotas failing to start.
+func withHistory(testType string, run func(ctx context.Context, req *perfops.RunRequest) (perfops.TestID, error)) func(ctx context.Context, req *perfops.RunRequest) (perfops.TestID, error) {
+	return func(ctx context.Context, req *perfops.RunRequest) (perfops.TestID, error) {
+		testID, err := run(ctx, req)
+		recordHistory(testType, req.Target, testID)
+		return testID, err
+	}
+}
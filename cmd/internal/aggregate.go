@@ -0,0 +1,101 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+// Aggregates represents the results of a test aggregated by the location
+// and the autonomous system of the nodes.
+type Aggregates struct {
+	Metric     string          `json:"metric,omitempty"`
+	Continents []*GroupSummary `json:"continents"`
+	Regions    []*GroupSummary `json:"regions"`
+	Countries  []*GroupSummary `json:"countries"`
+	ASNs       []*GroupSummary `json:"asns"`
+}
+
+// Aggregate returns the results aggregated by continent, region, country
+// and ASN.
+func Aggregate(results []*Result, metric string) *Aggregates {
+	return &Aggregates{
+		Metric:     metric,
+		Continents: groupSummaries(results, metric, "continent"),
+		Regions:    groupSummaries(results, metric, "region"),
+		Countries:  groupSummaries(results, metric, "country"),
+		ASNs:       groupSummaries(results, metric, "asn"),
+	}
+}
+
+// PrintResultsReport writes the summary of the results to w followed by
+// their aggregates if the view asks for them.
+func PrintResultsReport(w io.Writer, testType string, results []*Result, v *View) error {
+	if err := PrintResultsSummary(w, testType, results); err != nil {
+		return err
+	}
+	if !v.Aggregate {
+		return nil
+	}
+	return PrintAggregates(w, Aggregate(results, PrimaryMetric(testType)))
+}
+
+// PrintAggregates writes the aggregates as tables to w.
+func PrintAggregates(w io.Writer, a *Aggregates) error {
+	tables := []struct {
+		name   string
+		groups []*GroupSummary
+	}{
+		{"CONTINENT", a.Continents},
+		{"REGION", a.Regions},
+		{"COUNTRY", a.Countries},
+		{"ASN", a.ASNs},
+	}
+	fmt.Fprint(w, "\n--- Aggregates ---\n")
+	if label, ok := metricLabels[a.Metric]; ok {
+		fmt.Fprintf(w, "%s\n", label)
+	}
+	for _, t := range tables {
+		fmt.Fprintln(w)
+		if err := printAggregateTable(w, t.name, a.Metric, t.groups); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printAggregateTable(w io.Writer, name, metric string, groups []*GroupSummary) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tNODES\tFAILED\tFAILURE RATE", name)
+	_, hasMetric := metricLabels[metric]
+	if hasMetric {
+		fmt.Fprint(tw, "\tMEAN\tP50\tP95")
+	}
+	fmt.Fprintln(tw)
+	for _, g := range groups {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s%%", g.Name, g.Nodes, g.Failed, strconv.FormatFloat(g.FailureRate, 'f', 1, 64))
+		if hasMetric {
+			mean, p50, p95 := "-", "-", "-"
+			if st := g.Stats; st != nil {
+				mean, p50, p95 = fmtValue(st.Mean), fmtValue(st.Median), fmtValue(st.P95)
+			}
+			fmt.Fprintf(tw, "\t%s\t%s\t%s", mean, p50, p95)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
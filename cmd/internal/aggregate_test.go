@@ -0,0 +1,66 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrintAggregates(t *testing.T) {
+	var b bytes.Buffer
+	if err := PrintAggregates(&b, Aggregate(testResults(), MetricRTT)); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	exp := `
+--- Aggregates ---
+RTT (ms)
+
+CONTINENT  NODES  FAILED  FAILURE RATE  MEAN    P50     P95
+Asia       2      1       50.0%         180.00  180.00  180.00
+Europe     3      1       33.3%         4.00    4.00    4.90
+
+REGION   NODES  FAILED  FAILURE RATE  MEAN   P50   P95
+Unknown  5      2       40.0%         62.67  5.00  162.50
+
+COUNTRY    NODES  FAILED  FAILURE RATE  MEAN    P50     P95
+Germany    3      1       33.3%         4.00    4.00    4.90
+Hong Kong  2      1       50.0%         180.00  180.00  180.00
+
+ASN      NODES  FAILED  FAILURE RATE  MEAN    P50     P95
+AS3320   2      1       50.0%         3.00    3.00    3.00
+AS9304   2      1       50.0%         180.00  180.00  180.00
+AS24940  1      0       0.0%          5.00    5.00    5.00
+`
+	if got := b.String(); got != exp {
+		t.Fatalf("expected\n%s\ngot\n%s", exp, got)
+	}
+}
+
+func TestPrintResultsReport(t *testing.T) {
+	var b bytes.Buffer
+	if err := PrintResultsReport(&b, "ping", testResults(), &View{}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if bytes.Contains(b.Bytes(), []byte("Aggregates")) {
+		t.Fatalf("expected no aggregates; got\n%s", b.String())
+	}
+	b.Reset()
+	if err := PrintResultsReport(&b, "ping", testResults(), &View{Aggregate: true}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !bytes.Contains(b.Bytes(), []byte("--- Summary ---")) || !bytes.Contains(b.Bytes(), []byte("--- Aggregates ---")) {
+		t.Fatalf("expected summary and aggregates; got\n%s", b.String())
+	}
+}
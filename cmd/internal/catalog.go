@@ -0,0 +1,247 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+// CatalogTTL is the duration after which the cached catalog is refreshed.
+const CatalogTTL = 24 * time.Hour
+
+type (
+	// Catalog is the locally cached catalog of test nodes and the
+	// locations they are available in.
+	Catalog struct {
+		Updated   time.Time          `json:"updated"`
+		Nodes     []*perfops.Node    `json:"nodes"`
+		Countries []*perfops.Country `json:"countries,omitempty"`
+		Cities    []*perfops.City    `json:"cities,omitempty"`
+	}
+
+	// LocationError is returned for locations not found in the catalog.
+	LocationError struct {
+		Location    string
+		Suggestions []string
+	}
+)
+
+// usStates are accepted as locations in addition to the catalog entries.
+var usStates = []string{
+	"Alabama", "Alaska", "Arizona", "Arkansas", "California", "Colorado",
+	"Connecticut", "Delaware", "Florida", "Georgia", "Hawaii", "Idaho",
+	"Illinois", "Indiana", "Iowa", "Kansas", "Kentucky", "Louisiana", "Maine",
+	"Maryland", "Massachusetts", "Michigan", "Minnesota", "Mississippi",
+	"Missouri", "Montana", "Nebraska", "Nevada", "New Hampshire",
+	"New Jersey", "New Mexico", "New York", "North Carolina", "North Dakota",
+	"Ohio", "Oklahoma", "Oregon", "Pennsylvania", "Rhode Island",
+	"South Carolina", "South Dakota", "Tennessee", "Texas", "Utah",
+	"Vermont", "Virginia", "Washington", "West Virginia", "Wisconsin",
+	"Wyoming",
+}
+
+// Error returns the string representation of the error.
+func (e *LocationError) Error() string {
+	msg := fmt.Sprintf("unknown location '%s'", e.Location)
+	switch n := len(e.Suggestions); n {
+	case 0:
+		return msg
+	case 1:
+		return fmt.Sprintf("%s, did you mean '%s'?", msg, e.Suggestions[0])
+	default:
+		q := make([]string, n)
+		for i, s := range e.Suggestions {
+			q[i] = "'" + s + "'"
+		}
+		return fmt.Sprintf("%s, did you mean %s or %s?", msg, strings.Join(q[:n-1], ", "), q[n-1])
+	}
+}
+
+// FetchCatalog retrieves the catalog from the PerfOps API.
+func FetchCatalog(ctx context.Context, c *perfops.Client) (*Catalog, error) {
+	nodes, err := c.Nodes.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	countries, err := c.Geo.Countries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cities, err := c.Geo.Cities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Catalog{Updated: time.Now(), Nodes: nodes, Countries: countries, Cities: cities}, nil
+}
+
+// LoadCatalog reads a catalog from the file at path.
+func LoadCatalog(path string) (*Catalog, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cat *Catalog
+	if err := json.Unmarshal(b, &cat); err != nil {
+		return nil, err
+	}
+	return cat, nil
+}
+
+// Save writes the catalog to the file at path.
+func (cat *Catalog) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	b, err := json.Marshal(cat)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
+
+// Expired returns a value indicating whether the catalog is older than ttl.
+func (cat *Catalog) Expired(ttl time.Duration) bool {
+	return time.Since(cat.Updated) > ttl
+}
+
+// Locations returns the sorted names of all known locations, e.g.,
+// continents, regions, countries, US states and cities.
+func (cat *Catalog) Locations() []string {
+	seen := map[string]bool{}
+	var locs []string
+	add := func(names ...string) {
+		for _, s := range names {
+			k := strings.ToLower(s)
+			if s == "" || seen[k] {
+				continue
+			}
+			seen[k] = true
+			locs = append(locs, s)
+		}
+	}
+	for _, n := range cat.Nodes {
+		add(n.City, n.SubRegion, n.CountryName(), n.ContinentName())
+	}
+	for _, c := range cat.Countries {
+		add(c.Name)
+		if c.Continent != nil {
+			add(c.Continent.Name)
+		}
+	}
+	for _, c := range cat.Cities {
+		add(c.Name)
+	}
+	add(usStates...)
+	sort.Strings(locs)
+	return locs
+}
+
+// ValidateLocation returns a *LocationError with close matches if loc is
+// not a known location. ISO codes of countries and continents are
+// accepted as well.
+func (cat *Catalog) ValidateLocation(loc string) error {
+	loc = strings.TrimSpace(loc)
+	if loc == "" {
+		return nil
+	}
+	locs := cat.Locations()
+	for _, l := range locs {
+		if strings.EqualFold(l, loc) {
+			return nil
+		}
+	}
+	for _, n := range cat.Nodes {
+		if c := n.Country; c != nil {
+			if strings.EqualFold(c.ISO, loc) || (c.Continent != nil && strings.EqualFold(c.Continent.ISO, loc)) {
+				return nil
+			}
+		}
+	}
+	return &LocationError{Location: loc, Suggestions: Suggest(loc, locs, 3)}
+}
+
+// Suggest returns up to max candidates that are close to s, closest
+// first.
+func Suggest(s string, candidates []string, max int) []string {
+	type match struct {
+		s    string
+		dist int
+	}
+	ls := strings.ToLower(s)
+	threshold := len(ls) / 3
+	if threshold < 1 {
+		threshold = 1
+	}
+	var matches []match
+	for _, c := range candidates {
+		lc := strings.ToLower(c)
+		d := levenshtein(ls, lc)
+		if d <= threshold || (len(ls) >= 3 && strings.HasPrefix(lc, ls)) {
+			matches = append(matches, match{c, d})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].dist != matches[j].dist {
+			return matches[i].dist < matches[j].dist
+		}
+		return matches[i].s < matches[j].s
+	})
+	var res []string
+	for i := 0; i < len(matches) && i < max; i++ {
+		res = append(res, matches[i].s)
+	}
+	return res
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
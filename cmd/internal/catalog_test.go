@@ -0,0 +1,120 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func testCatalog() *Catalog {
+	return &Catalog{
+		Updated: time.Now(),
+		Nodes:   testNodes(),
+		Countries: []*perfops.Country{
+			{Name: "France", ISO: "FR", Continent: &perfops.Continent{Name: "Europe", ISO: "EU"}},
+		},
+		Cities: []*perfops.City{{Name: "Paris"}},
+	}
+}
+
+func TestValidateLocation(t *testing.T) {
+	testCases := map[string]struct {
+		loc string
+		exp string
+	}{
+		"Empty":          {"", ""},
+		"City":           {"Frankfurt", ""},
+		"Case":           {"frankfurt", ""},
+		"Sub region":     {"western europe", ""},
+		"Country":        {"Germany", ""},
+		"Country ISO":    {"de", ""},
+		"Continent":      {"Europe", ""},
+		"Continent ISO":  {"EU", ""},
+		"Listed country": {"France", ""},
+		"Listed city":    {"Paris", ""},
+		"US state":       {"New York", ""},
+		"Typo":           {"Frankfrt", "unknown location 'Frankfrt', did you mean 'Frankfurt'?"},
+		"Prefix":         {"Frank", "unknown location 'Frank', did you mean 'Frankfurt'?"},
+		"Swapped words":  {"Kong Hong", "unknown location 'Kong Hong', did you mean 'Hong Kong'?"},
+		"Unknown":        {"Atlantis", "unknown location 'Atlantis'"},
+	}
+	cat := testCatalog()
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := cat.ValidateLocation(tc.loc)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tc.exp {
+				t.Fatalf("expected %q; got %q", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"Austria", "Australia", "Germany", "Armenia"}
+	testCases := map[string]struct {
+		s   string
+		max int
+		exp []string
+	}{
+		"None":  {"Japan", 3, nil},
+		"One":   {"Germani", 3, []string{"Germany"}},
+		"Many":  {"Austrlia", 3, []string{"Australia", "Austria"}},
+		"Limit": {"Austrlia", 1, []string{"Australia"}},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if got := Suggest(tc.s, candidates, tc.max); !reflect.DeepEqual(got, tc.exp) {
+				t.Fatalf("expected %v; got %v", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestLocationError(t *testing.T) {
+	err := &LocationError{"Meep", []string{"Moop", "Meeps", "Mep"}}
+	if got, exp := err.Error(), "unknown location 'Meep', did you mean 'Moop', 'Meeps' or 'Mep'?"; got != exp {
+		t.Fatalf("expected %q; got %q", exp, got)
+	}
+}
+
+func TestCatalogSaveLoad(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "perfops", "catalog.json")
+	if _, err := LoadCatalog(p); err == nil {
+		t.Fatal("expected error for missing catalog; got nil")
+	}
+	cat := testCatalog()
+	cat.Updated = time.Now().Add(-2 * CatalogTTL)
+	if err := cat.Save(p); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	got, err := LoadCatalog(p)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got, exp := len(got.Nodes), len(cat.Nodes); got != exp {
+		t.Fatalf("expected %v nodes; got %v", exp, got)
+	}
+	if !got.Expired(CatalogTTL) {
+		t.Fatal("expected catalog to be expired")
+	}
+}
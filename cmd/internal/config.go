@@ -0,0 +1,55 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+)
+
+// Config represents the user settings applied to every run.
+type Config struct {
+	// ExcludeNodes lists the IDs of nodes never to run a test from.
+	ExcludeNodes []int `json:"exclude_nodes,omitempty"`
+	// ExcludeCountries lists the names or ISO codes of countries never
+	// to run a test from.
+	ExcludeCountries []string `json:"exclude_countries,omitempty"`
+	// DailyCreditBudget is the maximum number of credits the tests of a
+	// day may use, 0 for no limit.
+	DailyCreditBudget int `json:"daily_credit_budget,omitempty"`
+	// SettleFromRemaining records the difference of the remaining credits
+	// before and after a test if the API does not report the credits it
+	// used. Tests run meanwhile with the same API key are counted too.
+	SettleFromRemaining bool `json:"settle_from_remaining,omitempty"`
+	// CreditCosts are the credits per node by test type to estimate
+	// tests with instead of the costs recorded in the history.
+	CreditCosts map[string]int `json:"credit_costs,omitempty"`
+}
+
+// LoadConfig reads the config file at path. A missing config file is not
+// an error.
+func LoadConfig(path string) (*Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "config.json")
+	cfg, err := LoadConfig(p)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(cfg, &Config{}) {
+		t.Fatalf("expected empty config; got %v", cfg)
+	}
+
+	ioutil.WriteFile(p, []byte(`{"exclude_nodes": [12, 27], "exclude_countries": ["CN"]}`), 0644)
+	cfg, err = LoadConfig(p)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if exp := (&Config{ExcludeNodes: []int{12, 27}, ExcludeCountries: []string{"CN"}}); !reflect.DeepEqual(cfg, exp) {
+		t.Fatalf("expected %v; got %v", exp, cfg)
+	}
+
+	ioutil.WriteFile(p, []byte("{"), 0644)
+	if _, err := LoadConfig(p); err == nil {
+		t.Fatal("expected error for malformed config; got nil")
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+// CreditAccount guards the credits a test may use and accounts for the
+// credits it used. The methods of a nil account do nothing.
+type CreditAccount struct {
+	// MaxCredits is the maximum number of credits a test may use, 0 for
+	// no limit.
+	MaxCredits int
+	// DailyBudget is the maximum number of credits the tests of a day may
+	// use, 0 for no limit.
+	DailyBudget int
+	// UsedToday is the number of credits the tests of the day used so
+	// far.
+	UsedToday int
+	// Costs are the credits per node by test type overriding the default
+	// estimates, e.g., as reported for earlier tests.
+	Costs map[string]int
+	// CheckRemaining refuses tests needing more than the remaining
+	// credits, e.g., of a dry run. Otherwise the API decides.
+	CheckRemaining bool
+	// SettleFromRemaining settles tests the API reported no withdrawn
+	// credits for with the difference of the remaining credits before and
+	// after them. The difference includes the credits other runs with the
+	// same API key used meanwhile.
+	SettleFromRemaining bool
+	// Remaining retrieves the remaining credits.
+	Remaining func() (int, error)
+	// Record records the credits a test used and the number of nodes it
+	// ran on, e.g., in the history.
+	Record func(testID perfops.TestID, credits, nodes int)
+
+	before *int
+	used   *int
+}
+
+// Check estimates the credits of the plan with the costs of the account
+// and refuses a test whose estimated credits exceed the maximum per test
+// or the rest of the daily budget. The remaining credits are only
+// retrieved to check them or to settle the test with them.
+func (a *CreditAccount) Check(p *Plan) error {
+	if a == nil {
+		return nil
+	}
+	if c, ok := a.Costs[p.Type]; ok && c > 0 {
+		p.SetCreditsPerNode(c)
+	}
+	if a.Remaining != nil && (a.CheckRemaining || a.SettleFromRemaining) {
+		// The API decides if the remaining credits are unknown.
+		if n, err := a.Remaining(); err == nil {
+			a.before = &n
+		}
+	}
+	if a.MaxCredits > 0 && p.Credits > a.MaxCredits {
+		return fmt.Errorf("the test needs an estimated %d credits, more than the maximum of %d", p.Credits, a.MaxCredits)
+	}
+	if a.DailyBudget > 0 && a.UsedToday+p.Credits > a.DailyBudget {
+		return fmt.Errorf("the test needs an estimated %d credits, but only %d of the daily budget of %d are left", p.Credits, max(0, a.DailyBudget-a.UsedToday), a.DailyBudget)
+	}
+	if a.CheckRemaining && a.before != nil && p.Credits > *a.before {
+		return fmt.Errorf("the test needs an estimated %d credits, but only %d are remaining", p.Credits, *a.before)
+	}
+	return nil
+}
+
+// RemainingBefore returns the remaining credits before the test or nil
+// if they are unknown.
+func (a *CreditAccount) RemainingBefore() *int {
+	if a == nil {
+		return nil
+	}
+	return a.before
+}
+
+// Settle determines the credits a finished test used and records them
+// with the number of nodes it ran on. These are the credits withdrawn as
+// reported by the API, nil if not reported.
+func (a *CreditAccount) Settle(testID perfops.TestID, withdrawn *int, nodes int) {
+	a.SettleTests([]perfops.TestID{testID}, []*int{withdrawn}, []int{nodes})
+}
+
+// SettleTests determines the credits used by finished tests run together,
+// e.g., against several DNS servers, and records them. The credits of the
+// tests the API reported withdrawn credits for are recorded. If it did
+// not report them for every test, the credits used are unknown unless
+// SettleFromRemaining is set. Then the difference of the remaining
+// credits is used, and the part not reported is recorded for the first
+// test without withdrawn credits.
+func (a *CreditAccount) SettleTests(testIDs []perfops.TestID, withdrawn []*int, nodes []int) {
+	if a == nil || len(testIDs) == 0 {
+		return
+	}
+	unreported, sum := -1, 0
+	for i, id := range testIDs {
+		if i >= len(withdrawn) || withdrawn[i] == nil {
+			if unreported < 0 {
+				unreported = i
+			}
+			continue
+		}
+		sum += *withdrawn[i]
+		if a.Record != nil {
+			a.Record(id, *withdrawn[i], nodes[i])
+		}
+	}
+	if unreported < 0 {
+		a.used = &sum
+		return
+	}
+	if !a.SettleFromRemaining || a.before == nil || a.Remaining == nil {
+		return
+	}
+	if n, err := a.Remaining(); err == nil && n <= *a.before && *a.before-n >= sum {
+		used := *a.before - n
+		a.used = &used
+		if a.Record != nil {
+			a.Record(testIDs[unreported], used-sum, nodes[unreported])
+		}
+	}
+}
+
+// Used returns the credits the test used or nil if they are unknown.
+func (a *CreditAccount) Used() *int {
+	if a == nil {
+		return nil
+	}
+	return a.used
+}
+
+// PrintCreditsUsed prints the credits the test used, if known.
+func PrintCreditsUsed(w io.Writer, a *CreditAccount) error {
+	used := a.Used()
+	if used == nil {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "Credits used: %d\n", *used)
+	return err
+}
@@ -0,0 +1,146 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func TestCreditAccountCheck(t *testing.T) {
+	remaining := func(n int) func() (int, error) {
+		return func() (int, error) { return n, nil }
+	}
+	testCases := map[string]struct {
+		a      *CreditAccount
+		nodes  int
+		expErr string
+	}{
+		"Nil":               {nil, 100, ""},
+		"Within":            {&CreditAccount{MaxCredits: 10, DailyBudget: 20, UsedToday: 10, Remaining: remaining(10)}, 10, ""},
+		"Max credits":       {&CreditAccount{MaxCredits: 10}, 11, "the test needs an estimated 11 credits, more than the maximum of 10"},
+		"Daily budget":      {&CreditAccount{DailyBudget: 20, UsedToday: 15}, 6, "the test needs an estimated 6 credits, but only 5 of the daily budget of 20 are left"},
+		"Budget used":       {&CreditAccount{DailyBudget: 20, UsedToday: 25}, 1, "the test needs an estimated 1 credits, but only 0 of the daily budget of 20 are left"},
+		"Remaining":         {&CreditAccount{CheckRemaining: true, Remaining: remaining(3)}, 4, "the test needs an estimated 4 credits, but only 3 are remaining"},
+		"Unchecked":         {&CreditAccount{Remaining: remaining(3)}, 4, ""},
+		"Unknown remaining": {&CreditAccount{CheckRemaining: true, Remaining: func() (int, error) { return 0, errors.New("unauthorized") }}, 4, ""},
+		"Costs":             {&CreditAccount{MaxCredits: 10, Costs: map[string]int{"ping": 3, "mtr": 1}}, 4, "the test needs an estimated 12 credits, more than the maximum of 10"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.a.Check(NewPlan("ping", "example.com", "", nil, nil, tc.nodes))
+			if (err == nil && tc.expErr != "") || (err != nil && err.Error() != tc.expErr) {
+				t.Fatalf("expected %q; got %v", tc.expErr, err)
+			}
+		})
+	}
+
+	// The plan is estimated with the costs of its type.
+	p := NewPlan("ping", "example.com", "", nil, nil, 3)
+	if err := (&CreditAccount{Costs: map[string]int{"ping": 3}}).Check(p); err != nil || p.Credits != 9 || p.CreditsPerNode != 3 {
+		t.Fatalf("expected 9 credits at 3 per node; got %d at %d, %v", p.Credits, p.CreditsPerNode, err)
+	}
+
+	// The remaining credits are not retrieved without a need for them.
+	a := &CreditAccount{MaxCredits: 10, Remaining: func() (int, error) {
+		t.Fatal("unexpected request of the remaining credits")
+		return 0, nil
+	}}
+	if err := a.Check(NewPlan("ping", "example.com", "", nil, nil, 5)); err != nil || a.RemainingBefore() != nil {
+		t.Fatalf("expected no remaining credits; got %v, %v", a.RemainingBefore(), err)
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestCreditAccountSettle(t *testing.T) {
+	remaining := []int{100, 94}
+	var recorded []int
+	a := &CreditAccount{
+		SettleFromRemaining: true,
+		Remaining: func() (int, error) {
+			n := remaining[0]
+			remaining = remaining[1:]
+			return n, nil
+		},
+		Record: func(testID perfops.TestID, credits, nodes int) { recorded = append(recorded, credits) },
+	}
+	if err := a.Check(NewPlan("ping", "example.com", "", nil, nil, 5)); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got := a.RemainingBefore(); got == nil || *got != 100 {
+		t.Fatalf("expected 100 remaining credits; got %v", got)
+	}
+	a.Settle("abc", nil, 3)
+	if got := a.Used(); got == nil || *got != 6 {
+		t.Fatalf("expected 6 used credits; got %v", got)
+	}
+	a.Settle("abc", intPtr(5), 3)
+	if got := a.Used(); got == nil || *got != 5 {
+		t.Fatalf("expected 5 withdrawn credits; got %v", got)
+	}
+	// No remaining credits are left to compare, a test may cost nothing.
+	a.Settle("def", intPtr(0), 3)
+	if got := a.Used(); got == nil || *got != 0 {
+		t.Fatalf("expected 0 withdrawn credits; got %v", got)
+	}
+	if len(recorded) != 3 || recorded[0] != 6 || recorded[1] != 5 || recorded[2] != 0 {
+		t.Fatalf("expected credits 6, 5 and 0 to be recorded; got %v", recorded)
+	}
+
+	// Without SettleFromRemaining unreported credits are unknown.
+	unknown := &CreditAccount{Remaining: func() (int, error) { return 0, errors.New("unexpected") }}
+	unknown.Settle("abc", nil, 3)
+	if got := unknown.Used(); got != nil {
+		t.Fatalf("expected unknown credits; got %v", *got)
+	}
+
+	var b bytes.Buffer
+	if err := PrintCreditsUsed(&b, a); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got, exp := b.String(), "Credits used: 0\n"; got != exp {
+		t.Fatalf("expected %q; got %q", exp, got)
+	}
+	b.Reset()
+	var none *CreditAccount
+	none.Settle("abc", intPtr(5), 3)
+	if err := PrintCreditsUsed(&b, none); err != nil || b.Len() != 0 {
+		t.Fatalf("expected no output; got %q, %v", b.String(), err)
+	}
+}
+
+func TestCreditAccountSettleTests(t *testing.T) {
+	recorded := map[perfops.TestID]int{}
+	a := &CreditAccount{
+		SettleFromRemaining: true,
+		Remaining:           func() (int, error) { return 90, nil },
+		Record:              func(testID perfops.TestID, credits, nodes int) { recorded[testID] += credits },
+	}
+	before := 100
+	a.before = &before
+	a.SettleTests([]perfops.TestID{"a", "b"}, []*int{intPtr(3), intPtr(0)}, []int{3, 3})
+	if got := a.Used(); got == nil || *got != 3 || recorded["a"] != 3 || recorded["b"] != 0 {
+		t.Fatalf("expected 3 credits recorded per test; got %v, %v", got, recorded)
+	}
+	a.SettleTests([]perfops.TestID{"c", "d"}, []*int{intPtr(3), nil}, []int{3, 3})
+	if got := a.Used(); got == nil || *got != 10 || recorded["c"] != 3 || recorded["d"] != 7 {
+		t.Fatalf("expected the 7 unreported credits recorded on test d; got %v, %v", got, recorded)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+type (
+	// ASN summarizes the test nodes in an autonomous system.
+	ASN struct {
+		Number    int      `json:"as_number"`
+		Nodes     int      `json:"nodes"`
+		Countries []string `json:"countries"`
+	}
+
+	// treeNode is a node of a location tree.
+	treeNode struct {
+		name     string
+		children map[string]*treeNode
+	}
+)
+
+// unknownLocation is the name used for locations missing in the data.
+const unknownLocation = "Unknown"
+
+// PrintTable writes a header and rows as an aligned table to w.
+func PrintTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, r := range rows {
+		fmt.Fprintln(tw, strings.Join(r, "\t"))
+	}
+	return tw.Flush()
+}
+
+// PrintContinentsTable writes the continents as a table to w.
+func PrintContinentsTable(w io.Writer, continents []*perfops.Continent) error {
+	var rows [][]string
+	for _, c := range continents {
+		rows = append(rows, []string{strconv.Itoa(c.ID), c.Name, c.ISO})
+	}
+	return PrintTable(w, []string{"ID", "NAME", "ISO"}, rows)
+}
+
+// PrintCountriesTable writes the countries as a table to w.
+func PrintCountriesTable(w io.Writer, countries []*perfops.Country) error {
+	var rows [][]string
+	for _, c := range countries {
+		rows = append(rows, []string{strconv.Itoa(c.ID), c.Name, c.ISO, strconv.Itoa(int(c.ISONumeric)), c.Continent.GetName()})
+	}
+	return PrintTable(w, []string{"ID", "NAME", "ISO", "ISO-NUMERIC", "CONTINENT"}, rows)
+}
+
+// PrintCitiesTable writes the cities as a table to w.
+func PrintCitiesTable(w io.Writer, cities []*perfops.City) error {
+	var rows [][]string
+	for _, c := range cities {
+		rows = append(rows, []string{c.Name, c.Country.GetName(), c.Continent.GetName()})
+	}
+	return PrintTable(w, []string{"NAME", "COUNTRY", "CONTINENT"}, rows)
+}
+
+// PrintRegionsTable writes the regions as a table to w.
+func PrintRegionsTable(w io.Writer, regions []*perfops.Region) error {
+	var rows [][]string
+	for _, r := range regions {
+		rows = append(rows, []string{r.Name, r.Continent.GetName()})
+	}
+	return PrintTable(w, []string{"NAME", "CONTINENT"}, rows)
+}
+
+// PrintASNsTable writes the autonomous systems as a table to w.
+func PrintASNsTable(w io.Writer, asns []*ASN) error {
+	var rows [][]string
+	for _, a := range asns {
+		rows = append(rows, []string{strconv.Itoa(a.Number), strconv.Itoa(a.Nodes), strings.Join(a.Countries, ", ")})
+	}
+	return PrintTable(w, []string{"ASN", "NODES", "COUNTRIES"}, rows)
+}
+
+// NodeASNs returns the autonomous systems of the nodes ordered by AS
+// number.
+func NodeASNs(nodes []*perfops.Node) []*ASN {
+	byNumber := map[int]*ASN{}
+	var res []*ASN
+	for _, n := range nodes {
+		a, ok := byNumber[n.AsNumber]
+		if !ok {
+			a = &ASN{Number: n.AsNumber}
+			byNumber[n.AsNumber] = a
+			res = append(res, a)
+		}
+		a.Nodes++
+		if c := n.CountryName(); c != "" && !containsString(a.Countries, c) {
+			a.Countries = append(a.Countries, c)
+		}
+	}
+	for _, a := range res {
+		sort.Strings(a.Countries)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Number < res[j].Number })
+	return res
+}
+
+// PrintLocationTree writes the locations as a tree to w. Each path lists
+// the location names from the top level down, e.g., continent, country
+// and city.
+func PrintLocationTree(w io.Writer, paths [][]string) error {
+	root := &treeNode{}
+	for _, p := range paths {
+		n := root
+		for _, name := range p {
+			if name == "" {
+				name = unknownLocation
+			}
+			if n.children == nil {
+				n.children = map[string]*treeNode{}
+			}
+			c, ok := n.children[name]
+			if !ok {
+				c = &treeNode{name: name}
+				n.children[name] = c
+			}
+			n = c
+		}
+	}
+	for _, c := range root.sorted() {
+		if _, err := fmt.Fprintln(w, c.name); err != nil {
+			return err
+		}
+		if err := c.print(w, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *treeNode) sorted() []*treeNode {
+	var res []*treeNode
+	for _, c := range n.children {
+		res = append(res, c)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].name < res[j].name })
+	return res
+}
+
+func (n *treeNode) print(w io.Writer, indent string) error {
+	children := n.sorted()
+	for i, c := range children {
+		branch, next := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, next = "└── ", "    "
+		}
+		if _, err := fmt.Fprintln(w, indent+branch+c.name); err != nil {
+			return err
+		}
+		if err := c.print(w, indent+next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func containsString(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
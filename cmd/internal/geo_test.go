@@ -0,0 +1,68 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrintLocationTree(t *testing.T) {
+	var b bytes.Buffer
+	paths := [][]string{
+		{"Europe", "Germany", "Nuremberg"},
+		{"Asia", "Hong Kong", "Hong Kong"},
+		{"Europe", "Germany", "Frankfurt"},
+		{"Europe", "France", "Paris"},
+		{"", "", "Atlantis"},
+	}
+	if err := PrintLocationTree(&b, paths); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	exp := `Asia
+└── Hong Kong
+    └── Hong Kong
+Europe
+├── France
+│   └── Paris
+└── Germany
+    ├── Frankfurt
+    └── Nuremberg
+Unknown
+└── Unknown
+    └── Atlantis
+`
+	if got := b.String(); got != exp {
+		t.Fatalf("expected\n%s\ngot\n%s", exp, got)
+	}
+}
+
+func TestNodeASNs(t *testing.T) {
+	asns := NodeASNs(testNodes())
+	if len(asns) == 0 {
+		t.Fatal("expected ASNs; got none")
+	}
+	for i := 1; i < len(asns); i++ {
+		if asns[i-1].Number >= asns[i].Number {
+			t.Fatalf("expected ASNs ordered by number; got %v before %v", asns[i-1].Number, asns[i].Number)
+		}
+	}
+	var b bytes.Buffer
+	if err := PrintASNsTable(&b, asns); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !bytes.HasPrefix(b.Bytes(), []byte("ASN  ")) {
+		t.Fatalf("expected table header; got %q", b.String())
+	}
+}
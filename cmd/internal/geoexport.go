@@ -0,0 +1,167 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+type (
+	// FeatureCollection represents a GeoJSON feature collection.
+	FeatureCollection struct {
+		Type     string     `json:"type"`
+		Features []*Feature `json:"features"`
+	}
+
+	// Feature represents a GeoJSON feature of the result of a node.
+	Feature struct {
+		Type       string             `json:"type"`
+		Geometry   *Point             `json:"geometry"`
+		Properties *FeatureProperties `json:"properties"`
+	}
+
+	// Point represents a GeoJSON point. The coordinates are longitude
+	// and latitude.
+	Point struct {
+		Type        string     `json:"type"`
+		Coordinates [2]float64 `json:"coordinates"`
+	}
+
+	// FeatureProperties represents the properties of a node result
+	// feature.
+	FeatureProperties struct {
+		ID        string             `json:"id,omitempty"`
+		NodeID    int                `json:"node_id"`
+		AsNumber  int                `json:"as_number,omitempty"`
+		City      string             `json:"city,omitempty"`
+		Country   string             `json:"country,omitempty"`
+		Continent string             `json:"continent,omitempty"`
+		Status    perfops.Status     `json:"status"`
+		Reason    string             `json:"reason,omitempty"`
+		Metrics   map[string]float64 `json:"metrics,omitempty"`
+		// Cluster and Answers are set for the nodes of a DNS geo map.
+		Cluster string   `json:"cluster,omitempty"`
+		Answers []string `json:"answers,omitempty"`
+	}
+
+	kml struct {
+		XMLName  xml.Name    `xml:"kml"`
+		NS       string      `xml:"xmlns,attr"`
+		Document kmlDocument `xml:"Document"`
+	}
+
+	kmlDocument struct {
+		Name       string          `xml:"name,omitempty"`
+		Placemarks []*kmlPlacemark `xml:"Placemark"`
+	}
+
+	kmlPlacemark struct {
+		Name         string    `xml:"name"`
+		Description  string    `xml:"description,omitempty"`
+		ExtendedData []kmlData `xml:"ExtendedData>Data"`
+		Point        *kmlPoint `xml:"Point,omitempty"`
+	}
+
+	kmlData struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value"`
+	}
+
+	kmlPoint struct {
+		Coordinates string `xml:"coordinates"`
+	}
+)
+
+// NewFeatureCollection returns the results as GeoJSON features. Nodes
+// without coordinates are looked up in nodes, e.g., the node catalog, and
+// have no geometry if they are unknown.
+func NewFeatureCollection(results []*Result, nodes []*perfops.Node) *FeatureCollection {
+	locate := nodeLocator(nodes)
+	fc := &FeatureCollection{Type: "FeatureCollection", Features: []*Feature{}}
+	for _, r := range results {
+		f := &Feature{Type: "Feature", Properties: featureProperties(r)}
+		if n := locate(r.Node); n != nil {
+			f.Geometry = &Point{Type: "Point", Coordinates: [2]float64{n.Longitude, n.Latitude}}
+		}
+		fc.Features = append(fc.Features, f)
+	}
+	return fc
+}
+
+func featureProperties(r *Result) *FeatureProperties {
+	p := &FeatureProperties{ID: r.ID, Status: r.Status, Reason: r.Reason, Metrics: r.Metrics}
+	if n := r.Node; n != nil {
+		p.NodeID, p.AsNumber, p.City = n.ID, n.AsNumber, n.City
+		p.Country, p.Continent = n.CountryName(), n.ContinentName()
+	}
+	return p
+}
+
+// WriteGeoJSON writes the results as a GeoJSON feature collection to w.
+func WriteGeoJSON(w io.Writer, results []*Result, nodes []*perfops.Node) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(NewFeatureCollection(results, nodes))
+}
+
+// WriteKML writes the results as KML placemarks to w. The properties of
+// a result are written as extended data.
+func WriteKML(w io.Writer, name string, results []*Result, nodes []*perfops.Node) error {
+	doc := &kml{NS: "http://www.opengis.net/kml/2.2", Document: kmlDocument{Name: name}}
+	for _, f := range NewFeatureCollection(results, nodes).Features {
+		p := f.Properties
+		pm := &kmlPlacemark{
+			Name:        "Node" + strconv.Itoa(p.NodeID),
+			Description: p.Reason,
+			ExtendedData: []kmlData{
+				{Name: "node_id", Value: strconv.Itoa(p.NodeID)},
+				{Name: "as_number", Value: strconv.Itoa(p.AsNumber)},
+				{Name: "city", Value: p.City},
+				{Name: "country", Value: p.Country},
+				{Name: "continent", Value: p.Continent},
+				{Name: "status", Value: string(p.Status)},
+			},
+		}
+		var metrics []string
+		for m := range p.Metrics {
+			metrics = append(metrics, m)
+		}
+		sort.Strings(metrics)
+		for _, m := range metrics {
+			pm.ExtendedData = append(pm.ExtendedData, kmlData{Name: m, Value: strconv.FormatFloat(p.Metrics[m], 'f', -1, 64)})
+		}
+		if g := f.Geometry; g != nil {
+			pm.Point = &kmlPoint{Coordinates: fmt.Sprintf("%s,%s,0",
+				strconv.FormatFloat(g.Coordinates[0], 'f', -1, 64), strconv.FormatFloat(g.Coordinates[1], 'f', -1, 64))}
+		}
+		doc.Document.Placemarks = append(doc.Document.Placemarks, pm)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
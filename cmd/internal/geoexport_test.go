@@ -0,0 +1,158 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func geoTestResults() ([]*Result, []*perfops.Node) {
+	de := &perfops.Country{Name: "Germany", Continent: &perfops.Continent{Name: "Europe"}}
+	results := []*Result{
+		{ID: "a1", Node: &perfops.Node{ID: 5, AsNumber: 3320, City: "Frankfurt", Country: de}, Status: perfops.StatusOK, Metrics: map[string]float64{MetricLoss: 0, MetricRTT: 3.5}},
+		{ID: "a2", Node: &perfops.Node{ID: 30, AsNumber: 3320, City: "Berlin", Country: de}, Status: perfops.StatusTimeout, Reason: "timed out"},
+	}
+	return results, []*perfops.Node{{ID: 5, Latitude: 50.11, Longitude: 8.69}}
+}
+
+func TestWriteGeoJSON(t *testing.T) {
+	results, nodes := geoTestResults()
+	var b bytes.Buffer
+	if err := WriteGeoJSON(&b, results, nodes); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	exp := `{
+  "type": "FeatureCollection",
+  "features": [
+    {
+      "type": "Feature",
+      "geometry": {
+        "type": "Point",
+        "coordinates": [
+          8.69,
+          50.11
+        ]
+      },
+      "properties": {
+        "id": "a1",
+        "node_id": 5,
+        "as_number": 3320,
+        "city": "Frankfurt",
+        "country": "Germany",
+        "continent": "Europe",
+        "status": "ok",
+        "metrics": {
+          "loss": 0,
+          "rtt": 3.5
+        }
+      }
+    },
+    {
+      "type": "Feature",
+      "geometry": null,
+      "properties": {
+        "id": "a2",
+        "node_id": 30,
+        "as_number": 3320,
+        "city": "Berlin",
+        "country": "Germany",
+        "continent": "Europe",
+        "status": "timeout",
+        "reason": "timed out"
+      }
+    }
+  ]
+}
+`
+	if got := b.String(); got != exp {
+		t.Fatalf("expected\n%s\ngot\n%s", exp, got)
+	}
+}
+
+func TestWriteKML(t *testing.T) {
+	results, nodes := geoTestResults()
+	var b bytes.Buffer
+	if err := WriteKML(&b, "ping 123", results, nodes); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	exp := `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+  <Document>
+    <name>ping 123</name>
+    <Placemark>
+      <name>Node5</name>
+      <ExtendedData>
+        <Data name="node_id">
+          <value>5</value>
+        </Data>
+        <Data name="as_number">
+          <value>3320</value>
+        </Data>
+        <Data name="city">
+          <value>Frankfurt</value>
+        </Data>
+        <Data name="country">
+          <value>Germany</value>
+        </Data>
+        <Data name="continent">
+          <value>Europe</value>
+        </Data>
+        <Data name="status">
+          <value>ok</value>
+        </Data>
+        <Data name="loss">
+          <value>0</value>
+        </Data>
+        <Data name="rtt">
+          <value>3.5</value>
+        </Data>
+      </ExtendedData>
+      <Point>
+        <coordinates>8.69,50.11,0</coordinates>
+      </Point>
+    </Placemark>
+    <Placemark>
+      <name>Node30</name>
+      <description>timed out</description>
+      <ExtendedData>
+        <Data name="node_id">
+          <value>30</value>
+        </Data>
+        <Data name="as_number">
+          <value>3320</value>
+        </Data>
+        <Data name="city">
+          <value>Berlin</value>
+        </Data>
+        <Data name="country">
+          <value>Germany</value>
+        </Data>
+        <Data name="continent">
+          <value>Europe</value>
+        </Data>
+        <Data name="status">
+          <value>timeout</value>
+        </Data>
+      </ExtendedData>
+    </Placemark>
+  </Document>
+</kml>
+`
+	if got := b.String(); got != exp {
+		t.Fatalf("expected\n%s\ngot\n%s", exp, got)
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+// clusterColors are the colors of the answer clusters on the map.
+var clusterColors = []int{colorGreen, colorYellow, 36, 35, 34, colorRed}
+
+type (
+	// AnswerCluster represents the nodes receiving the same answers.
+	AnswerCluster struct {
+		// Label tells the clusters apart, e.g., on the map.
+		Label     string          `json:"label"`
+		Answers   []string        `json:"answers"`
+		Nodes     []*perfops.Node `json:"nodes"`
+		Countries []string        `json:"countries"`
+		Cities    []string        `json:"cities"`
+		ASNs      []int           `json:"asns"`
+	}
+
+	// NodeFailure represents a node failing to resolve a record.
+	NodeFailure struct {
+		Node   *perfops.Node `json:"node"`
+		Reason string        `json:"reason"`
+	}
+
+	// GeoMap represents the answers of a DNS record by location.
+	GeoMap struct {
+		Name      string           `json:"name"`
+		Type      string           `json:"type"`
+		DNSServer string           `json:"dns_server"`
+		TestID    string           `json:"test_id"`
+		Clusters  []*AnswerCluster `json:"clusters"`
+		Failed    []*NodeFailure   `json:"failed,omitempty"`
+		// CreditsUsed is nil if the credits used are unknown.
+		CreditsUsed *int `json:"credits_used,omitempty"`
+	}
+)
+
+// NewGeoMap clusters the nodes of the output of a DNS resolve test by the
+// answers they received. Clusters are ordered by their number of nodes,
+// most first, and labeled A, B, C and so on.
+func NewGeoMap(name, queryType, dnsServer string, o *perfops.DNSTestOutput) *GeoMap {
+	g := &GeoMap{Name: name, Type: queryType, DNSServer: dnsServer, TestID: o.ID, Clusters: []*AnswerCluster{}}
+	byAnswers := map[string]*AnswerCluster{}
+	for _, item := range o.Items {
+		r := item.Result
+		if r == nil {
+			continue
+		}
+		set := newRecordSet(queryType, o.ID, r)
+		if set.Reason != "" && len(set.Records) == 0 {
+			g.Failed = append(g.Failed, &NodeFailure{Node: r.Node, Reason: set.Reason})
+			continue
+		}
+		answers := recordValues(set.Records)
+		key := strings.Join(answers, "\n")
+		c := byAnswers[key]
+		if c == nil {
+			c = &AnswerCluster{Answers: append([]string{}, answers...)}
+			byAnswers[key] = c
+			g.Clusters = append(g.Clusters, c)
+		}
+		if r.Node != nil {
+			c.Nodes = append(c.Nodes, r.Node)
+		}
+	}
+	sort.SliceStable(g.Clusters, func(i, j int) bool {
+		a, b := g.Clusters[i], g.Clusters[j]
+		if len(a.Nodes) != len(b.Nodes) {
+			return len(a.Nodes) > len(b.Nodes)
+		}
+		return strings.Join(a.Answers, " ") < strings.Join(b.Answers, " ")
+	})
+	for i, c := range g.Clusters {
+		c.Label = clusterLabel(i)
+		sort.SliceStable(c.Nodes, func(i, j int) bool { return nodeLess(c.Nodes[i], c.Nodes[j]) })
+		c.Countries, c.Cities, c.ASNs = []string{}, []string{}, []int{}
+		for _, n := range c.Nodes {
+			if s := n.CountryName(); s != "" && !containsString(c.Countries, s) {
+				c.Countries = append(c.Countries, s)
+			}
+			if n.City != "" && !containsString(c.Cities, n.City) {
+				c.Cities = append(c.Cities, n.City)
+			}
+			if n.AsNumber != 0 && !containsInt(c.ASNs, n.AsNumber) {
+				c.ASNs = append(c.ASNs, n.AsNumber)
+			}
+		}
+		sort.Strings(c.Countries)
+		sort.Strings(c.Cities)
+		sort.Ints(c.ASNs)
+	}
+	sort.SliceStable(g.Failed, func(i, j int) bool { return nodeLess(g.Failed[i].Node, g.Failed[j].Node) })
+	return g
+}
+
+// clusterLabel returns the label of the i-th cluster, A to Z and then
+// numbers.
+func clusterLabel(i int) string {
+	if i < 26 {
+		return string(rune('A' + i))
+	}
+	return strconv.Itoa(i + 1)
+}
+
+// clusterSymbol returns the symbol of a cluster on the map.
+func clusterSymbol(i int) string {
+	s := "*"
+	if i < 26 {
+		s = clusterLabel(i)
+	}
+	return colorize(clusterColors[i%len(clusterColors)], s)
+}
+
+func containsInt(a []int, v int) bool {
+	for _, x := range a {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintGeoMap prints each answer cluster with the countries, cities and
+// autonomous systems of its nodes, and the failed nodes.
+func PrintGeoMap(w io.Writer, g *GeoMap) error {
+	var b strings.Builder
+	nodes := len(g.Failed)
+	for _, c := range g.Clusters {
+		nodes += len(c.Nodes)
+	}
+	fmt.Fprintf(&b, "%s %s from %d nodes via %s: %d answer sets, %d failed\n", g.Name, g.Type, nodes, g.DNSServer, len(g.Clusters), len(g.Failed))
+	for _, c := range g.Clusters {
+		answers := strings.Join(c.Answers, " ")
+		if answers == "" {
+			answers = "(empty)"
+		}
+		asns := make([]string, len(c.ASNs))
+		for i, n := range c.ASNs {
+			asns[i] = "AS" + strconv.Itoa(n)
+		}
+		fmt.Fprintf(&b, "\n[%s] %s (%d nodes)\n", c.Label, answers, len(c.Nodes))
+		fmt.Fprintf(&b, "    Countries: %s\n", strings.Join(c.Countries, ", "))
+		fmt.Fprintf(&b, "    Cities:    %s\n", strings.Join(c.Cities, ", "))
+		fmt.Fprintf(&b, "    ASNs:      %s\n", strings.Join(asns, ", "))
+	}
+	if len(g.Failed) > 0 {
+		fmt.Fprintln(&b, "\nFailed:")
+		for _, f := range g.Failed {
+			fmt.Fprintf(&b, "    %s: %s\n", nodeLocation(f.Node), f.Reason)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// PrintGeoMapMap writes a world map with the nodes labeled by their answer
+// cluster to w. Nodes without coordinates are looked up in nodes.
+func PrintGeoMapMap(w io.Writer, g *GeoMap, nodes []*perfops.Node) error {
+	var (
+		points []mapPoint
+		legend []string
+	)
+	for i, c := range g.Clusters {
+		// Smaller clusters win a shared cell to not hide outliers.
+		for _, n := range c.Nodes {
+			points = append(points, mapPoint{node: n, symbol: clusterSymbol(i), rank: i + 1})
+		}
+		answers := strings.Join(c.Answers, " ")
+		if answers == "" {
+			answers = "(empty)"
+		}
+		legend = append(legend, fmt.Sprintf("%s %s", clusterSymbol(i), truncate(answers, 40)))
+	}
+	for _, f := range g.Failed {
+		points = append(points, mapPoint{node: f.Node, symbol: mapSymbol(bucketFailed)})
+	}
+	if len(g.Failed) > 0 {
+		legend = append(legend, mapSymbol(bucketFailed)+" failed")
+	}
+	return drawMap(w, points, nodes, strings.Join(legend, "  "))
+}
+
+// NewGeoMapFeatures returns the nodes of the answer clusters as GeoJSON
+// features with the cluster label and answers as properties. Nodes without
+// coordinates are looked up in nodes and have no geometry if unknown.
+func NewGeoMapFeatures(g *GeoMap, nodes []*perfops.Node) *FeatureCollection {
+	locate := nodeLocator(nodes)
+	fc := &FeatureCollection{Type: "FeatureCollection", Features: []*Feature{}}
+	add := func(n *perfops.Node, p *FeatureProperties) {
+		f := &Feature{Type: "Feature", Properties: p}
+		if n != nil {
+			p.NodeID, p.AsNumber, p.City = n.ID, n.AsNumber, n.City
+			p.Country, p.Continent = n.CountryName(), n.ContinentName()
+		}
+		if l := locate(n); l != nil {
+			f.Geometry = &Point{Type: "Point", Coordinates: [2]float64{l.Longitude, l.Latitude}}
+		}
+		fc.Features = append(fc.Features, f)
+	}
+	for _, c := range g.Clusters {
+		for _, n := range c.Nodes {
+			add(n, &FeatureProperties{Status: perfops.StatusOK, Cluster: c.Label, Answers: c.Answers})
+		}
+	}
+	for _, f := range g.Failed {
+		add(f.Node, &FeatureProperties{Status: perfops.StatusError, Reason: f.Reason})
+	}
+	return fc
+}
+
+// WriteGeoMapGeoJSON writes the nodes of the answer clusters as a GeoJSON
+// feature collection to w.
+func WriteGeoMapGeoJSON(w io.Writer, g *GeoMap, nodes []*perfops.Node) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(NewGeoMapFeatures(g, nodes))
+}
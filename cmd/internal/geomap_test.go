@@ -0,0 +1,111 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func testGeoMap() *GeoMap {
+	var o perfops.DNSTestOutput
+	json.Unmarshal([]byte(`{"id":"a1","finished":true,"items":[
+		{"id":"1","result":{"output":["93.184.216.34"],"node":{"id":5,"as_number":3320,"latitude":50.1,"longitude":8.7,"city":"Frankfurt","country":{"name":"Germany"}}}},
+		{"id":"2","result":{"output":["93.184.216.34"],"node":{"id":12,"as_number":1136,"latitude":52.4,"longitude":4.9,"city":"Amsterdam","country":{"name":"Netherlands"}}}},
+		{"id":"3","result":{"output":["93.184.216.99"],"node":{"id":27,"as_number":9304,"latitude":22.3,"longitude":114.2,"city":"Hong Kong","country":{"name":"Hong Kong"}}}},
+		{"id":"4","result":{"output":"-2","node":{"id":30,"as_number":7922,"city":"Ashburn","country":{"name":"United States"}}}}]}`), &o)
+	return NewGeoMap("example.com", "A", "127.0.0.1", &o)
+}
+
+func TestNewGeoMap(t *testing.T) {
+	g := testGeoMap()
+	if len(g.Clusters) != 2 || len(g.Failed) != 1 {
+		t.Fatalf("expected 2 clusters and 1 failure; got %d, %d", len(g.Clusters), len(g.Failed))
+	}
+	c := g.Clusters[0]
+	if c.Label != "A" || strings.Join(c.Answers, " ") != "93.184.216.34" || len(c.Nodes) != 2 {
+		t.Fatalf("unexpected first cluster %+v", c)
+	}
+	if got, exp := strings.Join(c.Countries, ","), "Germany,Netherlands"; got != exp {
+		t.Fatalf("expected countries %v; got %v", exp, got)
+	}
+	if len(c.ASNs) != 2 || c.ASNs[0] != 1136 || c.ASNs[1] != 3320 {
+		t.Fatalf("expected sorted ASNs; got %v", c.ASNs)
+	}
+	if g.Clusters[1].Label != "B" || g.Failed[0].Node.ID != 30 {
+		t.Fatalf("unexpected second cluster or failure %+v %+v", g.Clusters[1], g.Failed[0])
+	}
+	if got := clusterLabel(26); got != "27" {
+		t.Fatalf("expected numbered labels after Z; got %v", got)
+	}
+}
+
+func TestPrintGeoMap(t *testing.T) {
+	var b bytes.Buffer
+	if err := PrintGeoMap(&b, testGeoMap()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	exp := `example.com A from 4 nodes via 127.0.0.1: 2 answer sets, 1 failed
+
+[A] 93.184.216.34 (2 nodes)
+    Countries: Germany, Netherlands
+    Cities:    Amsterdam, Frankfurt
+    ASNs:      AS1136, AS3320
+
+[B] 93.184.216.99 (1 nodes)
+    Countries: Hong Kong
+    Cities:    Hong Kong
+    ASNs:      AS9304
+
+Failed:
+    Node30, AS7922, Ashburn, United States: The command timed-out. It either took too long to execute or we could not connect to your target at all.
+`
+	if got := b.String(); got != exp {
+		t.Fatalf("expected\n%s\ngot\n%s", exp, got)
+	}
+}
+
+func TestPrintGeoMapMap(t *testing.T) {
+	defer func(c bool) { color = c }(color)
+	color = false
+	var b bytes.Buffer
+	if err := PrintGeoMapMap(&b, testGeoMap(), nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	out := b.String()
+	if strings.Count(out, "A") != 2 || !strings.Contains(out, "B") {
+		t.Fatalf("expected the nodes labeled by cluster\n%s", out)
+	}
+	if !strings.Contains(out, "A 93.184.216.34  B 93.184.216.99  ✕ failed\n1 nodes without a known location are not shown") {
+		t.Fatalf("unexpected legend\n%s", out)
+	}
+}
+
+func TestNewGeoMapFeatures(t *testing.T) {
+	fc := NewGeoMapFeatures(testGeoMap(), nil)
+	if len(fc.Features) != 4 {
+		t.Fatalf("expected 4 features; got %d", len(fc.Features))
+	}
+	f := fc.Features[0]
+	if f.Properties.Cluster != "A" || f.Properties.NodeID != 5 || f.Geometry == nil || f.Geometry.Coordinates != [2]float64{8.7, 50.1} {
+		t.Fatalf("unexpected feature %+v %+v", f.Properties, f.Geometry)
+	}
+	if f := fc.Features[3]; f.Properties.Status != perfops.StatusError || f.Geometry != nil {
+		t.Fatalf("expected a failed node without location; got %+v", f)
+	}
+}
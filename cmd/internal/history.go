@@ -0,0 +1,137 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+// HistoryEntry represents a test recorded in the local history.
+type HistoryEntry struct {
+	ID     perfops.TestID `json:"id"`
+	Type   string         `json:"type"`
+	Target string         `json:"target"`
+	Time   time.Time      `json:"time"`
+	// Credits is the number of credits the test used, if known.
+	Credits int `json:"credits,omitempty"`
+	// Nodes is the number of nodes the test ran on, if its credits are
+	// known.
+	Nodes int `json:"nodes,omitempty"`
+}
+
+// AppendHistory appends an entry to the history file at path.
+func AppendHistory(path string, e *HistoryEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(e)
+}
+
+// ReadHistory returns the entries of the history file at path, oldest
+// first. A missing history file is not an error. Malformed lines are
+// skipped.
+func ReadHistory(path string) ([]*HistoryEntry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []*HistoryEntry
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		var e *HistoryEntry
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil || e == nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, s.Err()
+}
+
+// FindHistory returns the most recent entry for a test ID or nil.
+func FindHistory(entries []*HistoryEntry, id perfops.TestID) *HistoryEntry {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].ID == id {
+			return entries[i]
+		}
+	}
+	return nil
+}
+
+// SetHistoryCredits records the credits a test used and the number of
+// nodes it ran on in the most recent entry of the test in the history
+// file at path.
+func SetHistoryCredits(path string, id perfops.TestID, credits, nodes int) error {
+	entries, err := ReadHistory(path)
+	if err != nil {
+		return err
+	}
+	e := FindHistory(entries, id)
+	if e == nil {
+		return nil
+	}
+	e.Credits, e.Nodes = credits, nodes
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// CreditsUsedOn returns the credits used by the tests of the day of t in
+// the local time zone.
+func CreditsUsedOn(entries []*HistoryEntry, t time.Time) int {
+	y, m, d := t.Local().Date()
+	used := 0
+	for _, e := range entries {
+		if ey, em, ed := e.Time.Local().Date(); ey == y && em == m && ed == d {
+			used += e.Credits
+		}
+	}
+	return used
+}
+
+// CreditCosts returns the credits per node of the most recent test of
+// each type in the history that used credits, rounded up.
+func CreditCosts(entries []*HistoryEntry) map[string]int {
+	costs := map[string]int{}
+	for _, e := range entries {
+		if e.Credits > 0 && e.Nodes > 0 {
+			costs[e.Type] = (e.Credits + e.Nodes - 1) / e.Nodes
+		}
+	}
+	return costs
+}
@@ -0,0 +1,93 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "perfops", "history.jsonl")
+	entries, err := ReadHistory(p)
+	if err != nil || entries != nil {
+		t.Fatalf("expected no entries and no error; got %v, %v", entries, err)
+	}
+
+	now := time.Now()
+	for _, e := range []*HistoryEntry{
+		{ID: "a", Type: "ping", Target: "example.com", Time: now},
+		{ID: "b", Type: "mtr", Target: "example.com", Time: now},
+		{ID: "a", Type: "curl", Target: "example.org", Time: now},
+	} {
+		if err := AppendHistory(p, e); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	b, _ := ioutil.ReadFile(p)
+	ioutil.WriteFile(p, append(b, []byte("not json\n")...), 0644)
+
+	entries, err = ReadHistory(p)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got, exp := len(entries), 3; got != exp {
+		t.Fatalf("expected %v entries; got %v", exp, got)
+	}
+	if got := FindHistory(entries, "a"); got == nil || got.Type != "curl" {
+		t.Fatalf("expected most recent entry; got %v", got)
+	}
+	if got := FindHistory(entries, "c"); got != nil {
+		t.Fatalf("expected nil; got %v", got)
+	}
+}
+
+func TestHistoryCredits(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "perfops", "history.jsonl")
+	now := time.Now()
+	for _, e := range []*HistoryEntry{
+		{ID: "a", Type: "ping", Target: "example.com", Time: now.AddDate(0, 0, -1), Credits: 7},
+		{ID: "b", Type: "mtr", Target: "example.com", Time: now, Credits: 2},
+		{ID: "d", Type: "curl", Target: "example.org", Time: now.AddDate(0, 0, -1), Credits: 2, Nodes: 2},
+		{ID: "c", Type: "curl", Target: "example.org", Time: now},
+	} {
+		if err := AppendHistory(p, e); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	if err := SetHistoryCredits(p, "c", 7, 3); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := SetHistoryCredits(p, "unknown", 3, 1); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	entries, err := ReadHistory(p)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(entries) != 4 || entries[3].ID != "c" || entries[3].Credits != 7 || entries[3].Nodes != 3 {
+		t.Fatalf("expected credits of c to be recorded; got %+v", entries)
+	}
+	if got, exp := CreditsUsedOn(entries, now), 9; got != exp {
+		t.Fatalf("expected %d; got %d", exp, got)
+	}
+	// The most recent curl test cost 7 credits on 3 nodes; the mtr test
+	// did not record its nodes.
+	if got, exp := CreditCosts(entries), map[string]int{"curl": 3}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("expected %v; got %v", exp, got)
+	}
+}
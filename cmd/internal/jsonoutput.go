@@ -0,0 +1,152 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+type (
+	// RunJSON represents the JSON output of an MTR, ping, traceroute,
+	// latency or curl test.
+	RunJSON struct {
+		*perfops.RunOutput
+		// Nodes lists the IDs of the nodes the test ran on.
+		Nodes      []int       `json:"nodes,omitempty"`
+		Summary    *Summary    `json:"summary,omitempty"`
+		Aggregates *Aggregates `json:"aggregates,omitempty"`
+		// CreditsUsed is the number of credits the test used, if known.
+		CreditsUsed *int `json:"credits_used,omitempty"`
+	}
+
+	// DNSTestJSON represents the JSON output of a DNS perf or DNS resolve
+	// test.
+	DNSTestJSON struct {
+		*perfops.DNSTestOutput
+		// Nodes lists the IDs of the nodes the test ran on.
+		Nodes      []int       `json:"nodes,omitempty"`
+		Summary    *Summary    `json:"summary,omitempty"`
+		Aggregates *Aggregates `json:"aggregates,omitempty"`
+		// CreditsUsed is the number of credits the test used, if known.
+		CreditsUsed *int `json:"credits_used,omitempty"`
+		// QueryType and Records are set for DNS resolve tests.
+		QueryType string         `json:"query_type,omitempty"`
+		Records   []*NodeRecords `json:"records,omitempty"`
+	}
+
+	// TestJSON is the JSON output of a test the aggregates of its results
+	// and the credits it used are added to.
+	TestJSON interface {
+		setTotals(aggregates *Aggregates, creditsUsed *int)
+	}
+
+	// NodeRecords represents the typed records a node resolved.
+	NodeRecords struct {
+		ID        string               `json:"id"`
+		Node      int                  `json:"node"`
+		DNSServer string               `json:"dns_server,omitempty"`
+		Records   []*perfops.DNSRecord `json:"records"`
+		// Error is set if a record could not be parsed.
+		Error string `json:"error,omitempty"`
+	}
+)
+
+// NewRunJSON returns the JSON output of a test.
+func NewRunJSON(testType string, o *perfops.RunOutput) *RunJSON {
+	return &RunJSON{
+		RunOutput: o,
+		Nodes:     o.NodeIDs(),
+		Summary:   Summarize(RunResults(testType, o), PrimaryMetric(testType)),
+	}
+}
+
+func (j *RunJSON) setTotals(aggregates *Aggregates, creditsUsed *int) {
+	j.Aggregates, j.CreditsUsed = aggregates, creditsUsed
+}
+
+// NewDNSTestJSON returns the JSON output of a DNS test.
+func NewDNSTestJSON(testType string, o *perfops.DNSTestOutput) *DNSTestJSON {
+	return &DNSTestJSON{
+		DNSTestOutput: o,
+		Nodes:         o.NodeIDs(),
+		Summary:       Summarize(DNSResults(testType, o), PrimaryMetric(testType)),
+	}
+}
+
+func (j *DNSTestJSON) setTotals(aggregates *Aggregates, creditsUsed *int) {
+	j.Aggregates, j.CreditsUsed = aggregates, creditsUsed
+}
+
+// NewResolveJSON returns the JSON output of a DNS resolve test with the
+// typed records of each node. The record types are inferred from the
+// answers if queryType is empty.
+func NewResolveJSON(queryType string, o *perfops.DNSTestOutput) *DNSTestJSON {
+	j := NewDNSTestJSON("resolve", o)
+	j.QueryType = queryType
+	for _, item := range o.Items {
+		r := item.Result
+		if r == nil {
+			continue
+		}
+		if status, _ := r.Status(); status != perfops.StatusOK {
+			continue
+		}
+		nr := &NodeRecords{ID: item.ID, DNSServer: r.DNSServer, Records: []*perfops.DNSRecord{}}
+		if r.Node != nil {
+			nr.Node = r.Node.ID
+		}
+		records, err := r.ResolveRecords(queryType)
+		if err != nil {
+			nr.Error = err.Error()
+		}
+		if records != nil {
+			nr.Records = records
+		}
+		j.Records = append(j.Records, nr)
+	}
+	return j
+}
+
+// NodeIDsFromJSON returns the IDs of the nodes a test ran on given its
+// JSON output. Both the JSON output of perfops and the raw output of the
+// API are accepted.
+func NodeIDsFromJSON(data []byte) ([]int, error) {
+	var v struct {
+		perfops.RunOutput
+		Nodes json.RawMessage `json:"nodes"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	var ids []int
+	if len(v.Nodes) > 0 {
+		if err := json.Unmarshal(v.Nodes, &ids); err != nil {
+			var nodeIDs perfops.NodeIDs
+			if err := json.Unmarshal(v.Nodes, &nodeIDs); err != nil {
+				return nil, err
+			}
+			ids = nodeIDs
+		}
+	}
+	if len(ids) == 0 {
+		ids = v.RunOutput.NodeIDs()
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("no nodes found in test output")
+	}
+	return ids, nil
+}
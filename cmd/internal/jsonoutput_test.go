@@ -0,0 +1,94 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func TestNewRunJSON(t *testing.T) {
+	o := &perfops.RunOutput{ID: "abc", Finished: true, Items: []*perfops.RunItem{
+		{ID: "1", Result: &perfops.RunResult{Node: &perfops.Node{ID: 27}}},
+		{ID: "2", Result: &perfops.RunResult{Node: &perfops.Node{ID: 5}}},
+		{ID: "3", Result: &perfops.RunResult{}},
+	}}
+	b, err := json.Marshal(NewRunJSON("ping", o))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got := string(b); !strings.HasPrefix(got, `{"id":"abc","finished":true,`) || !strings.Contains(got, `,"nodes":[5,27],"summary":{"nodes":3,`) {
+		t.Fatalf("unexpected JSON output %s", got)
+	}
+}
+
+func TestNewResolveJSON(t *testing.T) {
+	var o perfops.DNSTestOutput
+	json.Unmarshal([]byte(`{"id":"abc","finished":true,"items":[
+		{"id":"1","result":{"dnsServer":"8.8.8.8","output":["10 mx1.example.com.","20 mx2.example.com."],"node":{"id":5}}},
+		{"id":"2","result":{"dnsServer":"8.8.8.8","output":"broken","node":{"id":12}}},
+		{"id":"3","result":{"message":"NO DATA","node":{"id":27}}}]}`), &o)
+	j := NewResolveJSON("MX", &o)
+	if len(j.Records) != 2 {
+		t.Fatalf("expected records of 2 nodes; got %d", len(j.Records))
+	}
+	if r := j.Records[0]; r.Node != 5 || len(r.Records) != 2 || r.Records[1].MX.Exchange != "mx2.example.com." || r.Error != "" {
+		t.Fatalf("unexpected records %+v", r)
+	}
+	if r := j.Records[1]; r.Node != 12 || len(r.Records) != 0 || r.Error == "" {
+		t.Fatalf("expected an error; got %+v", r)
+	}
+	b, err := json.Marshal(j)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got := string(b); !strings.Contains(got, `"query_type":"MX","records":[{"id":"1","node":5,"dns_server":"8.8.8.8","records":[{"type":"MX","data":"10 mx1.example.com.","mx":{"preference":10,"exchange":"mx1.example.com."}}`) {
+		t.Fatalf("unexpected JSON output %s", got)
+	}
+}
+
+func TestNodeIDsFromJSON(t *testing.T) {
+	testCases := map[string]struct {
+		data   string
+		exp    []int
+		expErr string
+	}{
+		"Output":     {`{"id":"abc","items":[{"result":{"node":{"id":27}}}],"nodes":[5,27]}`, []int{5, 27}, ""},
+		"Node list":  {`{"nodes":"12,3"}`, []int{12, 3}, ""},
+		"API output": {`{"id":"abc","items":[{"result":{"node":{"id":27}}},{"result":{"node":{"id":5}}}]}`, []int{5, 27}, ""},
+		"DNS output": {`{"id":"abc","items":[{"result":{"dnsServer":"8.8.8.8","node":{"id":7},"output":["1.2.3.4"]}}]}`, []int{7}, ""},
+		"No nodes":   {`{"id":"abc"}`, nil, "no nodes found in test output"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := NodeIDsFromJSON([]byte(tc.data))
+			if tc.expErr != "" {
+				if err == nil || err.Error() != tc.expErr {
+					t.Fatalf("expected error %v; got %v", tc.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.exp) {
+				t.Fatalf("expected %v; got %v", tc.exp, got)
+			}
+		})
+	}
+}
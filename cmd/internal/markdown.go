@@ -0,0 +1,81 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+// WriteMarkdown writes the results as a GitHub-flavored Markdown table
+// to w, followed by collapsible blocks with the raw output of each node.
+func WriteMarkdown(w io.Writer, title string, results []*Result) error {
+	metrics := ResultMetrics(results)
+	var b strings.Builder
+	if title != "" {
+		fmt.Fprintf(&b, "### %s\n\n", mdEscape(title))
+	}
+	b.WriteString("| Node | Location | ASN |")
+	for _, m := range metrics {
+		fmt.Fprintf(&b, " %s |", metricLabels[m])
+	}
+	b.WriteString(" Status |\n|---:|---|---:|")
+	for range metrics {
+		b.WriteString("---:|")
+	}
+	b.WriteString("---|\n")
+	for _, r := range results {
+		var id, location, asn string
+		if n := r.Node; n != nil {
+			id, asn = strconv.Itoa(n.ID), "AS"+strconv.Itoa(n.AsNumber)
+			location = strings.Trim(n.City+", "+n.CountryName(), ", ")
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |", id, mdEscape(location), asn)
+		for _, m := range metrics {
+			v := ""
+			if x, ok := r.Metric(m); ok {
+				v = fmtValue(x)
+			}
+			fmt.Fprintf(&b, " %s |", v)
+		}
+		status := string(r.Status)
+		if r.Reason != "" {
+			status += ": " + r.Reason
+		}
+		fmt.Fprintf(&b, " %s |\n", mdEscape(status))
+	}
+	for _, r := range results {
+		if r.Text == "" || r.Status != perfops.StatusOK {
+			continue
+		}
+		fence := "```"
+		if strings.Contains(r.Text, fence) {
+			fence = "~~~~"
+		}
+		fmt.Fprintf(&b, "\n<details>\n<summary>%s</summary>\n\n%s\n%s\n%s\n\n</details>\n",
+			html.EscapeString(nodeLocation(r.Node)), fence, strings.TrimRight(r.Text, "\n"), fence)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mdEscape escapes the characters of s breaking a Markdown table cell.
+func mdEscape(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
@@ -0,0 +1,45 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	results := testResults()[2:4]
+	results[0].Text = "1  10.0.0.1  0.5 ms\n2  example.com  180 ms\n"
+	results[1].Reason = "timed out"
+	var b bytes.Buffer
+	if err := WriteMarkdown(&b, "traceroute abc123", results); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	exp := "### traceroute abc123\n\n" +
+		"| Node | Location | ASN | RTT (ms) | Status |\n" +
+		"|---:|---|---:|---:|---|\n" +
+		"| 27 | Hong Kong, Hong Kong | AS9304 | 180.00 | ok |\n" +
+		"| 28 | Hong Kong, Hong Kong | AS9304 |  | timeout: timed out |\n" +
+		"\n<details>\n<summary>Node27, AS9304, Hong Kong, Hong Kong</summary>\n\n" +
+		"```\n1  10.0.0.1  0.5 ms\n2  example.com  180 ms\n```\n\n</details>\n"
+	if got := b.String(); got != exp {
+		t.Fatalf("expected\n%s\ngot\n%s", exp, got)
+	}
+}
+
+func TestMDEscape(t *testing.T) {
+	if got, exp := mdEscape("a|b\nc"), `a\|b c`; got != exp {
+		t.Fatalf("expected %q; got %q", exp, got)
+	}
+}
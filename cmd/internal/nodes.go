@@ -0,0 +1,77 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+var nodeColumns = []string{"ID", "CITY", "SUB-REGION", "COUNTRY", "CONTINENT", "ASN", "LATITUDE", "LONGITUDE", "IPV6"}
+
+// PrintNodesTable writes the nodes as an aligned table to w.
+func PrintNodesTable(w io.Writer, nodes []*perfops.Node) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for i, c := range nodeColumns {
+		if i > 0 {
+			fmt.Fprint(tw, "\t")
+		}
+		fmt.Fprint(tw, c)
+	}
+	fmt.Fprintln(tw)
+	for _, n := range nodes {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%d\t%.4f\t%.4f\t%s\n",
+			n.ID, n.City, n.SubRegion, n.CountryName(), n.ContinentName(), n.AsNumber,
+			n.Latitude, n.Longitude, yesNo(n.IPv6))
+	}
+	return tw.Flush()
+}
+
+// PrintNodesCSV writes the nodes as CSV including a header row to w.
+func PrintNodesCSV(w io.Writer, nodes []*perfops.Node) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(nodeColumns); err != nil {
+		return err
+	}
+	for _, n := range nodes {
+		rec := []string{
+			strconv.Itoa(n.ID),
+			n.City,
+			n.SubRegion,
+			n.CountryName(),
+			n.ContinentName(),
+			strconv.Itoa(n.AsNumber),
+			strconv.FormatFloat(n.Latitude, 'f', -1, 64),
+			strconv.FormatFloat(n.Longitude, 'f', -1, 64),
+			strconv.FormatBool(n.IPv6),
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
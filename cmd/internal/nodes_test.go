@@ -0,0 +1,55 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func testNodes() []*perfops.Node {
+	return []*perfops.Node{
+		{ID: 5, AsNumber: 3320, Latitude: 50.1108, Longitude: 8.6898, City: "Frankfurt", SubRegion: "Western Europe", IPv6: true,
+			Country: &perfops.Country{Name: "Germany", ISO: "DE", Continent: &perfops.Continent{Name: "Europe", ISO: "EU"}}},
+		{ID: 27, AsNumber: 9304, Latitude: 22.2851, Longitude: 114.1751, City: "Hong Kong", SubRegion: "Eastern Asia"},
+	}
+}
+
+func TestPrintNodesTable(t *testing.T) {
+	var b bytes.Buffer
+	if err := PrintNodesTable(&b, testNodes()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	exp := "ID  CITY       SUB-REGION      COUNTRY  CONTINENT  ASN   LATITUDE  LONGITUDE  IPV6\n" +
+		"5   Frankfurt  Western Europe  Germany  Europe     3320  50.1108   8.6898     yes\n" +
+		"27  Hong Kong  Eastern Asia                        9304  22.2851   114.1751   no\n"
+	if got := b.String(); got != exp {
+		t.Fatalf("expected %q; got %q", exp, got)
+	}
+}
+
+func TestPrintNodesCSV(t *testing.T) {
+	var b bytes.Buffer
+	if err := PrintNodesCSV(&b, testNodes()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	exp := "ID,CITY,SUB-REGION,COUNTRY,CONTINENT,ASN,LATITUDE,LONGITUDE,IPV6\n" +
+		"5,Frankfurt,Western Europe,Germany,Europe,3320,50.1108,8.6898,true\n" +
+		"27,Hong Kong,Eastern Asia,,,9304,22.2851,114.1751,false\n"
+	if got := b.String(); got != exp {
+		t.Fatalf("expected %q; got %q", exp, got)
+	}
+}
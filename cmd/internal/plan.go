@@ -0,0 +1,136 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+// creditCosts are the default credits a test costs per node by test type.
+// The API publishes no costs per test type, but reports the credits each
+// test withdrew in the creditsWithdrawn field of its output. These are
+// recorded in the history and used to estimate the following tests of the
+// type, see CreditCosts, which the credit_costs of the config file
+// override.
+var creditCosts = map[string]int{
+	"curl":       1,
+	"dnsperf":    1,
+	"latency":    1,
+	"mtr":        1,
+	"ping":       1,
+	"resolve":    1,
+	"traceroute": 1,
+}
+
+// Plan represents a test that would be run, e.g., by --dry-run.
+type Plan struct {
+	Type     string `json:"type"`
+	Target   string `json:"target"`
+	Location string `json:"location,omitempty"`
+	NodeIDs  []int  `json:"node_ids,omitempty"`
+	// Nodes is the maximum number of nodes the test runs on.
+	Nodes int `json:"nodes"`
+	// Tests is the number of tests run on the nodes, e.g., one per DNS
+	// server.
+	Tests          int `json:"tests,omitempty"`
+	CreditsPerNode int `json:"credits_per_node"`
+	Credits        int `json:"credits"`
+	// RemainingCredits is nil if the remaining credits are unknown.
+	RemainingCredits *int `json:"remaining_credits,omitempty"`
+}
+
+// NewPlan returns the plan of a test of testType from the location, node
+// IDs and limit of the request, and estimates its credits.
+func NewPlan(testType, target, location string, quotas perfops.LocationQuotas, nodeIDs []int, limit int) *Plan {
+	p := &Plan{Type: testType, Target: target, Location: location, NodeIDs: nodeIDs}
+	switch {
+	case len(nodeIDs) > 0:
+		p.Nodes = len(nodeIDs)
+	case len(quotas) > 0:
+		p.Location = quotas.String()
+		p.Nodes = quotas.Total(limit)
+	default:
+		p.Nodes = limit
+	}
+	p.CreditsPerNode = CreditsPerNode(testType)
+	p.Credits = p.Nodes * p.CreditsPerNode
+	return p
+}
+
+// Repeat sets the number of tests run on the nodes of the plan and
+// estimates their credits.
+func (p *Plan) Repeat(tests int) *Plan {
+	p.Tests = tests
+	p.Credits = p.Nodes * p.CreditsPerNode * max(1, tests)
+	return p
+}
+
+// SetCreditsPerNode sets the credits a test costs per node and estimates
+// the credits of the plan again.
+func (p *Plan) SetCreditsPerNode(credits int) {
+	p.CreditsPerNode = credits
+	p.Credits = p.Nodes * credits * max(1, p.Tests)
+}
+
+// CreditsPerNode returns the credits a test of testType costs per node.
+func CreditsPerNode(testType string) int {
+	if c, ok := creditCosts[testType]; ok {
+		return c
+	}
+	return 1
+}
+
+// PrintPlan prints the plan. The node IDs are resolved to their locations
+// if they are known nodes.
+func PrintPlan(w io.Writer, p *Plan, nodes []*perfops.Node) error {
+	location := p.Location
+	if location == "" {
+		location = "any"
+	}
+	remaining := "unknown"
+	if p.RemainingCredits != nil {
+		remaining = fmt.Sprint(*p.RemainingCredits)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Dry run: %s %s\n", p.Type, p.Target)
+	if len(p.NodeIDs) == 0 {
+		fmt.Fprintf(&b, "Location:          %s\n", location)
+		fmt.Fprintf(&b, "Nodes:             up to %d\n", p.Nodes)
+	} else {
+		fmt.Fprintf(&b, "Nodes:             %d\n", p.Nodes)
+		known := map[int]*perfops.Node{}
+		for _, n := range nodes {
+			known[n.ID] = n
+		}
+		for _, id := range p.NodeIDs {
+			if n, ok := known[id]; ok {
+				fmt.Fprintf(&b, "  %s\n", nodeLocation(n))
+			} else {
+				fmt.Fprintf(&b, "  Node%d\n", id)
+			}
+		}
+	}
+	if p.Tests > 1 {
+		fmt.Fprintf(&b, "Estimated credits: %d (%d per node, %d tests)\n", p.Credits, p.CreditsPerNode, p.Tests)
+	} else {
+		fmt.Fprintf(&b, "Estimated credits: %d (%d per node)\n", p.Credits, p.CreditsPerNode)
+	}
+	fmt.Fprintf(&b, "Remaining credits: %s\n", remaining)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,76 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func TestNewPlan(t *testing.T) {
+	testCases := map[string]struct {
+		location string
+		quotas   perfops.LocationQuotas
+		nodeIDs  []int
+		limit    int
+		expLoc   string
+		expNodes int
+	}{
+		"Limit":    {"Europe", nil, nil, 7, "Europe", 7},
+		"Quotas":   {"", perfops.LocationQuotas{{Location: "Europe", Limit: 5}, {Location: "Asia"}}, nil, 3, "Europe:5,Asia", 8},
+		"Node IDs": {"", nil, []int{5, 12}, 20, "", 2},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			p := NewPlan("ping", "example.com", tc.location, tc.quotas, tc.nodeIDs, tc.limit)
+			if p.Location != tc.expLoc || p.Nodes != tc.expNodes || p.Credits != tc.expNodes*p.CreditsPerNode {
+				t.Fatalf("expected %s, %d nodes; got %+v", tc.expLoc, tc.expNodes, p)
+			}
+		})
+	}
+}
+
+func TestPlanRepeat(t *testing.T) {
+	p := NewPlan("resolve", "example.com", "", nil, []int{5, 12}, 2).Repeat(3)
+	if p.Tests != 3 || p.Credits != 6 {
+		t.Fatalf("expected 3 tests and 6 credits; got %+v", p)
+	}
+	var b bytes.Buffer
+	PrintPlan(&b, p, nil)
+	if !bytes.Contains(b.Bytes(), []byte("Estimated credits: 6 (1 per node, 3 tests)\n")) {
+		t.Fatalf("unexpected plan\n%s", b.String())
+	}
+}
+
+func TestPrintPlan(t *testing.T) {
+	remaining := 1
+	p := NewPlan("mtr", "example.com", "", nil, []int{5, 99}, 2)
+	p.RemainingCredits = &remaining
+	var b bytes.Buffer
+	if err := PrintPlan(&b, p, []*perfops.Node{testResults()[0].Node}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	exp := `Dry run: mtr example.com
+Nodes:             2
+  Node5, AS3320, Frankfurt, Germany
+  Node99
+Estimated credits: 2 (1 per node)
+Remaining credits: 1
+`
+	if got := b.String(); got != exp {
+		t.Fatalf("expected\n%s\ngot\n%s", exp, got)
+	}
+}
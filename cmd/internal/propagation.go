@@ -0,0 +1,292 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+// PropagationStatus represents whether a node sees the expected value of
+// a DNS record.
+type PropagationStatus string
+
+const (
+	// PropagationNew is the status of nodes seeing the expected value.
+	PropagationNew PropagationStatus = "new"
+	// PropagationOld is the status of nodes seeing another value.
+	PropagationOld PropagationStatus = "old"
+	// PropagationFailed is the status of nodes failing to resolve the
+	// record.
+	PropagationFailed PropagationStatus = "failed"
+)
+
+// propagationOrder orders the results by status, nodes still seeing the
+// old value first.
+var propagationOrder = map[PropagationStatus]int{
+	PropagationOld:    0,
+	PropagationFailed: 1,
+	PropagationNew:    2,
+}
+
+type (
+	// PropagationResult represents the answer a node got from a DNS
+	// server.
+	PropagationResult struct {
+		Node      *perfops.Node     `json:"node"`
+		DNSServer string            `json:"dns_server"`
+		Status    PropagationStatus `json:"status"`
+		Answers   []string          `json:"answers,omitempty"`
+		// TTL is the lowest TTL of the answers in seconds, nil if the
+		// DNS server did not report it.
+		TTL    *int   `json:"ttl,omitempty"`
+		Reason string `json:"reason,omitempty"`
+	}
+
+	// PropagationServer summarizes the results of a DNS server.
+	PropagationServer struct {
+		DNSServer string `json:"dns_server"`
+		TestID    string `json:"test_id"`
+		New       int    `json:"new"`
+		Old       int    `json:"old"`
+		Failed    int    `json:"failed"`
+		// OldTTL is the highest TTL of the old answers in seconds, i.e.,
+		// the time until every node should see the new value, nil if
+		// unknown.
+		OldTTL *int `json:"old_ttl,omitempty"`
+	}
+
+	// PropagationAnswers represents an old answer set and the number of
+	// results with it.
+	PropagationAnswers struct {
+		Answers []string `json:"answers"`
+		Results int      `json:"results"`
+	}
+
+	// Propagation represents the propagation of a DNS record to the
+	// nodes and DNS servers.
+	Propagation struct {
+		Name       string                `json:"name"`
+		Type       string                `json:"type"`
+		Expect     []string              `json:"expect"`
+		Servers    []*PropagationServer  `json:"servers"`
+		OldAnswers []*PropagationAnswers `json:"old_answers,omitempty"`
+		Results    []*PropagationResult  `json:"results"`
+		// CreditsUsed is nil if the credits used are unknown.
+		CreditsUsed *int `json:"credits_used,omitempty"`
+	}
+)
+
+// NewPropagation returns the propagation of the expected values of a DNS
+// record from the outputs of the DNS resolve tests against each DNS
+// server. A node sees the new value if its answers contain all expected
+// values. Expected MX and SRV values without preference or priority match
+// the exchange or target of an answer only.
+func NewPropagation(name, queryType string, expect, dnsServers []string, outputs []*perfops.DNSTestOutput) *Propagation {
+	p := &Propagation{Name: name, Type: queryType, Expect: expect}
+	// expected holds whether a value is a host name only.
+	expected := map[string]bool{}
+	for _, v := range expect {
+		value, hostOnly := expectedValue(queryType, v)
+		expected[value] = hostOnly
+	}
+	oldAnswers := map[string]*PropagationAnswers{}
+	for i, o := range outputs {
+		s := &PropagationServer{DNSServer: dnsServers[i], TestID: o.ID}
+		p.Servers = append(p.Servers, s)
+		for _, item := range o.Items {
+			r := item.Result
+			if r == nil {
+				continue
+			}
+			res := newPropagationResult(queryType, s.DNSServer, r, expected)
+			switch res.Status {
+			case PropagationNew:
+				s.New++
+			case PropagationFailed:
+				s.Failed++
+			case PropagationOld:
+				s.Old++
+				if res.TTL != nil && (s.OldTTL == nil || *res.TTL > *s.OldTTL) {
+					s.OldTTL = res.TTL
+				}
+				key := strings.Join(res.Answers, " ")
+				if oldAnswers[key] == nil {
+					oldAnswers[key] = &PropagationAnswers{Answers: res.Answers}
+					p.OldAnswers = append(p.OldAnswers, oldAnswers[key])
+				}
+				oldAnswers[key].Results++
+			}
+			p.Results = append(p.Results, res)
+		}
+	}
+	servers := map[string]int{}
+	for i, s := range dnsServers {
+		servers[s] = i
+	}
+	sort.SliceStable(p.Results, func(i, j int) bool {
+		a, b := p.Results[i], p.Results[j]
+		if a.DNSServer != b.DNSServer {
+			return servers[a.DNSServer] < servers[b.DNSServer]
+		}
+		if a.Status != b.Status {
+			return propagationOrder[a.Status] < propagationOrder[b.Status]
+		}
+		return nodeLess(a.Node, b.Node)
+	})
+	sort.SliceStable(p.OldAnswers, func(i, j int) bool { return p.OldAnswers[i].Results > p.OldAnswers[j].Results })
+	return p
+}
+
+func newPropagationResult(queryType, dnsServer string, r *perfops.DNSTestResult, expected map[string]bool) *PropagationResult {
+	res := &PropagationResult{Node: r.Node, DNSServer: dnsServer, Status: PropagationFailed}
+	status, reason := r.Status()
+	if status != perfops.StatusOK {
+		res.Reason = reason
+		if res.Reason == "" {
+			res.Reason = "no answer"
+		}
+		return res
+	}
+	records, _ := r.ResolveRecords(queryType)
+	seen, hosts := map[string]bool{}, map[string]bool{}
+	for _, rec := range records {
+		if rec.TTL != nil && (res.TTL == nil || *rec.TTL < *res.TTL) {
+			res.TTL = rec.TTL
+		}
+		if v := rec.Value(); !seen[v] {
+			seen[v] = true
+			res.Answers = append(res.Answers, v)
+		}
+		if h := recordHost(rec); h != "" {
+			hosts[h] = true
+		}
+	}
+	sort.Strings(res.Answers)
+	res.Status = PropagationNew
+	for v, hostOnly := range expected {
+		if hostOnly && !hosts[v] || !hostOnly && !seen[v] {
+			res.Status = PropagationOld
+			break
+		}
+	}
+	return res
+}
+
+// answerValue returns the canonical value of an answer to compare it,
+// e.g., without the trailing dot of a domain name.
+func answerValue(queryType, s string) string {
+	if rec, err := perfops.ParseDNSRecord(queryType, s); err == nil {
+		return rec.Value()
+	}
+	return strings.TrimSpace(s)
+}
+
+// expectedValue returns the canonical value of an expected answer and
+// whether it is the host name of an MX or SRV record only, e.g.,
+// "mail.example.com" rather than "10 mail.example.com".
+func expectedValue(queryType, s string) (string, bool) {
+	t := strings.ToUpper(strings.TrimSpace(queryType))
+	if f := strings.Fields(s); (t == "MX" || t == "SRV") && len(f) == 1 {
+		return hostName(f[0]), true
+	}
+	return answerValue(queryType, s), false
+}
+
+// recordHost returns the exchange or target of an MX or SRV record.
+func recordHost(rec *perfops.DNSRecord) string {
+	switch {
+	case rec.MX != nil:
+		return hostName(rec.MX.Exchange)
+	case rec.SRV != nil:
+		return hostName(rec.SRV.Target)
+	}
+	return ""
+}
+
+func hostName(s string) string {
+	return strings.TrimSuffix(strings.ToLower(s), ".")
+}
+
+// nodeLess orders nodes by country, city and ID.
+func nodeLess(a, b *perfops.Node) bool {
+	if a == nil || b == nil {
+		return a != nil
+	}
+	if a.CountryName() != b.CountryName() {
+		return a.CountryName() < b.CountryName()
+	}
+	if a.City != b.City {
+		return a.City < b.City
+	}
+	return a.ID < b.ID
+}
+
+// PrintPropagation prints the propagation by DNS server, the old answers
+// still seen and the answer of each node.
+func PrintPropagation(w io.Writer, p *Propagation) error {
+	var total, seeNew, seeOld, failed int
+	for _, s := range p.Servers {
+		total += s.New + s.Old + s.Failed
+		seeNew += s.New
+		seeOld += s.Old
+		failed += s.Failed
+	}
+	fmt.Fprintf(w, "%s %s: %d of %d see %s, %d still see an old value, %d failed\n\n",
+		p.Name, p.Type, seeNew, total, strings.Join(p.Expect, " "), seeOld, failed)
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "DNS SERVER\tNEW\tOLD\tFAILED\tOLD TTL")
+	for _, s := range p.Servers {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\n", s.DNSServer, s.New, s.Old, s.Failed, fmtTTL(s.OldTTL))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(p.OldAnswers) > 0 {
+		fmt.Fprintln(w, "\nOld answers:")
+		for _, a := range p.OldAnswers {
+			answers := strings.Join(a.Answers, " ")
+			if answers == "" {
+				answers = "(empty)"
+			}
+			fmt.Fprintf(w, "  %s (%d)\n", answers, a.Results)
+		}
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tDNS SERVER\tNODE\tANSWERS\tTTL")
+	for _, r := range p.Results {
+		answers := strings.Join(r.Answers, " ")
+		if r.Status == PropagationFailed {
+			answers = r.Reason
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Status, r.DNSServer, nodeLocation(r.Node), truncate(answers, 60), fmtTTL(r.TTL))
+	}
+	return tw.Flush()
+}
+
+func fmtTTL(ttl *int) string {
+	if ttl == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%ds", *ttl)
+}
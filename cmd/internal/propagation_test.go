@@ -0,0 +1,100 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func TestPropagation(t *testing.T) {
+	var local, google perfops.DNSTestOutput
+	json.Unmarshal([]byte(`{"id":"a1","finished":true,"items":[
+		{"id":"1","result":{"output":["example.com. 300 IN A 1.2.3.4"],"node":{"id":5,"city":"Frankfurt","country":{"name":"Germany"}}}},
+		{"id":"2","result":{"output":"example.com. 3600 IN A 5.6.7.8\nexample.com. 1200 IN A 5.6.7.9","node":{"id":12,"city":"Amsterdam","country":{"name":"Netherlands"}}}},
+		{"id":"3","result":{"output":"-2","node":{"id":27,"city":"Hong Kong","country":{"name":"Hong Kong"}}}}]}`), &local)
+	json.Unmarshal([]byte(`{"id":"b2","finished":true,"items":[
+		{"id":"4","result":{"output":["1.2.3.4"],"node":{"id":5,"city":"Frankfurt","country":{"name":"Germany"}}}},
+		{"id":"5","result":{"output":["5.6.7.9","5.6.7.8"],"node":{"id":12,"city":"Amsterdam","country":{"name":"Netherlands"}}}}]}`), &google)
+
+	p := NewPropagation("example.com", "A", []string{"1.2.3.4"}, []string{"127.0.0.1", "8.8.8.8"}, []*perfops.DNSTestOutput{&local, &google})
+	if got, exp := len(p.Servers), 2; got != exp {
+		t.Fatalf("expected %d servers; got %d", exp, got)
+	}
+	s := p.Servers[0]
+	if s.TestID != "a1" || s.New != 1 || s.Old != 1 || s.Failed != 1 || s.OldTTL == nil || *s.OldTTL != 1200 {
+		t.Fatalf("unexpected local resolver summary %+v", s)
+	}
+	if s := p.Servers[1]; s.New != 1 || s.Old != 1 || s.OldTTL != nil {
+		t.Fatalf("unexpected 8.8.8.8 summary %+v", s)
+	}
+	if len(p.OldAnswers) != 1 || p.OldAnswers[0].Results != 2 || strings.Join(p.OldAnswers[0].Answers, " ") != "5.6.7.8 5.6.7.9" {
+		t.Fatalf("expected one old answer set of 2 results; got %+v", p.OldAnswers)
+	}
+	var order []string
+	for _, r := range p.Results {
+		order = append(order, r.DNSServer+" "+string(r.Status))
+	}
+	if got, exp := strings.Join(order, ","), "127.0.0.1 old,127.0.0.1 failed,127.0.0.1 new,8.8.8.8 old,8.8.8.8 new"; got != exp {
+		t.Fatalf("expected %s; got %s", exp, got)
+	}
+
+	var b bytes.Buffer
+	if err := PrintPropagation(&b, p); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for _, s := range []string{
+		"example.com A: 2 of 5 see 1.2.3.4, 2 still see an old value, 1 failed\n",
+		"127.0.0.1   1    1    1       1200s",
+		"  5.6.7.8 5.6.7.9 (2)\n",
+		"old     127.0.0.1   Node12, AS0, Amsterdam, Netherlands  5.6.7.8 5.6.7.9",
+		"failed  127.0.0.1   Node27, AS0, Hong Kong, Hong Kong",
+	} {
+		if !strings.Contains(b.String(), s) {
+			t.Errorf("expected %q in output\n%s", s, b.String())
+		}
+	}
+}
+
+func TestPropagationHosts(t *testing.T) {
+	testCases := map[string]struct {
+		queryType string
+		output    string
+		expect    []string
+		exp       PropagationStatus
+	}{
+		"MX exchange":     {"MX", `["10 Mail.example.com."]`, []string{"mail.example.com"}, PropagationNew},
+		"MX full":         {"MX", `["10 mail.example.com."]`, []string{"10 mail.example.com."}, PropagationNew},
+		"MX preference":   {"MX", `["10 mail.example.com."]`, []string{"20 mail.example.com"}, PropagationOld},
+		"MX old exchange": {"MX", `["10 mx.example.net."]`, []string{"mail.example.com"}, PropagationOld},
+		"SRV target":      {"SRV", `["10 5 5060 sip.example.com."]`, []string{"sip.example.com."}, PropagationNew},
+		"SRV full":        {"SRV", `["_sip._tcp.example.com. 300 IN SRV 10 5 5060 sip.example.com."]`, []string{"10 5 5060 sip.example.com"}, PropagationNew},
+		"SRV port":        {"SRV", `["10 5 5060 sip.example.com."]`, []string{"10 5 5061 sip.example.com"}, PropagationOld},
+		"SRV old target":  {"SRV", `["10 5 5060 sip.example.com."]`, []string{"mail.example.com"}, PropagationOld},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var o perfops.DNSTestOutput
+			json.Unmarshal([]byte(`{"id":"a1","finished":true,"items":[{"id":"1","result":{"output":`+tc.output+`,"node":{"id":5}}}]}`), &o)
+			p := NewPropagation("example.com", tc.queryType, tc.expect, []string{"127.0.0.1"}, []*perfops.DNSTestOutput{&o})
+			if got := p.Results[0].Status; got != tc.exp {
+				t.Fatalf("expected %v; got %v", tc.exp, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+var (
+	// QueryTypes are the DNS query types of DNS resolve tests.
+	QueryTypes = []string{"A", "AAAA", "CNAME", "MX", "NAPTR", "NS", "PTR", "SOA", "SPF", "SRV", "TXT"}
+	// CommonQueryTypes are the query types of "ALL", the ones commonly
+	// set on a domain name.
+	CommonQueryTypes = []string{"A", "AAAA", "CNAME", "MX", "NS", "SOA", "TXT"}
+)
+
+// QueryTypeAll selects the common query types.
+const QueryTypeAll = "ALL"
+
+type (
+	// RecordSet represents the records of a query type a node resolved.
+	RecordSet struct {
+		Type    string               `json:"type"`
+		TestID  string               `json:"test_id"`
+		Records []*perfops.DNSRecord `json:"records"`
+		// Reason explains a failed query or records that could not be
+		// parsed.
+		Reason string `json:"reason,omitempty"`
+	}
+
+	// NodeRecordSets represents the record sets of a node.
+	NodeRecordSets struct {
+		Node *perfops.Node `json:"node"`
+		Sets []*RecordSet  `json:"record_sets"`
+	}
+
+	// TypeSummary summarizes the answers of the nodes for a query type.
+	TypeSummary struct {
+		Type   string `json:"type"`
+		Nodes  int    `json:"nodes"`
+		Failed int    `json:"failed"`
+		// AnswerSets is the number of distinct answers of the nodes.
+		AnswerSets int `json:"answer_sets"`
+	}
+
+	// RecordSets represents the records of several query types resolved
+	// by the same nodes.
+	RecordSets struct {
+		Name      string            `json:"name"`
+		DNSServer string            `json:"dns_server"`
+		Types     []*TypeSummary    `json:"types"`
+		Nodes     []*NodeRecordSets `json:"nodes"`
+		// CreditsUsed is nil if the credits used are unknown.
+		CreditsUsed *int `json:"credits_used,omitempty"`
+	}
+)
+
+// ParseQueryTypes returns the query types of a comma separated list, e.g.,
+// "A,AAAA,MX". "ALL" stands for the common query types. Types other than
+// QueryTypes are refused.
+func ParseQueryTypes(s string) ([]string, error) {
+	var res []string
+	seen := map[string]bool{}
+	add := func(t string) {
+		if !seen[t] {
+			seen[t] = true
+			res = append(res, t)
+		}
+	}
+	for _, t := range strings.Split(s, ",") {
+		t = strings.ToUpper(strings.TrimSpace(t))
+		switch t {
+		case "":
+		case QueryTypeAll:
+			for _, t := range CommonQueryTypes {
+				add(t)
+			}
+		default:
+			if !containsString(QueryTypes, t) {
+				return nil, fmt.Errorf("unsupported query type '%s', use one of %s or %s", t, strings.Join(QueryTypes, ", "), QueryTypeAll)
+			}
+			add(t)
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no query type in '%s'", s)
+	}
+	return res, nil
+}
+
+// NewRecordSets correlates the outputs of the DNS resolve tests of each
+// query type by node. Nodes are ordered by country, city and ID.
+func NewRecordSets(name, dnsServer string, types []string, outputs []*perfops.DNSTestOutput) *RecordSets {
+	rs := &RecordSets{Name: name, DNSServer: dnsServer}
+	byNode := map[int]*NodeRecordSets{}
+	for i, o := range outputs {
+		for _, item := range o.Items {
+			r := item.Result
+			if r == nil || r.Node == nil {
+				continue
+			}
+			n := byNode[r.Node.ID]
+			if n == nil {
+				n = &NodeRecordSets{Node: r.Node, Sets: make([]*RecordSet, len(types))}
+				byNode[r.Node.ID] = n
+				rs.Nodes = append(rs.Nodes, n)
+			}
+			n.Sets[i] = newRecordSet(types[i], o.ID, r)
+		}
+	}
+	sort.SliceStable(rs.Nodes, func(i, j int) bool { return nodeLess(rs.Nodes[i].Node, rs.Nodes[j].Node) })
+	for i, t := range types {
+		s := &TypeSummary{Type: t}
+		answers := map[string]bool{}
+		for _, n := range rs.Nodes {
+			if n.Sets[i] == nil {
+				n.Sets[i] = &RecordSet{Type: t, TestID: outputs[i].ID, Records: []*perfops.DNSRecord{}, Reason: "no result"}
+			}
+			set := n.Sets[i]
+			s.Nodes++
+			if set.Reason != "" {
+				s.Failed++
+				continue
+			}
+			answers[strings.Join(recordValues(set.Records), "\n")] = true
+		}
+		s.AnswerSets = len(answers)
+		rs.Types = append(rs.Types, s)
+	}
+	return rs
+}
+
+func newRecordSet(queryType, testID string, r *perfops.DNSTestResult) *RecordSet {
+	set := &RecordSet{Type: queryType, TestID: testID, Records: []*perfops.DNSRecord{}}
+	status, reason := r.Status()
+	if status != perfops.StatusOK {
+		set.Reason = reason
+		if set.Reason == "" {
+			set.Reason = "no answer"
+		}
+		return set
+	}
+	records, err := r.ResolveRecords(queryType)
+	if err != nil {
+		set.Reason = err.Error()
+	}
+	if records != nil {
+		set.Records = records
+	}
+	return set
+}
+
+// recordValues returns the sorted canonical values of records.
+func recordValues(records []*perfops.DNSRecord) []string {
+	var res []string
+	for _, rec := range records {
+		res = append(res, rec.Value())
+	}
+	sort.Strings(res)
+	return res
+}
+
+// PrintRecordSets prints how many distinct answers the nodes got per query
+// type and the record sets of each node.
+func PrintRecordSets(w io.Writer, rs *RecordSets) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "TYPE\tNODES\tFAILED\tANSWERS")
+	for _, s := range rs.Types {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", s.Type, s.Nodes, s.Failed, s.AnswerSets)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, n := range rs.Nodes {
+		fmt.Fprintf(w, "\n%s\n", nodeLocation(n.Node))
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		for _, set := range n.Sets {
+			switch {
+			case set.Reason != "" && len(set.Records) == 0:
+				fmt.Fprintf(tw, "  %s\t%s\t-\n", set.Type, set.Reason)
+			case len(set.Records) == 0:
+				fmt.Fprintf(tw, "  %s\t(empty)\t-\n", set.Type)
+			}
+			for _, rec := range set.Records {
+				fmt.Fprintf(tw, "  %s\t%s\t%s\n", rec.Type, rec.Data, fmtTTL(rec.TTL))
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func TestParseQueryTypes(t *testing.T) {
+	testCases := map[string]struct {
+		s   string
+		exp string
+		err bool
+	}{
+		"Single":    {"txt", "TXT", false},
+		"List":      {"A, aaaa,MX,A", "A,AAAA,MX", false},
+		"All":       {"MX,all", "MX,A,AAAA,CNAME,NS,SOA,TXT", false},
+		"Empty":     {"", "", true},
+		"Separator": {" , ", "", true},
+		"Unknown":   {"A,AAA,MX", "", true},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			types, err := ParseQueryTypes(tc.s)
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error %v; got %v", tc.err, err)
+			}
+			if got := strings.Join(types, ","); got != tc.exp {
+				t.Fatalf("expected %v; got %v", tc.exp, got)
+			}
+		})
+	}
+	if _, err := ParseQueryTypes("MXX"); err == nil || !strings.Contains(err.Error(), "'MXX'") {
+		t.Fatalf("expected an error naming MXX; got %v", err)
+	}
+}
+
+func TestRecordSets(t *testing.T) {
+	var a, mx perfops.DNSTestOutput
+	json.Unmarshal([]byte(`{"id":"a1","finished":true,"items":[
+		{"id":"1","result":{"output":["example.com. 300 IN A 93.184.216.34"],"node":{"id":5,"as_number":3320,"city":"Frankfurt","country":{"name":"Germany"}}}},
+		{"id":"2","result":{"output":["93.184.216.35"],"node":{"id":12,"as_number":1136,"city":"Amsterdam","country":{"name":"Netherlands"}}}}]}`), &a)
+	json.Unmarshal([]byte(`{"id":"b2","finished":true,"items":[
+		{"id":"3","result":{"output":["10 mail.example.com."],"node":{"id":5,"as_number":3320,"city":"Frankfurt","country":{"name":"Germany"}}}}]}`), &mx)
+
+	rs := NewRecordSets("example.com", "8.8.8.8", []string{"A", "MX"}, []*perfops.DNSTestOutput{&a, &mx})
+	if len(rs.Nodes) != 2 || rs.Nodes[0].Node.ID != 5 || rs.Nodes[1].Node.ID != 12 {
+		t.Fatalf("expected nodes 5 and 12; got %+v", rs.Nodes)
+	}
+	if s := rs.Types[0]; s.Nodes != 2 || s.Failed != 0 || s.AnswerSets != 2 {
+		t.Fatalf("unexpected A summary %+v", s)
+	}
+	if s := rs.Types[1]; s.Nodes != 2 || s.Failed != 1 || s.AnswerSets != 1 {
+		t.Fatalf("unexpected MX summary %+v", s)
+	}
+	if set := rs.Nodes[1].Sets[1]; set.Type != "MX" || set.TestID != "b2" || set.Reason != "no result" {
+		t.Fatalf("expected a missing MX result; got %+v", set)
+	}
+
+	var b bytes.Buffer
+	if err := PrintRecordSets(&b, rs); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	exp := `TYPE  NODES  FAILED  ANSWERS
+A     2      0       2
+MX    2      1       1
+
+Node5, AS3320, Frankfurt, Germany
+  A   93.184.216.34         300s
+  MX  10 mail.example.com.  -
+
+Node12, AS1136, Amsterdam, Netherlands
+  A   93.184.216.35  -
+  MX  no result      -
+`
+	if got := b.String(); got != exp {
+		t.Fatalf("expected\n%s\ngot\n%s", exp, got)
+	}
+}
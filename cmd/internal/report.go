@@ -0,0 +1,329 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+type (
+	// ReportRun represents a test run in a report.
+	ReportRun struct {
+		ID      string
+		Type    string
+		Target  string
+		Time    time.Time
+		Results []*Result
+	}
+
+	reportData struct {
+		Title     string
+		Generated string
+		Runs      []*reportRunData
+	}
+
+	reportRunData struct {
+		ID        string
+		Heading   string
+		Time      string
+		Summary   string
+		Metrics   []string
+		Rows      []*reportRow
+		Map       *reportMap
+		Histogram *reportHistogram
+	}
+
+	reportRow struct {
+		Node     *perfops.Node
+		Location string
+		Status   perfops.Status
+		Values   []string
+		Text     string
+	}
+
+	reportMap struct {
+		Width, Height float64
+		Land          string
+		Points        []*reportPoint
+	}
+
+	reportPoint struct {
+		X, Y  float64
+		Color string
+		Title string
+	}
+
+	reportHistogram struct {
+		Label string
+		Min   string
+		Max   string
+		Bars  []*reportBar
+	}
+
+	reportBar struct {
+		X, Y, Width, Height float64
+		Title               string
+	}
+)
+
+const (
+	histogramBins   = 10
+	histogramWidth  = 400
+	histogramHeight = 120
+)
+
+// bucketColors are the colors of the map buckets in reports.
+var bucketColors = map[mapBucket]string{
+	bucketNone:   "#bbbbbb",
+	bucketGood:   "#2e9e44",
+	bucketWarn:   "#e0a800",
+	bucketBad:    "#d33333",
+	bucketFailed: "#777777",
+}
+
+// ParseReport returns the path of the report file given a report of the
+// form html=<file>.
+func ParseReport(s string) (string, error) {
+	i := strings.Index(s, "=")
+	if i < 0 || s[:i] != "html" || s[i+1:] == "" {
+		return "", fmt.Errorf("invalid report '%s', must be html=<file>", s)
+	}
+	return s[i+1:], nil
+}
+
+// WriteHTMLReport writes a self-contained HTML report of the runs to w.
+// Nodes without coordinates are placed on the map by looking them up in
+// nodes, e.g., the node catalog.
+func WriteHTMLReport(w io.Writer, title string, runs []*ReportRun, nodes []*perfops.Node) error {
+	d := &reportData{Title: title, Generated: time.Now().Format("2006-01-02 15:04:05 MST")}
+	locate := nodeLocator(nodes)
+	for _, r := range runs {
+		d.Runs = append(d.Runs, newReportRunData(r, locate))
+	}
+	return reportTmpl.Execute(w, d)
+}
+
+// WriteHTMLReportFile writes a self-contained HTML report of the runs to
+// the file at path.
+func WriteHTMLReportFile(path, title string, runs []*ReportRun, nodes []*perfops.Node) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteHTMLReport(f, title, runs, nodes); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func newReportRunData(r *ReportRun, locate func(*perfops.Node) *perfops.Node) *reportRunData {
+	metric := PrimaryMetric(r.Type)
+	d := &reportRunData{
+		ID:      r.ID,
+		Heading: strings.TrimSpace(r.Type + " " + r.Target),
+		Map:     &reportMap{Width: 360, Height: mapNorth - mapSouth, Land: landPath()},
+	}
+	if !r.Time.IsZero() {
+		d.Time = r.Time.Format("2006-01-02 15:04:05")
+	}
+	var b bytes.Buffer
+	PrintSummary(&b, Summarize(r.Results, metric))
+	d.Summary = strings.TrimPrefix(b.String(), "\n--- Summary ---\n")
+
+	metrics := ResultMetrics(r.Results)
+	for _, m := range metrics {
+		d.Metrics = append(d.Metrics, metricLabels[m])
+	}
+
+	var values []float64
+	for _, res := range r.Results {
+		row := &reportRow{Node: res.Node, Location: nodeLocation(res.Node), Status: res.Status, Text: res.Text}
+		if row.Node == nil {
+			row.Node = &perfops.Node{}
+		}
+		for _, m := range metrics {
+			v := ""
+			if x, ok := res.Metric(m); ok {
+				v = fmtValue(x)
+			}
+			row.Values = append(row.Values, v)
+		}
+		d.Rows = append(d.Rows, row)
+		if v, ok := res.Metric(metric); ok {
+			values = append(values, v)
+		}
+		if n := locate(res.Node); n != nil {
+			d.Map.Points = append(d.Map.Points, &reportPoint{
+				X:     n.Longitude + 180,
+				Y:     math.Max(0, math.Min(mapNorth-mapSouth, mapNorth-n.Latitude)),
+				Color: bucketColors[resultBucket(res, metric)],
+				Title: fmt.Sprintf("%s: %s", row.Location, res.Status),
+			})
+		}
+	}
+	d.Histogram = newReportHistogram(metricLabels[metric], values)
+	return d
+}
+
+// landPath returns the SVG path of the land masses of the map in a
+// viewport of 360 by mapNorth-mapSouth.
+func landPath() string {
+	var b strings.Builder
+	cw := 360 / float64(len(worldLand[0]))
+	ch := (mapNorth - mapSouth) / float64(len(worldLand))
+	for row, land := range worldLand {
+		for col := 0; col < len(land); col++ {
+			if land[col] != '#' {
+				continue
+			}
+			end := col
+			for end < len(land) && land[end] == '#' {
+				end++
+			}
+			fmt.Fprintf(&b, "M%s %sh%sv%sh-%sz", fmtCoord(float64(col)*cw), fmtCoord(float64(row)*ch),
+				fmtCoord(float64(end-col)*cw), fmtCoord(ch), fmtCoord(float64(end-col)*cw))
+			col = end
+		}
+	}
+	return b.String()
+}
+
+// newReportHistogram returns the histogram of the values or nil if there
+// are none.
+func newReportHistogram(label string, values []float64) *reportHistogram {
+	st := NewStats(values)
+	if st == nil {
+		return nil
+	}
+	bins := histogramBins
+	if st.Max == st.Min {
+		bins = 1
+	}
+	width := (st.Max - st.Min) / float64(bins)
+	counts := make([]int, bins)
+	var max int
+	for _, v := range values {
+		i := bins - 1
+		if width > 0 {
+			i = int(math.Min(float64(bins-1), (v-st.Min)/width))
+		}
+		counts[i]++
+		if counts[i] > max {
+			max = counts[i]
+		}
+	}
+	h := &reportHistogram{Label: label, Min: fmtValue(st.Min), Max: fmtValue(st.Max)}
+	bw := float64(histogramWidth) / float64(bins)
+	for i, c := range counts {
+		bh := float64(histogramHeight) * float64(c) / float64(max)
+		h.Bars = append(h.Bars, &reportBar{
+			X:      float64(i) * bw,
+			Y:      histogramHeight - bh,
+			Width:  bw - 2,
+			Height: bh,
+			Title:  fmt.Sprintf("%s-%s: %d nodes", fmtValue(st.Min+float64(i)*width), fmtValue(st.Min+float64(i+1)*width), c),
+		})
+	}
+	return h
+}
+
+func fmtCoord(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+var reportTmpl = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.6em; }
+h2 { font-size: 1.3em; margin-top: 2em; border-bottom: 1px solid #ddd; }
+.meta { color: #666; }
+pre { background: #f6f8fa; padding: .8em; overflow-x: auto; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ddd; padding: .3em .6em; text-align: left; }
+th { background: #f0f0f0; cursor: pointer; user-select: none; }
+th[data-order=asc]::after { content: " \25B2"; }
+th[data-order=desc]::after { content: " \25BC"; }
+td.num { text-align: right; }
+.ok { color: #2e9e44; }
+.timeout, .error { color: #d33333; }
+.map { width: 100%; max-width: 900px; background: #eef4fa; }
+.map .land { fill: #d6d6d6; }
+.histogram { width: 100%; max-width: 600px; }
+.histogram rect { fill: #4a7fc1; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">Generated {{.Generated}}</p>
+{{range .Runs}}
+<h2>{{.Heading}}</h2>
+<p class="meta">Test ID {{.ID}}{{if .Time}}, run {{.Time}}{{end}}</p>
+<pre>{{.Summary}}</pre>
+<svg class="map" viewBox="0 0 {{.Map.Width}} {{.Map.Height}}" xmlns="http://www.w3.org/2000/svg">
+<path class="land" d="{{.Map.Land}}"/>
+{{range .Map.Points}}<circle cx="{{printf "%.2f" .X}}" cy="{{printf "%.2f" .Y}}" r="2" fill="{{.Color}}" stroke="#fff" stroke-width=".4"><title>{{.Title}}</title></circle>
+{{end}}</svg>
+{{with .Histogram}}
+<h3>{{.Label}}</h3>
+<svg class="histogram" viewBox="0 -2 400 140" xmlns="http://www.w3.org/2000/svg">
+{{range .Bars}}<rect x="{{printf "%.2f" .X}}" y="{{printf "%.2f" .Y}}" width="{{printf "%.2f" .Width}}" height="{{printf "%.2f" .Height}}"><title>{{.Title}}</title></rect>
+{{end}}<text x="0" y="136" font-size="10">{{.Min}}</text><text x="400" y="136" font-size="10" text-anchor="end">{{.Max}}</text>
+</svg>
+{{end}}
+<table class="sortable">
+<thead><tr><th>Node</th><th>ASN</th><th>City</th><th>Country</th><th>Continent</th><th>Status</th>{{range .Metrics}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr><td class="num">{{.Node.ID}}</td><td class="num">{{.Node.AsNumber}}</td><td>{{.Node.City}}</td><td>{{.Node.CountryName}}</td><td>{{.Node.ContinentName}}</td><td class="{{.Status}}">{{.Status}}</td>{{range .Values}}<td class="num">{{.}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+<h3>Raw output</h3>
+{{range .Rows}}<details><summary>{{.Location}} ({{.Status}})</summary><pre>{{.Text}}</pre></details>
+{{end}}
+{{end}}
+<script>
+document.querySelectorAll("table.sortable th").forEach(function(th) {
+  th.addEventListener("click", function() {
+    var i = th.cellIndex, tbody = th.closest("table").tBodies[0];
+    var asc = th.getAttribute("data-order") !== "asc";
+    th.parentNode.querySelectorAll("th").forEach(function(h) { h.removeAttribute("data-order"); });
+    th.setAttribute("data-order", asc ? "asc" : "desc");
+    var rows = Array.prototype.slice.call(tbody.rows);
+    rows.sort(function(a, b) {
+      var x = a.cells[i].textContent, y = b.cells[i].textContent;
+      var nx = parseFloat(x), ny = parseFloat(y);
+      var c = !isNaN(nx) && !isNaN(ny) ? nx - ny : x.localeCompare(y);
+      return asc ? c : -c;
+    });
+    rows.forEach(function(r) { tbody.appendChild(r); });
+  });
+});
+</script>
+</body>
+</html>
+`))
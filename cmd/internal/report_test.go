@@ -0,0 +1,90 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func TestParseReport(t *testing.T) {
+	testCases := map[string]struct {
+		report string
+		exp    string
+		expErr bool
+	}{
+		"HTML":    {"html=out.html", "out.html", false},
+		"Path":    {"html=reports/a=b.html", "reports/a=b.html", false},
+		"Format":  {"pdf=out.pdf", "", true},
+		"No file": {"html=", "", true},
+		"Missing": {"out.html", "", true},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseReport(tc.report)
+			if (err != nil) != tc.expErr {
+				t.Fatalf("expected error %v; got %v", tc.expErr, err)
+			}
+			if got != tc.exp {
+				t.Fatalf("expected %q; got %q", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestWriteHTMLReport(t *testing.T) {
+	results := testResults()
+	results[0].Text = "PING <example.com>"
+	nodes := []*perfops.Node{{ID: 5, Latitude: 50.11, Longitude: 8.69}, {ID: 27, Latitude: 22.29, Longitude: 114.18}}
+	runs := []*ReportRun{{ID: "abc123", Type: "ping", Target: "example.com", Time: time.Now(), Results: results}}
+	var b bytes.Buffer
+	if err := WriteHTMLReport(&b, "Incident <42>", runs, nodes); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	got := b.String()
+	for _, exp := range []string{
+		"<title>Incident &lt;42&gt;</title>",
+		"<h2>ping example.com</h2>",
+		"<th>RTT (ms)</th>",
+		`<circle cx="188.69" cy="33.89" r="2" fill="#2e9e44"`,
+		"<pre>PING &lt;example.com&gt;</pre>",
+		"5 nodes: 3 ok, 1 failed, 1 timed out",
+	} {
+		if !strings.Contains(got, exp) {
+			t.Fatalf("expected %q in report", exp)
+		}
+	}
+	for exp, n := range map[string]int{"<circle ": 2, "<tr><td": 5, "<details>": 5, "<rect ": histogramBins} {
+		if c := strings.Count(got, exp); c != n {
+			t.Fatalf("expected %d %q; got %d", n, exp, c)
+		}
+	}
+	if strings.Contains(got, "src=") || strings.Contains(got, "href=") {
+		t.Fatal("expected no external assets in report")
+	}
+}
+
+func TestReportHistogram(t *testing.T) {
+	if h := newReportHistogram("RTT (ms)", nil); h != nil {
+		t.Fatalf("expected no histogram; got %v", h)
+	}
+	h := newReportHistogram("RTT (ms)", []float64{7, 7})
+	if len(h.Bars) != 1 || h.Bars[0].Title != "7.00-7.00: 2 nodes" {
+		t.Fatalf("expected a single bar; got %v", h.Bars)
+	}
+}
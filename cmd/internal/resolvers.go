@@ -0,0 +1,246 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+type (
+	// ResolverStats summarizes the resolve times of a DNS server.
+	ResolverStats struct {
+		DNSServer string `json:"dns_server"`
+		TestID    string `json:"test_id"`
+		// Rank orders the DNS servers by their median resolve time, the
+		// DNS servers no node could resolve with last.
+		Rank   int `json:"rank"`
+		Nodes  int `json:"nodes"`
+		Failed int `json:"failed"`
+		// Stats summarizes the resolve times in ms, nil if every node
+		// failed.
+		Stats *Stats `json:"stats,omitempty"`
+		// Wins is the number of nodes the DNS server was the fastest on.
+		Wins int `json:"wins"`
+	}
+
+	// ResolverNode represents the resolve times of a node by DNS server.
+	ResolverNode struct {
+		Node *perfops.Node `json:"node"`
+		// Times are the resolve times in ms by DNS server.
+		Times map[string]float64 `json:"times,omitempty"`
+		// Failed are the reasons by DNS server the node failed with.
+		Failed map[string]string `json:"failed,omitempty"`
+		// Fastest is the DNS server with the lowest resolve time.
+		Fastest string `json:"fastest,omitempty"`
+	}
+
+	// ResolverRegion represents the median resolve times of the nodes of
+	// a continent by DNS server.
+	ResolverRegion struct {
+		Continent string             `json:"continent"`
+		Nodes     int                `json:"nodes"`
+		Medians   map[string]float64 `json:"medians,omitempty"`
+		Fastest   string             `json:"fastest,omitempty"`
+	}
+
+	// ResolverComparison represents the resolve times of a target with
+	// several DNS servers from the same nodes.
+	ResolverComparison struct {
+		Target     string   `json:"target"`
+		DNSServers []string `json:"dns_servers"`
+		// Resolvers are the DNS servers by rank.
+		Resolvers []*ResolverStats  `json:"resolvers"`
+		Regions   []*ResolverRegion `json:"regions"`
+		Nodes     []*ResolverNode   `json:"nodes"`
+		// CreditsUsed is nil if the credits used are unknown.
+		CreditsUsed *int `json:"credits_used,omitempty"`
+	}
+)
+
+// NewResolverComparison returns the comparison of the DNS servers from the
+// outputs of the DNS perf tests against each of them.
+func NewResolverComparison(target string, dnsServers []string, outputs []*perfops.DNSTestOutput) *ResolverComparison {
+	rc := &ResolverComparison{Target: target, DNSServers: dnsServers}
+	nodes := map[int]*ResolverNode{}
+	times := make([][]float64, len(dnsServers))
+	for i, o := range outputs {
+		s := &ResolverStats{DNSServer: dnsServers[i], TestID: o.ID}
+		rc.Resolvers = append(rc.Resolvers, s)
+		for _, r := range DNSResults("dnsperf", o) {
+			if r.Node == nil {
+				continue
+			}
+			n := nodes[r.Node.ID]
+			if n == nil {
+				n = &ResolverNode{Node: r.Node, Times: map[string]float64{}, Failed: map[string]string{}}
+				nodes[r.Node.ID] = n
+				rc.Nodes = append(rc.Nodes, n)
+			}
+			s.Nodes++
+			if v, ok := r.Metric(MetricResolve); ok {
+				n.Times[s.DNSServer] = v
+				times[i] = append(times[i], v)
+				continue
+			}
+			s.Failed++
+			reason := r.Reason
+			if reason == "" {
+				reason = string(r.Status)
+			}
+			n.Failed[s.DNSServer] = reason
+		}
+		s.Stats = NewStats(times[i])
+	}
+
+	wins := map[string]int{}
+	regions := map[string]*ResolverRegion{}
+	regionTimes := map[string]map[string][]float64{}
+	for _, n := range rc.Nodes {
+		n.Fastest = fastestResolver(dnsServers, n.Times)
+		if n.Fastest != "" {
+			wins[n.Fastest]++
+		}
+		continent := n.Node.ContinentName()
+		if continent == "" {
+			continent = unknownLocation
+		}
+		if regions[continent] == nil {
+			regions[continent] = &ResolverRegion{Continent: continent}
+			regionTimes[continent] = map[string][]float64{}
+			rc.Regions = append(rc.Regions, regions[continent])
+		}
+		regions[continent].Nodes++
+		for server, v := range n.Times {
+			regionTimes[continent][server] = append(regionTimes[continent][server], v)
+		}
+	}
+	for _, r := range rc.Regions {
+		r.Medians = map[string]float64{}
+		for server, v := range regionTimes[r.Continent] {
+			r.Medians[server] = NewStats(v).Median
+		}
+		r.Fastest = fastestResolver(dnsServers, r.Medians)
+	}
+	for _, s := range rc.Resolvers {
+		s.Wins = wins[s.DNSServer]
+	}
+
+	sort.SliceStable(rc.Resolvers, func(i, j int) bool {
+		a, b := rc.Resolvers[i], rc.Resolvers[j]
+		if a.Stats == nil || b.Stats == nil {
+			return a.Stats != nil
+		}
+		if a.Stats.Median != b.Stats.Median {
+			return a.Stats.Median < b.Stats.Median
+		}
+		if a.Stats.P95 != b.Stats.P95 {
+			return a.Stats.P95 < b.Stats.P95
+		}
+		return a.Failed < b.Failed
+	})
+	for i, s := range rc.Resolvers {
+		s.Rank = i + 1
+	}
+	sort.Slice(rc.Regions, func(i, j int) bool { return rc.Regions[i].Continent < rc.Regions[j].Continent })
+	sort.SliceStable(rc.Nodes, func(i, j int) bool { return nodeLess(rc.Nodes[i].Node, rc.Nodes[j].Node) })
+	return rc
+}
+
+// fastestResolver returns the DNS server with the lowest time, the first
+// one on ties.
+func fastestResolver(dnsServers []string, times map[string]float64) string {
+	var fastest string
+	for _, server := range dnsServers {
+		v, ok := times[server]
+		if ok && (fastest == "" || v < times[fastest]) {
+			fastest = server
+		}
+	}
+	return fastest
+}
+
+// PrintResolverComparison prints the ranking of the DNS servers, their
+// median resolve times by continent and the resolve times of each node.
+func PrintResolverComparison(w io.Writer, rc *ResolverComparison) error {
+	fmt.Fprintf(w, "dnsperf %s: %d DNS servers from %d nodes\n\n", rc.Target, len(rc.DNSServers), len(rc.Nodes))
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "RANK\tDNS SERVER\tNODES\tFAILED\tP50 (ms)\tP95 (ms)\tWINS")
+	for _, s := range rc.Resolvers {
+		p50, p95 := "-", "-"
+		if s.Stats != nil {
+			p50, p95 = fmtValue(s.Stats.Median), fmtValue(s.Stats.P95)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%s\t%s\t%d\n", s.Rank, s.DNSServer, s.Nodes, s.Failed, p50, p95, s.Wins)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "\nMedian resolve time (ms) by continent:")
+	tw = tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprint(tw, "CONTINENT\tNODES")
+	for _, server := range rc.DNSServers {
+		fmt.Fprintf(tw, "\t%s", server)
+	}
+	fmt.Fprintln(tw, "\tFASTEST")
+	for _, r := range rc.Regions {
+		fmt.Fprintf(tw, "%s\t%d", r.Continent, r.Nodes)
+		for _, server := range rc.DNSServers {
+			fmt.Fprintf(tw, "\t%s", fmtResolverTime(r.Medians, nil, server))
+		}
+		fmt.Fprintf(tw, "\t%s\n", fmtFastest(r.Fastest))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "\nResolve time (ms) by node:")
+	tw = tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprint(tw, "NODE")
+	for _, server := range rc.DNSServers {
+		fmt.Fprintf(tw, "\t%s", server)
+	}
+	fmt.Fprintln(tw, "\tFASTEST")
+	for _, n := range rc.Nodes {
+		fmt.Fprint(tw, nodeLocation(n.Node))
+		for _, server := range rc.DNSServers {
+			fmt.Fprintf(tw, "\t%s", fmtResolverTime(n.Times, n.Failed, server))
+		}
+		fmt.Fprintf(tw, "\t%s\n", fmtFastest(n.Fastest))
+	}
+	return tw.Flush()
+}
+
+func fmtResolverTime(times map[string]float64, failed map[string]string, server string) string {
+	if v, ok := times[server]; ok {
+		return fmtValue(v)
+	}
+	if _, ok := failed[server]; ok {
+		return "failed"
+	}
+	return "-"
+}
+
+func fmtFastest(server string) string {
+	if server == "" {
+		return "-"
+	}
+	return server
+}
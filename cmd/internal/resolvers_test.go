@@ -0,0 +1,87 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func TestResolverComparison(t *testing.T) {
+	const (
+		frankfurt = `"node":{"id":5,"city":"Frankfurt","country":{"name":"Germany","continent":{"name":"Europe"}}}`
+		amsterdam = `"node":{"id":12,"city":"Amsterdam","country":{"name":"Netherlands","continent":{"name":"Europe"}}}`
+		hongKong  = `"node":{"id":27,"city":"Hong Kong","country":{"name":"Hong Kong","continent":{"name":"Asia"}}}`
+	)
+	var google, cloudflare, local perfops.DNSTestOutput
+	json.Unmarshal([]byte(`{"id":"a1","finished":true,"items":[
+		{"id":"1","result":{"output":"12.5",`+frankfurt+`}},
+		{"id":"2","result":{"output":"8",`+amsterdam+`}},
+		{"id":"3","result":{"output":"40",`+hongKong+`}}]}`), &google)
+	json.Unmarshal([]byte(`{"id":"b2","finished":true,"items":[
+		{"id":"4","result":{"output":"3",`+frankfurt+`}},
+		{"id":"5","result":{"output":"-2",`+amsterdam+`}},
+		{"id":"6","result":{"output":"20",`+hongKong+`}}]}`), &cloudflare)
+	json.Unmarshal([]byte(`{"id":"c3","finished":true,"items":[
+		{"id":"7","result":{"output":"1",`+frankfurt+`}},
+		{"id":"8","result":{"output":"2",`+amsterdam+`}}]}`), &local)
+
+	servers := []string{"8.8.8.8", "1.1.1.1", "127.0.0.1"}
+	rc := NewResolverComparison("example.com", servers, []*perfops.DNSTestOutput{&google, &cloudflare, &local})
+	var ranking []string
+	for _, s := range rc.Resolvers {
+		ranking = append(ranking, s.DNSServer)
+	}
+	if got, exp := strings.Join(ranking, ","), "127.0.0.1,1.1.1.1,8.8.8.8"; got != exp {
+		t.Fatalf("expected ranking %s; got %s", exp, got)
+	}
+	if s := rc.Resolvers[1]; s.Rank != 2 || s.TestID != "b2" || s.Nodes != 3 || s.Failed != 1 || s.Wins != 1 || s.Stats.Median != 11.5 {
+		t.Fatalf("unexpected 1.1.1.1 summary %+v", s)
+	}
+	if s := rc.Resolvers[0]; s.Wins != 2 || s.Failed != 0 || s.Stats.P95 != 1.95 {
+		t.Fatalf("unexpected local resolver summary %+v", s)
+	}
+	if got, exp := len(rc.Regions), 2; got != exp {
+		t.Fatalf("expected %d regions; got %d", exp, got)
+	}
+	if r := rc.Regions[0]; r.Continent != "Asia" || r.Nodes != 1 || r.Fastest != "1.1.1.1" {
+		t.Fatalf("unexpected Asia region %+v", r)
+	}
+	if r := rc.Regions[1]; r.Nodes != 2 || r.Fastest != "127.0.0.1" || r.Medians["8.8.8.8"] != 10.25 {
+		t.Fatalf("unexpected Europe region %+v", r)
+	}
+	if n := rc.Nodes[1]; n.Node.ID != 27 || n.Fastest != "1.1.1.1" || len(n.Times) != 2 {
+		t.Fatalf("unexpected Hong Kong node %+v", n)
+	}
+
+	var b bytes.Buffer
+	if err := PrintResolverComparison(&b, rc); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for _, s := range []string{
+		"dnsperf example.com: 3 DNS servers from 3 nodes\n",
+		"1     127.0.0.1   2      0       1.50      1.95      2\n",
+		"3     8.8.8.8     3      0       12.50     37.25     0\n",
+		"Asia       1      40.00    20.00    -          1.1.1.1\n",
+		"Node12, AS0, Amsterdam, Netherlands  8.00     failed   2.00       127.0.0.1\n",
+	} {
+		if !strings.Contains(b.String(), s) {
+			t.Errorf("expected %q in output\n%s", s, b.String())
+		}
+	}
+}
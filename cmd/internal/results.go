@@ -0,0 +1,218 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+// Metric names.
+const (
+	MetricRTT     = "rtt"
+	MetricLoss    = "loss"
+	MetricTTFB    = "ttfb"
+	MetricTotal   = "total"
+	MetricResolve = "resolve"
+)
+
+// Result represents the normalized result of a node.
+type Result struct {
+	ID     string         `json:"id,omitempty"`
+	Node   *perfops.Node  `json:"node,omitempty"`
+	Status perfops.Status `json:"status"`
+	Reason string         `json:"reason,omitempty"`
+	// Text is the output shown for the node.
+	Text string `json:"-"`
+	// Metrics holds the values parsed from the output, e.g., the round
+	// trip time in milliseconds or the packet loss in percent.
+	Metrics map[string]float64 `json:"metrics,omitempty"`
+}
+
+var (
+	pingRTTRe  = regexp.MustCompile(`= [\d.]+/([\d.]+)/[\d.]+`)
+	pingLossRe = regexp.MustCompile(`([\d.]+)% packet loss`)
+	mtrHopRe   = regexp.MustCompile(`^\s*\d+\.\|--\s+\S+\s+([\d.]+)%?\s+\d+\s+[\d.]+\s+([\d.]+)`)
+	msRe       = regexp.MustCompile(`([\d.]+) ms`)
+)
+
+// ResultMetrics returns the metrics any of the results has in a fixed
+// order.
+func ResultMetrics(results []*Result) []string {
+	seen := map[string]bool{}
+	for _, r := range results {
+		for m := range r.Metrics {
+			seen[m] = true
+		}
+	}
+	var res []string
+	for _, m := range []string{MetricRTT, MetricLoss, MetricTTFB, MetricTotal, MetricResolve} {
+		if seen[m] {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+// PrimaryMetric returns the name of the metric results of a test type
+// are compared by, if any.
+func PrimaryMetric(testType string) string {
+	switch testType {
+	case "latency", "mtr", "ping", "traceroute":
+		return MetricRTT
+	case "curl":
+		return MetricTTFB
+	case "dnsperf":
+		return MetricResolve
+	}
+	return ""
+}
+
+// RunResults returns the normalized results of the output of an MTR,
+// ping, traceroute, latency or curl test.
+func RunResults(testType string, o *perfops.RunOutput) []*Result {
+	var res []*Result
+	for _, item := range o.Items {
+		r := item.Result
+		if r == nil {
+			continue
+		}
+		status, reason := r.Status()
+		text, _ := resultText(r)
+		res = append(res, &Result{
+			ID:      item.ID,
+			Node:    r.Node,
+			Status:  status,
+			Reason:  reason,
+			Text:    text,
+			Metrics: runMetrics(testType, status, text, r.Timing),
+		})
+	}
+	return res
+}
+
+// DNSResults returns the normalized results of the output of a DNS perf
+// or DNS resolve test.
+func DNSResults(testType string, o *perfops.DNSTestOutput) []*Result {
+	var res []*Result
+	for _, item := range o.Items {
+		r := item.Result
+		if r == nil {
+			continue
+		}
+		status, reason := r.Status()
+		text := reason
+		if status == perfops.StatusOK {
+			text = dnsResultText(testType, r)
+		}
+		res = append(res, &Result{
+			ID:      item.ID,
+			Node:    r.Node,
+			Status:  status,
+			Reason:  reason,
+			Text:    text,
+			Metrics: dnsMetrics(testType, status, r),
+		})
+	}
+	return res
+}
+
+// Metric returns the value of a metric and whether the result has it.
+func (r *Result) Metric(name string) (float64, bool) {
+	v, ok := r.Metrics[name]
+	return v, ok
+}
+
+func runMetrics(testType string, status perfops.Status, text string, timing *perfops.RunTiming) map[string]float64 {
+	if status != perfops.StatusOK {
+		return nil
+	}
+	m := map[string]float64{}
+	switch testType {
+	case "latency":
+		if v, err := strconv.ParseFloat(strings.TrimSpace(text), 64); err == nil {
+			m[MetricRTT] = v
+		}
+	case "ping":
+		if sm := pingRTTRe.FindStringSubmatch(text); sm != nil {
+			m[MetricRTT], _ = strconv.ParseFloat(sm[1], 64)
+		}
+		if sm := pingLossRe.FindStringSubmatch(text); sm != nil {
+			m[MetricLoss], _ = strconv.ParseFloat(sm[1], 64)
+		}
+	case "mtr":
+		// The last hop is the target.
+		lines := strings.Split(strings.TrimSpace(text), "\n")
+		if sm := mtrHopRe.FindStringSubmatch(lines[len(lines)-1]); sm != nil {
+			m[MetricLoss], _ = strconv.ParseFloat(sm[1], 64)
+			m[MetricRTT], _ = strconv.ParseFloat(sm[2], 64)
+		}
+	case "traceroute":
+		lines := strings.Split(strings.TrimSpace(text), "\n")
+		if v, ok := meanMS(lines[len(lines)-1]); ok {
+			m[MetricRTT] = v
+		}
+	case "curl":
+		// curl reports its timings in seconds.
+		if timing != nil {
+			m[MetricTTFB] = timing.TTFB * 1000
+			m[MetricTotal] = timing.Total * 1000
+		}
+	}
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+func dnsMetrics(testType string, status perfops.Status, r *perfops.DNSTestResult) map[string]float64 {
+	if status != perfops.StatusOK || testType != "dnsperf" {
+		return nil
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(r.PerfOutput()), 64)
+	if err != nil {
+		// Some outputs are numbers rather than strings.
+		if json.Unmarshal(r.Output, &v) != nil {
+			return nil
+		}
+	}
+	return map[string]float64{MetricResolve: v}
+}
+
+func dnsResultText(testType string, r *perfops.DNSTestResult) string {
+	if testType == "resolve" {
+		return strings.Join(r.ResolveOutput(), "\n")
+	}
+	return r.PerfOutput()
+}
+
+// meanMS returns the mean of the millisecond values in s.
+func meanMS(s string) (float64, bool) {
+	var sum float64
+	n := 0
+	for _, sm := range msRe.FindAllStringSubmatch(s, -1) {
+		if v, err := strconv.ParseFloat(sm[1], 64); err == nil {
+			sum += v
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return sum / float64(n), true
+}
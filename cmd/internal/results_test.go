@@ -0,0 +1,77 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+const (
+	testPingOutput = "PING google.com (173.194.222.113) 56(84) bytes of data.\n64 bytes from 173.194.222.113: icmp_seq=1 ttl=50 time=11.6 ms\n\n--- google.com ping statistics ---\n3 packets transmitted, 2 received, 33% packet loss, time 602ms\nrtt min/avg/max/mdev = 11.433/11.513/11.650/0.157 ms"
+	testMTROutput  = "Start: Thu Jul 27 15:59:05 2017                Loss%   Snt   Last   Avg  Best  Wrst StDev\n  1.|-- 172.18.0.1                 0.0%     2    0.0   0.1   0.0   0.1   0.0\n  2.|-- ???                       100.0     2    0.0   0.0   0.0   0.0   0.0\n  3.|-- 13.107.21.200             50.0%     2   39.8  40.1  39.8  40.3   0.0\n"
+	testTROutput   = "traceroute to google.com (172.217.10.46), 20 hops max, 60 byte packets\n 1  vl223-ar-02.nyc-ny.atlantic.net (45.58.33.35)  0.432 ms  0.420 ms\n13  lga34s13-in-f14.1e100.net (172.217.10.46)  1.826 ms  1.874 ms"
+)
+
+func TestRunResultsMetrics(t *testing.T) {
+	testCases := map[string]struct {
+		testType string
+		result   *perfops.RunResult
+		exp      map[string]float64
+	}{
+		"Ping":       {"ping", &perfops.RunResult{Output: testPingOutput, Finished: true}, map[string]float64{MetricRTT: 11.513, MetricLoss: 33}},
+		"Latency":    {"latency", &perfops.RunResult{Output: "7.705", Finished: true}, map[string]float64{MetricRTT: 7.705}},
+		"MTR":        {"mtr", &perfops.RunResult{Output: testMTROutput, Finished: true}, map[string]float64{MetricRTT: 40.1, MetricLoss: 50}},
+		"Traceroute": {"traceroute", &perfops.RunResult{Output: testTROutput, Finished: true}, map[string]float64{MetricRTT: 1.85}},
+		"Curl":       {"curl", &perfops.RunResult{Output: "HTTP/1.1 200 OK", Finished: true, Timing: &perfops.RunTiming{Total: 0.25, TTFB: 0.125}}, map[string]float64{MetricTTFB: 125, MetricTotal: 250}},
+		"Timeout":    {"ping", &perfops.RunResult{Output: "-2", Finished: true}, nil},
+		"Garbage":    {"latency", &perfops.RunResult{Output: "n/a", Finished: true}, nil},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			o := &perfops.RunOutput{Items: []*perfops.RunItem{{ID: "1", Result: tc.result}}}
+			res := RunResults(tc.testType, o)
+			if len(res) != 1 {
+				t.Fatalf("expected 1 result; got %d", len(res))
+			}
+			got := res[0].Metrics
+			for k, v := range got {
+				got[k] = float64(int(v*1000+0.5)) / 1000
+			}
+			if !reflect.DeepEqual(got, tc.exp) {
+				t.Fatalf("expected %v; got %v", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestDNSResults(t *testing.T) {
+	o := &perfops.DNSTestOutput{Items: []*perfops.DNSTestItem{
+		{ID: "1", Result: &perfops.DNSTestResult{Node: &perfops.Node{ID: 1}, Output: json.RawMessage(`"12.5"`)}},
+		{ID: "2", Result: &perfops.DNSTestResult{Node: &perfops.Node{ID: 2}, Message: "SERVFAIL"}},
+	}}
+	res := DNSResults("dnsperf", o)
+	if got, ok := res[0].Metric(MetricResolve); !ok || got != 12.5 {
+		t.Fatalf("expected resolve time 12.5; got %v, %v", got, ok)
+	}
+	if res[1].Status != perfops.StatusError || res[1].Reason != "SERVFAIL" {
+		t.Fatalf("expected error status; got %v, %v", res[1].Status, res[1].Reason)
+	}
+	if PrimaryMetric("resolve") != "" {
+		t.Fatal("expected no primary metric for resolve tests")
+	}
+}
@@ -40,6 +40,11 @@ type (
 		printID bool
 		s       *Spinner
 		w       terminalWriter
+		// live is set if the output is redrawn in place. Otherwise the
+		// output of each node is appended once it finished.
+		live      bool
+		idPrinted bool
+		printed   map[string]bool
 
 		mu  sync.Mutex
 		buf bytes.Buffer
@@ -53,27 +58,119 @@ type (
 		err    error
 	}
 
+	// FinishedTest is a test whose output is complete.
+	FinishedTest struct {
+		ID      perfops.TestID
+		Type    string
+		Target  string
+		Started time.Time
+		// CreditsWithdrawn are the credits the API reported for the test,
+		// nil if not reported.
+		CreditsWithdrawn *int
+		// Nodes is the number of nodes the test ran on.
+		Nodes   int
+		Results []*Result
+		// JSON is the structured output of the test.
+		JSON TestJSON
+		// PrintResults tells whether the results are printed as text, e.g.,
+		// because they were not printed as they arrived.
+		PrintResults bool
+	}
+
 	runFunc       func(ctx context.Context, req *perfops.RunRequest) (perfops.TestID, error)
 	runOutputFunc func(ctx context.Context, pingID perfops.TestID) (*perfops.RunOutput, error)
 )
 
+// Output formats of the test commands.
+const (
+	OutputText     = "text"
+	OutputJSON     = "json"
+	OutputGeoJSON  = "geojson"
+	OutputKML      = "kml"
+	OutputMarkdown = "markdown"
+)
+
+// OutputFormats lists the output formats of the test commands.
+var OutputFormats = []string{OutputText, OutputJSON, OutputGeoJSON, OutputKML, OutputMarkdown}
+
 // RunTest runs an MTR or ping test retrieves its output and presents it to the user.
-func RunTest(ctx context.Context, target, location string, nodeIDs []int, limit int, ipversion int, debug, outputJSON bool, runTest runFunc, runOutput runOutputFunc) error {
-	runReq := &perfops.RunRequest{
-		Target:    target,
-		Location:  location,
-		Nodes:     nodeIDs,
-		Limit:     limit,
-		IPVersion: ipversion,
+func RunTest(ctx context.Context, testType, target, location string, nodeIDs []int, limit int, ipversion int, debug bool, format string, view *View, runTest runFunc, runOutput runOutputFunc) error {
+	if err := view.Validate(); err != nil {
+		return err
+	}
+	// Structured output is printed once the test finished.
+	structured := format != OutputText
+	runReq, err := NewRunRequest(target, location, nodeIDs, limit, ipversion)
+	if err != nil {
+		return err
+	}
+	if err := view.Credits.Check(NewPlan(testType, target, runReq.Location, runReq.Quotas, nodeIDs, limit)); err != nil {
+		return err
 	}
 
-	f := NewFormatter(debug && !outputJSON)
+	f := NewFormatter(debug && !structured)
 	f.StartSpinner()
+	started := time.Now()
 	testID, err := runTest(ctx, runReq)
 	f.StopSpinner()
 	if err != nil {
 		return err
 	}
+	var o *perfops.RunOutput
+	if view.TUI {
+		title := fmt.Sprintf("%s %s (%s)", testType, target, testID)
+		if o, err = WatchRun(ctx, title, testType, testID, runOutput); err != nil {
+			return err
+		}
+		if o == nil || !o.IsFinished() {
+			PrintStillRunning(os.Stdout, testID)
+			return nil
+		}
+	} else if o, err = waitRunOutput(ctx, f, testType, testID, structured, view, runOutput); err != nil {
+		return err
+	}
+	f.StopSpinner()
+	return FinishTest(&FinishedTest{
+		ID:               testID,
+		Type:             testType,
+		Target:           target,
+		Started:          started,
+		CreditsWithdrawn: o.CreditsWithdrawn,
+		Nodes:            len(o.NodeIDs()),
+		Results:          RunResults(testType, o),
+		JSON:             NewRunJSON(testType, o),
+		PrintResults:     view.TUI,
+	}, format, view)
+}
+
+// FinishTest settles the credits of a finished test and writes its report
+// if the view asks for one. Then its output is printed in the format, or
+// as text followed by the summary of its results and the credits used.
+func FinishTest(t *FinishedTest, format string, view *View) error {
+	view.Credits.Settle(t.ID, t.CreditsWithdrawn, t.Nodes)
+	if err := view.WriteReport(&ReportRun{ID: string(t.ID), Type: t.Type, Target: t.Target, Time: t.Started, Results: t.Results}); err != nil {
+		return err
+	}
+	if format != OutputText {
+		var aggregates *Aggregates
+		if view.Aggregate {
+			aggregates = Aggregate(t.Results, PrimaryMetric(t.Type))
+		}
+		t.JSON.setTotals(aggregates, view.Credits.Used())
+		return PrintOutputFormat(format, t.Type+" "+string(t.ID), t.JSON, t.Results, view.Nodes)
+	}
+	if t.PrintResults {
+		PrintResults(os.Stdout, t.Type, t.Results, view)
+	}
+	if err := PrintResultsReport(os.Stdout, t.Type, t.Results, view); err != nil {
+		return err
+	}
+	return PrintCreditsUsed(os.Stdout, view.Credits)
+}
+
+// waitRunOutput polls the output of a test until it finished. Text output
+// is printed as it arrives.
+func waitRunOutput(ctx context.Context, f *Formatter, testType string, testID perfops.TestID, structured bool, view *View, runOutput runOutputFunc) (*perfops.RunOutput, error) {
 	res := &RunOutputResult{}
 	go func() {
 		for {
@@ -88,29 +185,58 @@ func RunTest(ctx context.Context, target, location string, nodeIDs []int, limit
 		}
 	}()
 
-	if outputJSON {
+	if structured {
 		f.StartSpinner()
 	}
-	var o *perfops.RunOutput
 	for {
 		select {
 		case <-time.After(50 * time.Millisecond):
 		}
-		if o, err = res.Output(); err != nil {
-			return err
+		o, err := res.Output()
+		if err != nil {
+			return nil, err
 		}
-		if !outputJSON && o != nil {
-			PrintOutput(f, o)
+		if !structured && o != nil && (!Quiet() || o.IsFinished()) {
+			if o.IsFinished() && view.IsSet() {
+				PrintOutputView(f, testType, o, view)
+			} else {
+				PrintOutput(f, o)
+			}
 		}
 		if o != nil && o.IsFinished() {
-			break
+			return o, nil
 		}
 	}
-	if outputJSON {
-		f.StopSpinner()
-		PrintOutputJSON(o)
+}
+
+// NewRunRequest returns the request of an MTR, ping, latency or traceroute
+// test.
+func NewRunRequest(target, location string, nodeIDs []int, limit int, ipversion int) (*perfops.RunRequest, error) {
+	location, quotas, err := ParseLocation(location)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return &perfops.RunRequest{
+		Target:    target,
+		Location:  location,
+		Nodes:     nodeIDs,
+		Limit:     limit,
+		IPVersion: ipversion,
+		Quotas:    quotas,
+	}, nil
+}
+
+// ParseLocation splits the value of --from into a location or a list of
+// per-location quotas, e.g., "Europe:5,North America:5,Asia:3".
+func ParseLocation(from string) (string, perfops.LocationQuotas, error) {
+	if !perfops.IsLocationQuotas(from) {
+		return from, nil, nil
+	}
+	quotas, err := perfops.ParseLocationQuotas(from)
+	if err != nil {
+		return "", nil, err
+	}
+	return "", quotas, nil
 }
 
 // formatFileName Returns a file name based on provided string and number
@@ -146,25 +272,10 @@ func OutputToFile(f *Formatter, output *perfops.RunOutput, fileOut string) {
 	for i, item := range output.Items {
 		r := item.Result
 		n := r.Node
-		if item.Result.Message == "" {
-			o := r.Output
-			if o == "-2" {
-				o = "The command timed-out. It either took too long to execute or we could not connect to your target at all."
-			} else if a, ok := o.([]interface{}); ok {
-				sb := strings.Builder{}
-				for _, i := range a {
-					sb.WriteString(fmt.Sprintf("%s\n", i))
-				}
-				s := sb.String()
-				o = s[:len(s)-1]
-			}
-
-			fileName := ""
-
+		if text, ok := resultText(r); ok {
+			fileName := fileOut
 			if len(output.Items) > 1 {
 				fileName = formatFileName(fileOut, i)
-			} else {
-				fileName = fileOut
 			}
 
 			file, _ := os.Create(fileName)
@@ -172,31 +283,11 @@ func OutputToFile(f *Formatter, output *perfops.RunOutput, fileOut string) {
 
 			w := bufio.NewWriter(file)
 
-			fmt.Fprintf(w, "Node%d, AS%d, %s, %s\n%s\n", n.ID, n.AsNumber, n.City, n.Country.Name, o)
-
-			err := w.Flush()
+			fmt.Fprintf(w, "Node%d, AS%d, %s, %s\n%s\n", n.ID, n.AsNumber, n.City, n.Country.Name, text)
 
-			if err != nil {
+			if err := w.Flush(); err != nil {
 				return
 			}
-
-		} else if r.Message != "NO DATA" {
-			fileName := ""
-
-			if len(output.Items) > 1 {
-				fileName = formatFileName(fileOut, i)
-			} else {
-				fileName = fileOut
-			}
-
-			file, _ := os.Create(fileName)
-			defer file.Close()
-
-			w := bufio.NewWriter(file)
-
-			fmt.Fprintf(w, "Node%d, AS%d, %s, %s\n%s\n", n.ID, n.AsNumber, n.City, n.Country.Name, r.Message)
-
-			w.Flush()
 		}
 		if !item.Result.IsFinished() {
 			f.Printf("%s\n", spinner)
@@ -207,6 +298,10 @@ func OutputToFile(f *Formatter, output *perfops.RunOutput, fileOut string) {
 
 // PrintOutput prints run items that have been data.
 func PrintOutput(f *Formatter, output *perfops.RunOutput) {
+	if !f.live {
+		printOutputAppend(f, output)
+		return
+	}
 	if f.printID {
 		f.Printf("Test ID: %v\n", output.ID)
 	}
@@ -227,24 +322,8 @@ func PrintOutput(f *Formatter, output *perfops.RunOutput) {
 	for _, item := range output.Items {
 		r := item.Result
 		n := r.Node
-		if item.Result.Message == "" {
-			o := r.Output
-			if o == "-2" {
-				o = "The command timed-out. It either took too long to execute or we could not connect to your target at all."
-			} else if a, ok := o.([]interface{}); ok {
-				sb := strings.Builder{}
-				for _, i := range a {
-					sb.WriteString(fmt.Sprintf("%s\n", i))
-				}
-				s := sb.String()
-				o = s[:len(s)-1]
-			}
-			if len(output.Items) > 1 {
-				fmt.Println("Greater Than One")
-			}
-			f.Printf("Node%d, AS%d, %s, %s\n%s\n", n.ID, n.AsNumber, n.City, n.Country.Name, o)
-		} else if r.Message != "NO DATA" {
-			f.Printf("Node%d, AS%d, %s, %s\n%s\n", n.ID, n.AsNumber, n.City, n.Country.Name, r.Message)
+		if text, ok := resultText(r); ok {
+			f.Printf("Node%d, AS%d, %s, %s\n%s\n", n.ID, n.AsNumber, n.City, n.Country.Name, text)
 		}
 		if !item.Result.IsFinished() {
 			f.Printf("%s\n", spinner)
@@ -253,6 +332,73 @@ func PrintOutput(f *Formatter, output *perfops.RunOutput) {
 	f.Flush(!output.IsFinished())
 }
 
+// printOutputAppend prints the run items that finished since the last
+// call without redrawing the earlier output.
+func printOutputAppend(f *Formatter, output *perfops.RunOutput) {
+	if f.printID && !f.idPrinted {
+		f.idPrinted = true
+		f.Printf("Test ID: %v\n", output.ID)
+	}
+	for _, item := range output.Items {
+		r := item.Result
+		if !r.IsFinished() || f.printed[item.ID] {
+			continue
+		}
+		f.printed[item.ID] = true
+		n := r.Node
+		if text, ok := resultText(r); ok {
+			f.Printf("Node%d, AS%d, %s, %s\n%s\n", n.ID, n.AsNumber, n.City, n.Country.Name, text)
+		}
+	}
+	f.Flush(false)
+}
+
+// PrintOutputView prints the results of a finished test in the order and
+// grouping of the view.
+func PrintOutputView(f *Formatter, testType string, output *perfops.RunOutput, v *View) {
+	if f.printID {
+		f.Printf("Test ID: %v\n", output.ID)
+	}
+	var b bytes.Buffer
+	PrintResults(&b, testType, RunResults(testType, output), v)
+	f.Printf("%s", b.String())
+	f.Flush(false)
+}
+
+// resultText returns the text shown for the result of a node, i.e., its
+// output or the reason it failed, and whether there is anything to show.
+func resultText(r *perfops.RunResult) (string, bool) {
+	status, reason := r.Status()
+	switch status {
+	case perfops.StatusTimeout, perfops.StatusError:
+		return reason, true
+	case perfops.StatusNoData:
+		return "", false
+	}
+	if a, ok := r.Output.([]interface{}); ok {
+		lines := make([]string, len(a))
+		for i, v := range a {
+			lines[i] = fmt.Sprintf("%s", v)
+		}
+		return strings.Join(lines, "\n"), true
+	}
+	return fmt.Sprintf("%s", r.Output), true
+}
+
+// PrintOutputFormat prints the output of a test in a structured output
+// format, v is its JSON output. name names the test in the KML output.
+func PrintOutputFormat(format, name string, v interface{}, results []*Result, nodes []*perfops.Node) error {
+	switch format {
+	case OutputGeoJSON:
+		return WriteGeoJSON(os.Stdout, results, nodes)
+	case OutputKML:
+		return WriteKML(os.Stdout, name, results, nodes)
+	case OutputMarkdown:
+		return WriteMarkdown(os.Stdout, name, results)
+	}
+	return PrintOutputJSON(v)
+}
+
 // PrintOutputJSON marshals the output into JSON and prints the JSON.
 func PrintOutputJSON(output interface{}) error {
 	b, err := json.Marshal(output)
@@ -269,6 +415,11 @@ func NewFormatter(printID bool) *Formatter {
 		printID: printID,
 		w:       uilive.New(),
 		s:       NewSpinner(),
+		live:    Progress(),
+		printed: map[string]bool{},
+	}
+	if !f.live {
+		f.w = &plainWriter{os.Stdout}
 	}
 	return f
 }
@@ -309,6 +460,12 @@ func (f *Formatter) Flush(limit bool) error {
 		return nil
 	}
 
+	if !f.live {
+		if _, err := f.w.Write(buf); err != nil {
+			return err
+		}
+		return f.w.Flush()
+	}
 	termStartOfRow(f.w)
 	if limit {
 		out := string(buf)
@@ -335,6 +492,13 @@ func (f *Formatter) Flush(limit bool) error {
 	return f.w.Flush()
 }
 
+// plainWriter writes the output as is, e.g., if stdout is not a terminal.
+type plainWriter struct {
+	io.Writer
+}
+
+func (w *plainWriter) Flush() error { return nil }
+
 // SetOutput sets the output and the error.
 func (g *RunOutputResult) SetOutput(o *perfops.RunOutput, err error) {
 	g.mu.Lock()
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"io"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/ProspectOne/perfops-cli/perfops"
@@ -65,7 +66,7 @@ func TestRunTest(t *testing.T) {
 	ctx := context.Background()
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
-			err := RunTest(ctx, "target", "location", []int{}, 1, 4, false, false, tc.run, tc.output)
+			err := RunTest(ctx, "mtr", "target", "location", []int{}, 1, 4, false, OutputText, &View{}, tc.run, tc.output)
 			if err != tc.err {
 				t.Fatalf("expected %v; got %v", tc.err, err)
 			}
@@ -73,6 +74,39 @@ func TestRunTest(t *testing.T) {
 	}
 }
 
+func TestFinishTest(t *testing.T) {
+	var o *perfops.RunOutput
+	if err := json.Unmarshal([]byte(`{"id":"abc","items":[{"id":"1","result":{"output":"10.5","finished":true,"node":{"id":5,"country":{"name":"Germany","continent":{"name":"Europe","iso":"EU"}}}}},{"id":"2","result":{"output":"20.5","finished":true,"node":{"id":7,"country":{"name":"France","continent":{"name":"Europe","iso":"EU"}}}}}],"finished":true,"creditsWithdrawn":4}`), &o); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var recorded []int
+	view := &View{Aggregate: true, Credits: &CreditAccount{Record: func(testID perfops.TestID, credits, nodes int) {
+		recorded = append(recorded, credits, nodes)
+	}}}
+	j := NewRunJSON("ping", o)
+	err := FinishTest(&FinishedTest{
+		ID:               "abc",
+		Type:             "ping",
+		Target:           "example.com",
+		CreditsWithdrawn: o.CreditsWithdrawn,
+		Nodes:            len(o.NodeIDs()),
+		Results:          RunResults("ping", o),
+		JSON:             j,
+	}, OutputJSON, view)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if exp := []int{4, 2}; !reflect.DeepEqual(recorded, exp) {
+		t.Fatalf("expected %v credits and nodes recorded; got %v", exp, recorded)
+	}
+	if j.CreditsUsed == nil || *j.CreditsUsed != 4 {
+		t.Fatalf("expected 4 credits used; got %v", j.CreditsUsed)
+	}
+	if j.Aggregates == nil || len(j.Aggregates.Continents) != 1 {
+		t.Fatalf("expected aggregates by continent; got %+v", j.Aggregates)
+	}
+}
+
 func TestPrintOutput(t *testing.T) {
 	testCases := map[string]struct {
 		output func() *perfops.RunOutput
@@ -102,6 +136,14 @@ func TestPrintOutput(t *testing.T) {
 			},
 			"\x1b[200DNode103, AS197328, Istanbul, Turkey\nheader\n  1 row\n 10 row\n",
 		},
+		"error and no data": {
+			func() *perfops.RunOutput {
+				var o *perfops.RunOutput
+				json.Unmarshal([]byte(`{"id":"706fc55e3377104da01f05569e35a30b","items":[{"id":"1","result":{"message":"Unknown host","finished":"true","node":{"id":27,"as_number":12345,"country":{"name":"Hong Kong"},"city":"Hong Kong"}}},{"id":"2","result":{"message":"NO DATA","finished":"true","node":{"id":5,"as_number":3320,"country":{"name":"Germany"},"city":"Frankfurt"}}}],"finished":true}`), &o)
+				return o
+			},
+			"\x1b[200DNode27, AS12345, Hong Kong, Hong Kong\nUnknown host\n",
+		},
 	}
 
 	var b bytes.Buffer
@@ -176,6 +218,8 @@ func newTestFormatter(w io.Writer, printID bool) *Formatter {
 		printID: printID,
 		w:       &testTerminalWriter{w},
 		s:       NewSpinner(),
+		live:    true,
+		printed: map[string]bool{},
 	}
 	return f
 }
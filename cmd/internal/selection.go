@@ -0,0 +1,183 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+// NodeSelection selects the nodes of a run client-side.
+type NodeSelection struct {
+	Filter perfops.NodeFilter
+	// MaxPerCountry is the maximum number of nodes per country, if
+	// positive.
+	MaxPerCountry int
+}
+
+// IsEmpty returns a value indicating whether the selection accepts all
+// nodes.
+func (s *NodeSelection) IsEmpty() bool {
+	f := &s.Filter
+	return len(f.Countries) == 0 && len(f.Continents) == 0 && len(f.Cities) == 0 &&
+		len(f.ASNs) == 0 && !f.IPv6 && len(f.ExcludeNodes) == 0 &&
+		len(f.ExcludeCountries) == 0 && s.MaxPerCountry <= 0
+}
+
+// Select resolves the selection into node IDs. Explicit node IDs are
+// filtered. Otherwise up to limit nodes are picked from the location, or
+// from each location of the quotas, in the order of nodes.
+func (s *NodeSelection) Select(nodes []*perfops.Node, location string, quotas perfops.LocationQuotas, nodeIDs []int, limit int) (perfops.NodeIDs, error) {
+	perCountry := map[string]int{}
+	picked := map[int]bool{}
+	var res perfops.NodeIDs
+	pick := func(n *perfops.Node) bool {
+		if picked[n.ID] || !s.Filter.Match(n) {
+			return false
+		}
+		c := strings.ToLower(n.CountryName())
+		if s.MaxPerCountry > 0 && perCountry[c] >= s.MaxPerCountry {
+			return false
+		}
+		perCountry[c]++
+		picked[n.ID] = true
+		res = append(res, n.ID)
+		return true
+	}
+
+	if len(nodeIDs) > 0 {
+		byID := map[int]*perfops.Node{}
+		for _, n := range nodes {
+			byID[n.ID] = n
+		}
+		for _, id := range nodeIDs {
+			n, ok := byID[id]
+			if !ok {
+				// Nothing is known about the node but its ID.
+				n = &perfops.Node{ID: id}
+			}
+			pick(n)
+		}
+	} else {
+		if len(quotas) == 0 {
+			quotas = perfops.LocationQuotas{{Location: location, Limit: limit}}
+		}
+		for _, q := range quotas {
+			max := q.Limit
+			if max == 0 {
+				max = limit
+			}
+			candidates := NodesInLocation(nodes, q.Location)
+			if len(candidates) == 0 {
+				return nil, fmt.Errorf("no nodes known in '%s'", q.Location)
+			}
+			for i := 0; i < len(candidates) && max > 0; i++ {
+				if pick(candidates[i]) {
+					max--
+				}
+			}
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no nodes match the node selection")
+	}
+	return res, nil
+}
+
+// SelectBalanced picks up to limit nodes spread evenly over the
+// continents and, within each continent, over the countries. Nodes are
+// taken in the order of nodes.
+func (s *NodeSelection) SelectBalanced(nodes []*perfops.Node, limit int) (perfops.NodeIDs, error) {
+	type continent struct {
+		countries [][]*perfops.Node
+		next      int
+	}
+	var (
+		continents []*continent
+		byName     = map[string]*continent{}
+		countries  = map[string]int{}
+	)
+	for _, n := range nodes {
+		if !s.Filter.Match(n) {
+			continue
+		}
+		name := strings.ToLower(n.ContinentName())
+		c := byName[name]
+		if c == nil {
+			c = &continent{}
+			byName[name] = c
+			continents = append(continents, c)
+		}
+		key := name + "/" + strings.ToLower(n.CountryName())
+		i, ok := countries[key]
+		if !ok {
+			i = len(c.countries)
+			countries[key] = i
+			c.countries = append(c.countries, nil)
+		}
+		if s.MaxPerCountry <= 0 || len(c.countries[i]) < s.MaxPerCountry {
+			c.countries[i] = append(c.countries[i], n)
+		}
+	}
+
+	var res perfops.NodeIDs
+	for picked := true; picked && len(res) < limit; {
+		picked = false
+		for _, c := range continents {
+			// Take the next node of the next country with nodes left.
+			for tries := 0; tries < len(c.countries) && len(res) < limit; tries++ {
+				i := c.next % len(c.countries)
+				c.next++
+				if len(c.countries[i]) > 0 {
+					res = append(res, c.countries[i][0].ID)
+					c.countries[i] = c.countries[i][1:]
+					picked = true
+					break
+				}
+			}
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no nodes match the node selection")
+	}
+	return res, nil
+}
+
+// NodesInLocation returns the nodes in a continent, region, country or
+// city given by name or ISO code. All nodes are in the empty location.
+func NodesInLocation(nodes []*perfops.Node, loc string) []*perfops.Node {
+	loc = strings.TrimSpace(loc)
+	if loc == "" {
+		return nodes
+	}
+	var res []*perfops.Node
+	for _, n := range nodes {
+		names := []string{n.City, n.SubRegion, n.CountryName(), n.ContinentName()}
+		if c := n.Country; c != nil {
+			names = append(names, c.ISO)
+			if c.Continent != nil {
+				names = append(names, c.Continent.ISO)
+			}
+		}
+		for _, name := range names {
+			if name != "" && strings.EqualFold(name, loc) {
+				res = append(res, n)
+				break
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,114 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func testSelectionNodes() []*perfops.Node {
+	de := &perfops.Country{Name: "Germany", ISO: "DE", Continent: &perfops.Continent{Name: "Europe", ISO: "EU"}}
+	fr := &perfops.Country{Name: "France", ISO: "FR", Continent: &perfops.Continent{Name: "Europe", ISO: "EU"}}
+	hk := &perfops.Country{Name: "Hong Kong", ISO: "HK", Continent: &perfops.Continent{Name: "Asia", ISO: "AS"}}
+	return []*perfops.Node{
+		{ID: 1, AsNumber: 3320, City: "Frankfurt", SubRegion: "Western Europe", Country: de, IPv6: true},
+		{ID: 2, AsNumber: 24940, City: "Nuremberg", SubRegion: "Western Europe", Country: de},
+		{ID: 3, AsNumber: 3215, City: "Paris", SubRegion: "Western Europe", Country: fr, IPv6: true},
+		{ID: 4, AsNumber: 9304, City: "Hong Kong", SubRegion: "Eastern Asia", Country: hk},
+		{ID: 5, AsNumber: 3320, City: "Berlin", SubRegion: "Western Europe", Country: de},
+	}
+}
+
+func TestNodeSelection(t *testing.T) {
+	testCases := map[string]struct {
+		sel      NodeSelection
+		location string
+		quotas   perfops.LocationQuotas
+		nodeIDs  []int
+		limit    int
+		exp      perfops.NodeIDs
+		expErr   string
+	}{
+		"Limit":           {NodeSelection{}, "", nil, nil, 2, perfops.NodeIDs{1, 2}, ""},
+		"Location":        {NodeSelection{}, "france", nil, nil, 5, perfops.NodeIDs{3}, ""},
+		"Continent ISO":   {NodeSelection{}, "AS", nil, nil, 5, perfops.NodeIDs{4}, ""},
+		"Exclude node":    {NodeSelection{Filter: perfops.NodeFilter{ExcludeNodes: []int{1}}}, "Germany", nil, nil, 1, perfops.NodeIDs{2}, ""},
+		"Exclude country": {NodeSelection{Filter: perfops.NodeFilter{ExcludeCountries: []string{"DE"}}}, "Europe", nil, nil, 5, perfops.NodeIDs{3}, ""},
+		"ASN":             {NodeSelection{Filter: perfops.NodeFilter{ASNs: []int{3320}}}, "", nil, nil, 5, perfops.NodeIDs{1, 5}, ""},
+		"IPv6":            {NodeSelection{Filter: perfops.NodeFilter{IPv6: true}}, "", nil, nil, 5, perfops.NodeIDs{1, 3}, ""},
+		"Max per country": {NodeSelection{MaxPerCountry: 1}, "Europe", nil, nil, 5, perfops.NodeIDs{1, 3}, ""},
+		"Quotas":          {NodeSelection{MaxPerCountry: 2}, "", perfops.LocationQuotas{{Location: "Europe", Limit: 3}, {Location: "Asia"}}, nil, 1, perfops.NodeIDs{1, 2, 3, 4}, ""},
+		"Node IDs":        {NodeSelection{Filter: perfops.NodeFilter{ExcludeCountries: []string{"France"}}}, "", nil, []int{3, 4, 99}, 1, perfops.NodeIDs{4, 99}, ""},
+		"Unknown":         {NodeSelection{}, "Atlantis", nil, nil, 1, nil, "no nodes known in 'Atlantis'"},
+		"Nothing":         {NodeSelection{Filter: perfops.NodeFilter{ASNs: []int{1}}}, "", nil, nil, 1, nil, "no nodes match the node selection"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.sel.Select(testSelectionNodes(), tc.location, tc.quotas, tc.nodeIDs, tc.limit)
+			if tc.expErr != "" {
+				if err == nil || err.Error() != tc.expErr {
+					t.Fatalf("expected error %v; got %v", tc.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.exp) {
+				t.Fatalf("expected %v; got %v", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestNodeSelectionIsEmpty(t *testing.T) {
+	if sel := (&NodeSelection{}); !sel.IsEmpty() {
+		t.Fatal("expected empty selection")
+	}
+	if sel := (&NodeSelection{MaxPerCountry: 1}); sel.IsEmpty() {
+		t.Fatal("expected non-empty selection")
+	}
+}
+
+func TestSelectBalanced(t *testing.T) {
+	testCases := map[string]struct {
+		sel   NodeSelection
+		limit int
+		exp   perfops.NodeIDs
+	}{
+		"Continents first": {NodeSelection{}, 2, perfops.NodeIDs{1, 4}},
+		"Countries next":   {NodeSelection{}, 3, perfops.NodeIDs{1, 4, 3}},
+		"All":              {NodeSelection{}, 10, perfops.NodeIDs{1, 4, 3, 2, 5}},
+		"Max per country":  {NodeSelection{MaxPerCountry: 1}, 10, perfops.NodeIDs{1, 4, 3}},
+		"Filter":           {NodeSelection{Filter: perfops.NodeFilter{IPv6: true}}, 10, perfops.NodeIDs{1, 3}},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.sel.SelectBalanced(testSelectionNodes(), tc.limit)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.exp) {
+				t.Fatalf("expected %v; got %v", tc.exp, got)
+			}
+		})
+	}
+	sel := &NodeSelection{Filter: perfops.NodeFilter{ASNs: []int{1}}}
+	if _, err := sel.SelectBalanced(testSelectionNodes(), 5); err == nil {
+		t.Fatal("expected an error if no node matches")
+	}
+}
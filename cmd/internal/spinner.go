@@ -46,7 +46,7 @@ func NewSpinner() *Spinner {
 
 // Start will start the indicator.
 func (s *Spinner) Start() {
-	if s.active {
+	if s.active || !Progress() {
 		return
 	}
 	s.active = true
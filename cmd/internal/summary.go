@@ -0,0 +1,249 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+type (
+	// Stats represents statistics of the values of a metric.
+	Stats struct {
+		Count  int     `json:"count"`
+		Min    float64 `json:"min"`
+		Mean   float64 `json:"mean"`
+		Median float64 `json:"median"`
+		P95    float64 `json:"p95"`
+		Max    float64 `json:"max"`
+	}
+
+	// NodeValue represents the value of a metric of a node.
+	NodeValue struct {
+		Node  *perfops.Node `json:"node"`
+		Value float64       `json:"value"`
+	}
+
+	// GroupSummary summarizes the results of a group of nodes, e.g., of a
+	// continent.
+	GroupSummary struct {
+		Name   string `json:"name"`
+		Nodes  int    `json:"nodes"`
+		OK     int    `json:"ok"`
+		Failed int    `json:"failed"`
+		// FailureRate is the percentage of failed nodes.
+		FailureRate float64 `json:"failure_rate"`
+		Stats       *Stats  `json:"stats,omitempty"`
+	}
+
+	// Summary summarizes the results of a multi-node test.
+	Summary struct {
+		Nodes      int             `json:"nodes"`
+		OK         int             `json:"ok"`
+		Failed     int             `json:"failed"`
+		TimedOut   int             `json:"timed_out"`
+		NoData     int             `json:"no_data"`
+		Pending    int             `json:"pending,omitempty"`
+		Metric     string          `json:"metric,omitempty"`
+		Stats      *Stats          `json:"stats,omitempty"`
+		Best       *NodeValue      `json:"best,omitempty"`
+		Worst      *NodeValue      `json:"worst,omitempty"`
+		Continents []*GroupSummary `json:"continents,omitempty"`
+	}
+)
+
+// metricLabels are the names of the metrics shown to the user.
+var metricLabels = map[string]string{
+	MetricRTT:     "RTT (ms)",
+	MetricLoss:    "Loss (%)",
+	MetricTTFB:    "TTFB (ms)",
+	MetricTotal:   "Total time (ms)",
+	MetricResolve: "Resolve time (ms)",
+}
+
+// NewStats returns the statistics of the values or nil if there are none.
+func NewStats(values []float64) *Stats {
+	if len(values) == 0 {
+		return nil
+	}
+	v := append([]float64{}, values...)
+	sort.Float64s(v)
+	var sum float64
+	for _, x := range v {
+		sum += x
+	}
+	return &Stats{
+		Count:  len(v),
+		Min:    v[0],
+		Mean:   sum / float64(len(v)),
+		Median: percentile(v, 50),
+		P95:    percentile(v, 95),
+		Max:    v[len(v)-1],
+	}
+}
+
+// percentile returns the p-th percentile of the sorted values,
+// interpolating between the closest ranks.
+func percentile(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	if lo+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lo] + (rank-float64(lo))*(sorted[lo+1]-sorted[lo])
+}
+
+// Summarize returns the summary of the results. Lower values of the
+// metric are better.
+func Summarize(results []*Result, metric string) *Summary {
+	s := &Summary{Nodes: len(results), Metric: metric}
+	var values []float64
+	for _, r := range results {
+		switch r.Status {
+		case perfops.StatusOK:
+			s.OK++
+		case perfops.StatusError:
+			s.Failed++
+		case perfops.StatusTimeout:
+			s.TimedOut++
+		case perfops.StatusNoData:
+			s.NoData++
+		default:
+			s.Pending++
+		}
+		v, ok := r.Metric(metric)
+		if !ok {
+			continue
+		}
+		values = append(values, v)
+		if s.Best == nil || v < s.Best.Value {
+			s.Best = &NodeValue{Node: r.Node, Value: v}
+		}
+		if s.Worst == nil || v > s.Worst.Value {
+			s.Worst = &NodeValue{Node: r.Node, Value: v}
+		}
+	}
+	s.Stats = NewStats(values)
+	s.Continents = groupSummaries(results, metric, "continent")
+	return s
+}
+
+// groupSummaries summarizes the results grouped by the node attribute
+// key, e.g., continent. The groups are ordered by name or AS number.
+func groupSummaries(results []*Result, metric, key string) []*GroupSummary {
+	groups := map[string]*GroupSummary{}
+	values := map[string][]float64{}
+	asns := map[string]int{}
+	var res []*GroupSummary
+	for _, r := range results {
+		name := groupName(r, key)
+		g, ok := groups[name]
+		if !ok {
+			g = &GroupSummary{Name: name}
+			groups[name] = g
+			asns[name] = nodeASN(r)
+			res = append(res, g)
+		}
+		g.Nodes++
+		if r.Status == perfops.StatusOK {
+			g.OK++
+		} else if r.Status.IsFailed() {
+			g.Failed++
+		}
+		if v, ok := r.Metric(metric); ok {
+			values[name] = append(values[name], v)
+		}
+	}
+	for _, g := range res {
+		g.FailureRate = 100 * float64(g.Failed) / float64(g.Nodes)
+		g.Stats = NewStats(values[g.Name])
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if key == "asn" {
+			return asns[res[i].Name] < asns[res[j].Name]
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// PrintResultsSummary writes the summary of the results as text to w if
+// the test ran on more than one node.
+func PrintResultsSummary(w io.Writer, testType string, results []*Result) error {
+	if len(results) < 2 {
+		return nil
+	}
+	return PrintSummary(w, Summarize(results, PrimaryMetric(testType)))
+}
+
+// PrintSummary writes the summary as text to w.
+func PrintSummary(w io.Writer, s *Summary) error {
+	fmt.Fprintf(w, "\n--- Summary ---\n%d nodes: %d ok, %d failed, %d timed out", s.Nodes, s.OK, s.Failed, s.TimedOut)
+	if s.NoData > 0 {
+		fmt.Fprintf(w, ", %d without data", s.NoData)
+	}
+	if s.Pending > 0 {
+		fmt.Fprintf(w, ", %d pending", s.Pending)
+	}
+	fmt.Fprintln(w)
+	if st := s.Stats; st != nil {
+		fmt.Fprintf(w, "%s: min %s, median %s, p95 %s, max %s\n", metricLabels[s.Metric],
+			fmtValue(st.Min), fmtValue(st.Median), fmtValue(st.P95), fmtValue(st.Max))
+	}
+	if s.Best != nil && s.Worst != nil {
+		fmt.Fprintf(w, "Best:  %s (%s)\n", nodeLocation(s.Best.Node), fmtValue(s.Best.Value))
+		fmt.Fprintf(w, "Worst: %s (%s)\n", nodeLocation(s.Worst.Node), fmtValue(s.Worst.Value))
+	}
+	if len(s.Continents) < 2 {
+		return nil
+	}
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	header := "CONTINENT\tNODES\tOK\tFAILED"
+	if s.Stats != nil {
+		header += "\tMEDIAN\tP95"
+	}
+	fmt.Fprintln(tw, header)
+	for _, g := range s.Continents {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d", g.Name, g.Nodes, g.OK, g.Failed)
+		if s.Stats != nil {
+			median, p95 := "-", "-"
+			if g.Stats != nil {
+				median, p95 = fmtValue(g.Stats.Median), fmtValue(g.Stats.P95)
+			}
+			fmt.Fprintf(tw, "\t%s\t%s", median, p95)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// nodeLocation returns the node ID and its location, e.g.,
+// "Node5, AS3320, Frankfurt, Germany".
+func nodeLocation(n *perfops.Node) string {
+	if n == nil {
+		return "unknown node"
+	}
+	return fmt.Sprintf("Node%d, AS%d, %s, %s", n.ID, n.AsNumber, n.City, n.CountryName())
+}
+
+func fmtValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,76 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func TestNewStats(t *testing.T) {
+	if NewStats(nil) != nil {
+		t.Fatal("expected no stats for no values")
+	}
+	got := NewStats([]float64{4, 1, 3, 2})
+	exp := &Stats{Count: 4, Min: 1, Mean: 2.5, Median: 2.5, P95: 3.85, Max: 4}
+	if got.P95 < 3.8499 || got.P95 > 3.8501 {
+		t.Fatalf("expected p95 %v; got %v", exp.P95, got.P95)
+	}
+	got.P95 = exp.P95
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("expected %v; got %v", exp, got)
+	}
+}
+
+func testResults() []*Result {
+	de := &perfops.Country{Name: "Germany", Continent: &perfops.Continent{Name: "Europe"}}
+	hk := &perfops.Country{Name: "Hong Kong", Continent: &perfops.Continent{Name: "Asia"}}
+	return []*Result{
+		{Node: &perfops.Node{ID: 5, AsNumber: 3320, City: "Frankfurt", Country: de}, Status: perfops.StatusOK, Metrics: map[string]float64{MetricRTT: 3}},
+		{Node: &perfops.Node{ID: 12, AsNumber: 24940, City: "Nuremberg", Country: de}, Status: perfops.StatusOK, Metrics: map[string]float64{MetricRTT: 5}},
+		{Node: &perfops.Node{ID: 27, AsNumber: 9304, City: "Hong Kong", Country: hk}, Status: perfops.StatusOK, Metrics: map[string]float64{MetricRTT: 180}},
+		{Node: &perfops.Node{ID: 28, AsNumber: 9304, City: "Hong Kong", Country: hk}, Status: perfops.StatusTimeout},
+		{Node: &perfops.Node{ID: 30, AsNumber: 3320, City: "Berlin", Country: de}, Status: perfops.StatusError},
+	}
+}
+
+func TestPrintSummary(t *testing.T) {
+	var b bytes.Buffer
+	if err := PrintResultsSummary(&b, "ping", testResults()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	exp := `
+--- Summary ---
+5 nodes: 3 ok, 1 failed, 1 timed out
+RTT (ms): min 3.00, median 5.00, p95 162.50, max 180.00
+Best:  Node5, AS3320, Frankfurt, Germany (3.00)
+Worst: Node27, AS9304, Hong Kong, Hong Kong (180.00)
+
+CONTINENT  NODES  OK  FAILED  MEDIAN  P95
+Asia       2      1   1       180.00  180.00
+Europe     3      2   1       4.00    4.90
+`
+	if got := b.String(); got != exp {
+		t.Fatalf("expected\n%s\ngot\n%s", exp, got)
+	}
+
+	b.Reset()
+	PrintResultsSummary(&b, "ping", testResults()[:1])
+	if b.Len() != 0 {
+		t.Fatalf("expected no summary for a single node; got %q", b.String())
+	}
+}
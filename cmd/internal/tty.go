@@ -0,0 +1,74 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mattn/go-isatty"
+)
+
+// Color modes of the output.
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// ColorModes lists the color modes of the output.
+var ColorModes = []string{ColorAuto, ColorAlways, ColorNever}
+
+var (
+	// progress is set if spinners and live redraws are shown.
+	progress = isTerminal(os.Stdout) && isTerminal(os.Stderr)
+	// quiet is set if only the final results are printed.
+	quiet bool
+	// color is set if the output is colored.
+	color = isTerminal(os.Stdout)
+)
+
+// SetOutputMode configures the terminal output. Spinners and live redraws
+// are only shown if stdout and stderr are terminals, unless noProgress or
+// quietMode turn them off. In quiet mode only the final results are
+// printed. Colors are used on terminals with the auto color mode.
+func SetOutputMode(noProgress, quietMode bool, colorMode string) error {
+	switch colorMode {
+	case ColorAuto, "":
+		color = isTerminal(os.Stdout)
+	case ColorAlways:
+		color = true
+	case ColorNever:
+		color = false
+	default:
+		return fmt.Errorf("unsupported color mode '%s'", colorMode)
+	}
+	quiet = quietMode
+	progress = isTerminal(os.Stdout) && isTerminal(os.Stderr) && !noProgress && !quietMode
+	return nil
+}
+
+// Progress returns true if spinners and live redraws are shown.
+func Progress() bool {
+	return progress
+}
+
+// Quiet returns true if only the final results are printed.
+func Quiet() bool {
+	return quiet
+}
+
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
@@ -0,0 +1,76 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func TestSetOutputMode(t *testing.T) {
+	defer SetOutputMode(false, false, ColorAuto)
+
+	if err := SetOutputMode(false, false, "sometimes"); err == nil {
+		t.Fatal("expected error; got nil")
+	}
+	if err := SetOutputMode(true, false, ColorAlways); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if Progress() || Quiet() {
+		t.Fatalf("expected no progress and not quiet; got %v, %v", Progress(), Quiet())
+	}
+	if got, exp := colorize(colorRed, "x"), "\x1b[31mx\x1b[0m"; got != exp {
+		t.Fatalf("expected %q; got %q", exp, got)
+	}
+	if err := SetOutputMode(false, true, ColorNever); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if Progress() || !Quiet() {
+		t.Fatalf("expected no progress and quiet; got %v, %v", Progress(), Quiet())
+	}
+	if got, exp := colorize(colorRed, "x"), "x"; got != exp {
+		t.Fatalf("expected %q; got %q", exp, got)
+	}
+	if got, exp := mapSymbol(bucketBad), "■"; got != exp {
+		t.Fatalf("expected %q; got %q", exp, got)
+	}
+}
+
+func TestPrintOutputAppend(t *testing.T) {
+	var b bytes.Buffer
+	f := newTestFormatter(&b, true)
+	f.live = false
+	outputs := []string{
+		`{"id":"706fc55e","items":[{"id":"1","result":{"output":"121","finished":true,"node":{"id":27,"as_number":9304,"country":{"name":"Hong Kong"},"city":"Hong Kong"}}},{"id":"2","result":{"node":{"id":5,"as_number":3320,"country":{"name":"Germany"},"city":"Frankfurt"}}}],"finished":false}`,
+		`{"id":"706fc55e","items":[{"id":"1","result":{"output":"121","finished":true,"node":{"id":27,"as_number":9304,"country":{"name":"Hong Kong"},"city":"Hong Kong"}}},{"id":"2","result":{"output":"3","finished":true,"node":{"id":5,"as_number":3320,"country":{"name":"Germany"},"city":"Frankfurt"}}}],"finished":true}`,
+	}
+	exp := []string{
+		"Test ID: 706fc55e\nNode27, AS9304, Hong Kong, Hong Kong\n121\n",
+		"Node5, AS3320, Frankfurt, Germany\n3\n",
+	}
+	for i, s := range outputs {
+		var o *perfops.RunOutput
+		if err := json.Unmarshal([]byte(s), &o); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		b.Reset()
+		PrintOutput(f, o)
+		if got := b.String(); got != exp[i] {
+			t.Fatalf("expected %q; got %q", exp[i], got)
+		}
+	}
+}
@@ -0,0 +1,593 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+type (
+	// Dashboard is the state of the full-screen dashboard of a running
+	// test.
+	Dashboard struct {
+		Title    string
+		TestType string
+
+		results  []*Result
+		finished bool
+		err      error
+
+		selID     string
+		offset    int
+		detailOff int
+		sortIdx   int
+		reverse   bool
+		filter    string
+		filtering bool
+	}
+
+	keyEvent struct {
+		key key
+		r   rune
+	}
+
+	key int
+)
+
+const (
+	keyRune key = iota
+	keyUp
+	keyDown
+	keyPgUp
+	keyPgDn
+	keyHome
+	keyEnd
+	keyEnter
+	keyEsc
+	keyBackspace
+	keyCtrlC
+)
+
+const (
+	// dashboardPoll is the interval the output of the test is retrieved
+	// in and the screen is redrawn.
+	dashboardPoll = 500 * time.Millisecond
+
+	ansiHome       = "\x1b[H"
+	ansiClearLine  = "\x1b[K"
+	ansiClearBelow = "\x1b[J"
+	ansiReverse    = "\x1b[7m"
+	ansiBold       = "\x1b[1m"
+	ansiReset      = "\x1b[0m"
+	ansiAltScreen  = "\x1b[?1049h\x1b[?25l"
+	ansiMainScreen = "\x1b[?25h\x1b[?1049l"
+)
+
+// errNoTerminal is returned if the dashboard cannot be shown.
+var errNoTerminal = errors.New("--tui requires an interactive terminal")
+
+// NewDashboard returns a dashboard of a test.
+func NewDashboard(title, testType string) *Dashboard {
+	return &Dashboard{Title: title, TestType: testType}
+}
+
+// Update replaces the results shown.
+func (d *Dashboard) Update(results []*Result, finished bool, err error) {
+	d.results, d.finished, d.err = results, finished, err
+}
+
+// sortKeys returns the keys the results can be sorted by, the first one
+// keeps the order of the API.
+func (d *Dashboard) sortKeys() []string {
+	keys := []string{""}
+	if m := PrimaryMetric(d.TestType); m != "" {
+		keys = append(keys, m)
+	}
+	return append(keys, "status", "city", "country", "asn")
+}
+
+// rows returns the results matching the filter in the selected order.
+func (d *Dashboard) rows() []*Result {
+	var res []*Result
+	filter := strings.ToLower(d.filter)
+	for _, r := range d.results {
+		if filter == "" || strings.Contains(strings.ToLower(nodeLocation(r.Node)+" "+string(r.Status)), filter) {
+			res = append(res, r)
+		}
+	}
+	switch key := d.sortKeys()[d.sortIdx]; key {
+	case "":
+	case "status":
+		sort.SliceStable(res, func(i, j int) bool { return res[i].Status < res[j].Status })
+	default:
+		sortResults(res, key)
+	}
+	if d.reverse {
+		for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+			res[i], res[j] = res[j], res[i]
+		}
+	}
+	return res
+}
+
+// selected returns the index of the selected row.
+func (d *Dashboard) selected(rows []*Result) int {
+	for i, r := range rows {
+		if r.ID == d.selID {
+			return i
+		}
+	}
+	return 0
+}
+
+// Handle applies a key press and returns true if the user quits.
+func (d *Dashboard) Handle(ev keyEvent, height int) bool {
+	if ev.key == keyCtrlC {
+		return true
+	}
+	if d.filtering {
+		switch ev.key {
+		case keyRune:
+			d.filter += string(ev.r)
+		case keyBackspace:
+			if n := len(d.filter); n > 0 {
+				_, size := utf8.DecodeLastRuneInString(d.filter)
+				d.filter = d.filter[:n-size]
+			}
+		case keyEnter:
+			d.filtering = false
+		case keyEsc:
+			d.filter, d.filtering = "", false
+		}
+		return false
+	}
+	rows := d.rows()
+	sel := d.selected(rows)
+	page := d.tableHeight(height)
+	switch ev.key {
+	case keyUp:
+		sel--
+	case keyDown:
+		sel++
+	case keyPgUp:
+		sel -= page
+	case keyPgDn:
+		sel += page
+	case keyHome:
+		sel = 0
+	case keyEnd:
+		sel = len(rows) - 1
+	case keyEsc:
+		d.filter = ""
+	case keyRune:
+		switch ev.r {
+		case 'q':
+			return true
+		case 'k':
+			sel--
+		case 'j':
+			sel++
+		case 'g':
+			sel = 0
+		case 'G':
+			sel = len(rows) - 1
+		case 's':
+			d.sortIdx = (d.sortIdx + 1) % len(d.sortKeys())
+		case 'r':
+			d.reverse = !d.reverse
+		case '/':
+			d.filtering = true
+		case '[':
+			if d.detailOff > 0 {
+				d.detailOff--
+			}
+		case ']':
+			d.detailOff++
+		}
+	}
+	if len(rows) > 0 {
+		sel = clamp(sel, len(rows)-1)
+		if rows[sel].ID != d.selID {
+			d.selID, d.detailOff = rows[sel].ID, 0
+		}
+	}
+	return false
+}
+
+// tableHeight returns the number of table rows shown given the height of
+// the screen. The rest of the screen shows the title, the summary, the
+// headers, the details of the selected node and the help.
+func (d *Dashboard) tableHeight(height int) int {
+	h := (height - 5) / 2
+	if h < 1 {
+		return 1
+	}
+	return h
+}
+
+// Render writes the dashboard to w as a full screen frame of width
+// columns and height rows.
+func (d *Dashboard) Render(w io.Writer, width, height int) error {
+	rows := d.rows()
+	sel := d.selected(rows)
+	tableH := d.tableHeight(height)
+	if sel < d.offset {
+		d.offset = sel
+	} else if sel >= d.offset+tableH {
+		d.offset = sel - tableH + 1
+	}
+	metric := PrimaryMetric(d.TestType)
+
+	var lines []string
+	state := "running"
+	if d.finished {
+		state = "finished"
+	}
+	if d.err != nil {
+		state = "error: " + d.err.Error()
+	}
+	lines = append(lines, ansiBold+fmt.Sprintf("%s [%s]", d.Title, state)+ansiReset)
+	lines = append(lines, dashboardSummary(Summarize(d.results, metric)))
+
+	metrics := ResultMetrics(d.results)
+	header := fmt.Sprintf("%-7s %-8s %-18s %-16s %-8s", "NODE", "ASN", "CITY", "COUNTRY", "STATUS")
+	for _, m := range metrics {
+		header += fmt.Sprintf(" %9s", strings.ToUpper(m))
+	}
+	lines = append(lines, ansiBold+header+ansiReset)
+	for i := d.offset; i < d.offset+tableH; i++ {
+		if i >= len(rows) {
+			lines = append(lines, "")
+			continue
+		}
+		line := dashboardRow(rows[i], metrics)
+		if i == sel {
+			line = ansiReverse + pad(line, width) + ansiReset
+		}
+		lines = append(lines, line)
+	}
+
+	detail := []string{}
+	title := "no node selected"
+	if len(rows) > 0 {
+		r := rows[sel]
+		title = fmt.Sprintf("%s (%s)", nodeLocation(r.Node), r.Status)
+		text := r.Text
+		if r.Reason != "" && r.Status != perfops.StatusOK {
+			text = r.Reason
+		}
+		detail = strings.Split(strings.TrimRight(text, "\n"), "\n")
+	}
+	lines = append(lines, ansiBold+"── "+title+" "+strings.Repeat("─", max(0, width-utf8.RuneCountInString(title)-4))+ansiReset)
+	detailH := height - len(lines) - 1
+	if d.detailOff > len(detail)-1 {
+		d.detailOff = max(0, len(detail)-1)
+	}
+	for i := d.detailOff; i < d.detailOff+detailH; i++ {
+		if i < len(detail) {
+			lines = append(lines, detail[i])
+		} else {
+			lines = append(lines, "")
+		}
+	}
+
+	sortKey := d.sortKeys()[d.sortIdx]
+	if sortKey == "" {
+		sortKey = "none"
+	}
+	if d.reverse {
+		sortKey += " (reversed)"
+	}
+	help := fmt.Sprintf("↑/↓ select  PgUp/PgDn page  [/] scroll details  s sort: %s  r reverse  / filter  q quit", sortKey)
+	if d.filtering || d.filter != "" {
+		help = fmt.Sprintf("Filter: %s", d.filter)
+		if d.filtering {
+			help += "_  (Enter to apply, Esc to clear)"
+		}
+	}
+	lines = append(lines, ansiReverse+pad(help, width)+ansiReset)
+
+	var b strings.Builder
+	b.WriteString(ansiHome)
+	for i, l := range lines {
+		if i >= height {
+			break
+		}
+		b.WriteString(truncate(l, width))
+		b.WriteString(ansiClearLine)
+		if i < height-1 && i < len(lines)-1 {
+			b.WriteString("\r\n")
+		}
+	}
+	b.WriteString(ansiClearBelow)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dashboardSummary returns the counts and stats of the summary in a line.
+func dashboardSummary(s *Summary) string {
+	line := fmt.Sprintf("%d nodes: %d ok, %d failed, %d timed out, %d pending", s.Nodes, s.OK, s.Failed, s.TimedOut, s.Pending)
+	if st := s.Stats; st != nil {
+		line += fmt.Sprintf(" | %s: min %s, median %s, p95 %s, max %s", metricLabels[s.Metric],
+			fmtValue(st.Min), fmtValue(st.Median), fmtValue(st.P95), fmtValue(st.Max))
+	}
+	return line
+}
+
+// dashboardRow returns the table row of a result.
+func dashboardRow(r *Result, metrics []string) string {
+	n := r.Node
+	if n == nil {
+		n = &perfops.Node{}
+	}
+	line := fmt.Sprintf("%-7d %-8s %-18s %-16s %-8s", n.ID, fmt.Sprintf("AS%d", n.AsNumber),
+		truncate(n.City, 18), truncate(n.CountryName(), 16), r.Status)
+	for _, m := range metrics {
+		v := "-"
+		if x, ok := r.Metric(m); ok {
+			v = fmtValue(x)
+		}
+		line += fmt.Sprintf(" %9s", v)
+	}
+	return line
+}
+
+// parseKeys returns the key presses read from the terminal.
+func parseKeys(b []byte) []keyEvent {
+	seqs := []struct {
+		seq string
+		key key
+	}{
+		{"\x1b[A", keyUp}, {"\x1bOA", keyUp},
+		{"\x1b[B", keyDown}, {"\x1bOB", keyDown},
+		{"\x1b[5~", keyPgUp}, {"\x1b[6~", keyPgDn},
+		{"\x1b[H", keyHome}, {"\x1bOH", keyHome}, {"\x1b[1~", keyHome},
+		{"\x1b[F", keyEnd}, {"\x1bOF", keyEnd}, {"\x1b[4~", keyEnd},
+	}
+	var res []keyEvent
+	for len(b) > 0 {
+		if b[0] == 0x1b {
+			matched := false
+			for _, s := range seqs {
+				if strings.HasPrefix(string(b), s.seq) {
+					res = append(res, keyEvent{key: s.key})
+					b = b[len(s.seq):]
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				res = append(res, keyEvent{key: keyEsc})
+				b = b[1:]
+			}
+			continue
+		}
+		switch b[0] {
+		case 3:
+			res = append(res, keyEvent{key: keyCtrlC})
+		case '\r', '\n':
+			res = append(res, keyEvent{key: keyEnter})
+		case 8, 127:
+			res = append(res, keyEvent{key: keyBackspace})
+		default:
+			r, size := utf8.DecodeRune(b)
+			res = append(res, keyEvent{key: keyRune, r: r})
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return res
+}
+
+// RunDashboard shows the results returned by poll in a full-screen
+// dashboard until the user quits. poll is called periodically in the
+// background until it reports the test as finished. The last results are
+// returned along with whether the test finished. A poll still running
+// when the user quits is not waited for.
+func RunDashboard(d *Dashboard, poll func() ([]*Result, bool, error)) ([]*Result, bool, error) {
+	out := os.Stdout
+	fd := int(os.Stdin.Fd())
+	restore, err := makeRaw(fd)
+	if err != nil {
+		return nil, false, errNoTerminal
+	}
+	defer restore()
+	in, closeInput, err := openInput(fd)
+	if err != nil {
+		return nil, false, errNoTerminal
+	}
+	defer closeInput()
+	fmt.Fprint(out, ansiAltScreen)
+	defer fmt.Fprint(out, ansiMainScreen)
+
+	// The keys are read until the dashboard is closed. The reader is
+	// stopped before returning so it does not take the next key press
+	// away from the shell or a following prompt.
+	keys := make(chan []keyEvent)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		buf := make([]byte, 64)
+		for {
+			n, err := in.Read(buf)
+			if err != nil {
+				return
+			}
+			select {
+			case keys <- parseKeys(buf[:n]):
+			case <-done:
+				return
+			}
+		}
+	}()
+	defer func() {
+		close(done)
+		if in.SetReadDeadline(time.Now()) == nil {
+			<-stopped
+		}
+	}()
+
+	type pollResult struct {
+		results  []*Result
+		finished bool
+		err      error
+	}
+	polled := make(chan pollResult, 1)
+	polling := false
+	update := func() {
+		if d.finished || polling {
+			return
+		}
+		polling = true
+		go func() {
+			results, finished, err := poll()
+			polled <- pollResult{results, finished, err}
+		}()
+	}
+
+	tick := time.NewTicker(dashboardPoll)
+	defer tick.Stop()
+	update()
+	for {
+		width, height := terminalSize(int(out.Fd()))
+		d.Render(out, width, height)
+		select {
+		case evs := <-keys:
+			for _, ev := range evs {
+				if d.Handle(ev, height) {
+					return d.results, d.finished, nil
+				}
+			}
+		case r := <-polled:
+			polling = false
+			if r.err != nil {
+				d.err = r.err
+				break
+			}
+			d.Update(r.results, r.finished, nil)
+		case <-tick.C:
+			update()
+		}
+	}
+}
+
+// WatchRun shows the output of a ping, MTR, latency or traceroute test in
+// the dashboard until the user quits. It returns the most recent output,
+// which is nil or not finished if the user quit early.
+func WatchRun(ctx context.Context, title, testType string, testID perfops.TestID, runOutput runOutputFunc) (*perfops.RunOutput, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		mu sync.Mutex
+		o  *perfops.RunOutput
+	)
+	_, _, err := RunDashboard(NewDashboard(title, testType), func() ([]*Result, bool, error) {
+		output, err := runOutput(ctx, testID)
+		if err != nil || output == nil {
+			return nil, false, err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		o = output
+		return RunResults(testType, o), o.IsFinished(), nil
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	return o, err
+}
+
+// WatchDNSTest shows the output of a DNS test in the dashboard until the
+// user quits. It returns the most recent output, which is nil or not
+// finished if the user quit early.
+func WatchDNSTest(ctx context.Context, title, testType string, testID perfops.TestID, dnsOutput func(ctx context.Context, testID perfops.TestID) (*perfops.DNSTestOutput, error)) (*perfops.DNSTestOutput, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		mu sync.Mutex
+		o  *perfops.DNSTestOutput
+	)
+	_, _, err := RunDashboard(NewDashboard(title, testType), func() ([]*Result, bool, error) {
+		output, err := dnsOutput(ctx, testID)
+		if err != nil || output == nil {
+			return nil, false, err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		o = output
+		return DNSResults(testType, o), o.IsFinished(), nil
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	return o, err
+}
+
+// PrintStillRunning tells the user how to get the results of a test that
+// was left running in the dashboard.
+func PrintStillRunning(w io.Writer, testID perfops.TestID) {
+	fmt.Fprintf(w, "Test %s is still running, use 'perfops fetch %s' to show its results\n", testID, testID)
+}
+
+// truncate cuts s to width runes. ANSI escape sequences are not counted.
+func truncate(s string, width int) string {
+	var b strings.Builder
+	n := 0
+	for i := 0; i < len(s); {
+		if s[i] == 0x1b {
+			j := strings.IndexByte(s[i:], 'm')
+			if j < 0 {
+				break
+			}
+			b.WriteString(s[i : i+j+1])
+			i += j + 1
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if n >= width {
+			i += size
+			continue
+		}
+		b.WriteRune(r)
+		n++
+		i += size
+	}
+	return b.String()
+}
+
+// pad fills s with spaces to width runes.
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,216 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func dashboardResults() []*Result {
+	res := testResults()
+	for _, r := range res {
+		r.ID = fmt.Sprintf("item-%d", r.Node.ID)
+		r.Text = fmt.Sprintf("output of node %d", r.Node.ID)
+	}
+	return res
+}
+
+func TestParseKeys(t *testing.T) {
+	got := parseKeys([]byte("\x1b[A\x1bOB\x1b[5~\x1b[6~\x1b[H\x1b[4~\x1bq/\r\x7f\x03ü"))
+	exp := []keyEvent{
+		{key: keyUp}, {key: keyDown}, {key: keyPgUp}, {key: keyPgDn}, {key: keyHome}, {key: keyEnd},
+		{key: keyEsc}, {key: keyRune, r: 'q'}, {key: keyRune, r: '/'}, {key: keyEnter},
+		{key: keyBackspace}, {key: keyCtrlC}, {key: keyRune, r: 'ü'},
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("expected %v; got %v", exp, got)
+	}
+}
+
+func TestOpenInput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the dashboard is not supported on Windows")
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+	in, closeInput, err := openInput(int(r.Fd()))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer closeInput()
+
+	// A pending read is interrupted by the deadline and leaves the next
+	// input to the original reader.
+	read := make(chan error)
+	go func() {
+		_, err := in.Read(make([]byte, 1))
+		read <- err
+	}()
+	if err := in.SetReadDeadline(time.Now()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	select {
+	case err := <-read:
+		if !os.IsTimeout(err) {
+			t.Fatalf("expected timeout; got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected read to be interrupted")
+	}
+	w.Write([]byte("q"))
+	closeInput()
+	b := make([]byte, 1)
+	if n, err := r.Read(b); err != nil || string(b[:n]) != "q" {
+		t.Fatalf("expected q; got %q, %v", b[:n], err)
+	}
+}
+
+func TestDashboardHandle(t *testing.T) {
+	d := NewDashboard("ping", "ping")
+	d.Update(dashboardResults(), false, nil)
+	press := func(s string) bool {
+		quit := false
+		for _, ev := range parseKeys([]byte(s)) {
+			quit = d.Handle(ev, 20)
+		}
+		return quit
+	}
+
+	press("k")
+	if d.selID != "item-5" {
+		t.Fatalf("expected the first node to stay selected; got %s", d.selID)
+	}
+	press("jj")
+	if d.selID != "item-27" {
+		t.Fatalf("expected item-27; got %s", d.selID)
+	}
+	press("\x1b[6~\x1b[6~")
+	if d.selID != "item-30" {
+		t.Fatalf("expected the last node; got %s", d.selID)
+	}
+	press("g")
+	if d.selID != "item-5" {
+		t.Fatalf("expected the first node; got %s", d.selID)
+	}
+
+	// Sort by RTT, reversed.
+	press("sr")
+	if key := d.sortKeys()[d.sortIdx]; key != MetricRTT || !d.reverse {
+		t.Fatalf("expected reversed sort by rtt; got %q, %v", key, d.reverse)
+	}
+	var ids []string
+	for _, r := range d.rows() {
+		ids = append(ids, r.ID)
+	}
+	if exp := []string{"item-30", "item-28", "item-27", "item-12", "item-5"}; !reflect.DeepEqual(ids, exp) {
+		t.Fatalf("expected %v; got %v", exp, ids)
+	}
+
+	press("/hong kongx\x7f")
+	if !d.filtering || d.filter != "hong kong" {
+		t.Fatalf("expected filter 'hong kong' being edited; got %q, %v", d.filter, d.filtering)
+	}
+	if press("q") {
+		t.Fatal("expected q to be part of the filter")
+	}
+	press("\x7f\r")
+	if d.filtering || len(d.rows()) != 2 {
+		t.Fatalf("expected 2 rows after applying the filter; got %d", len(d.rows()))
+	}
+	press("\x1b")
+	if d.filter != "" || len(d.rows()) != 5 {
+		t.Fatalf("expected the filter to be cleared; got %q", d.filter)
+	}
+
+	if !press("q") {
+		t.Fatal("expected q to quit")
+	}
+	if !press("\x03") {
+		t.Fatal("expected Ctrl-C to quit")
+	}
+}
+
+func TestDashboardRender(t *testing.T) {
+	d := NewDashboard("ping example.com (1)", "ping")
+	d.Update(dashboardResults(), true, nil)
+	d.Handle(keyEvent{key: keyDown}, 20)
+
+	var b bytes.Buffer
+	if err := d.Render(&b, 120, 20); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	got := b.String()
+	lines := strings.Split(got, "\r\n")
+	if len(lines) != 20 {
+		t.Fatalf("expected 20 lines; got %d", len(lines))
+	}
+	for _, exp := range []string{
+		"ping example.com (1) [finished]",
+		"5 nodes: 3 ok, 1 failed, 1 timed out, 0 pending | RTT (ms): min 3.00, median 5.00, p95 162.50, max 180.00",
+		"NODE    ASN      CITY               COUNTRY          STATUS         RTT",
+		"12      AS24940  Nuremberg          Germany          ok            5.00",
+		"── Node12, AS24940, Nuremberg, Germany (ok) ──",
+		"output of node 12",
+		"s sort: none",
+	} {
+		if !strings.Contains(got, exp) {
+			t.Fatalf("expected %q in\n%s", exp, got)
+		}
+	}
+	if strings.Contains(got, "output of node 5") {
+		t.Fatalf("expected only the details of the selected node in\n%s", got)
+	}
+
+	d.filter = "berlin"
+	b.Reset()
+	if err := d.Render(&b, 120, 20); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	got = b.String()
+	if strings.Contains(got, "Nuremberg") || !strings.Contains(got, "Filter: berlin") || !strings.Contains(got, "── Node30, AS3320, Berlin, Germany (error) ──") {
+		t.Fatalf("expected only Berlin in\n%s", got)
+	}
+}
+
+func TestTruncatePad(t *testing.T) {
+	testCases := []struct {
+		s     string
+		width int
+		exp   string
+	}{
+		{"abc", 5, "abc"},
+		{"abcdef", 3, "abc"},
+		{"äöüß", 2, "äö"},
+		{ansiBold + "abcdef" + ansiReset, 3, ansiBold + "abc" + ansiReset},
+	}
+	for _, tc := range testCases {
+		if got := truncate(tc.s, tc.width); got != tc.exp {
+			t.Fatalf("truncate(%q, %d): expected %q; got %q", tc.s, tc.width, tc.exp, got)
+		}
+	}
+	if got, exp := pad("äb", 4), "äb  "; got != exp {
+		t.Fatalf("expected %q; got %q", exp, got)
+	}
+}
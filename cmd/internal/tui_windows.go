@@ -0,0 +1,33 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"os"
+)
+
+// makeRaw is not supported on Windows yet.
+func makeRaw(fd int) (func(), error) {
+	return nil, errNoTerminal
+}
+
+// terminalSize returns the default size of 80x24.
+func terminalSize(fd int) (int, int) {
+	return 80, 24
+}
+
+// openInput is not supported on Windows yet.
+func openInput(fd int) (*os.File, func(), error) {
+	return nil, nil, errNoTerminal
+}
@@ -0,0 +1,110 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+type (
+	// UsageRow represents the tests of a day or a test type and the
+	// credits they used.
+	UsageRow struct {
+		Name    string `json:"name"`
+		Tests   int    `json:"tests"`
+		Credits int    `json:"credits"`
+		// Unrecorded is the number of tests without recorded credits.
+		Unrecorded int `json:"unrecorded,omitempty"`
+	}
+
+	// Usage represents the credits used by the tests of the local history
+	// over time.
+	Usage struct {
+		Since time.Time   `json:"since"`
+		Days  []*UsageRow `json:"days"`
+		Types []*UsageRow `json:"types"`
+		Total *UsageRow   `json:"total"`
+	}
+)
+
+// NewUsage returns the usage of the history entries since a time by day
+// in the local time zone and by test type. Days are in chronological
+// order, test types by the credits used, most first.
+func NewUsage(entries []*HistoryEntry, since time.Time) *Usage {
+	u := &Usage{Since: since, Total: &UsageRow{Name: "TOTAL"}}
+	days := map[string]*UsageRow{}
+	types := map[string]*UsageRow{}
+	for _, e := range entries {
+		if e.Time.Before(since) {
+			continue
+		}
+		day := e.Time.Local().Format("2006-01-02")
+		if days[day] == nil {
+			days[day] = &UsageRow{Name: day}
+			u.Days = append(u.Days, days[day])
+		}
+		if types[e.Type] == nil {
+			types[e.Type] = &UsageRow{Name: e.Type}
+			u.Types = append(u.Types, types[e.Type])
+		}
+		for _, r := range []*UsageRow{days[day], types[e.Type], u.Total} {
+			r.Tests++
+			r.Credits += e.Credits
+			if e.Credits == 0 {
+				r.Unrecorded++
+			}
+		}
+	}
+	sort.SliceStable(u.Days, func(i, j int) bool { return u.Days[i].Name < u.Days[j].Name })
+	sort.SliceStable(u.Types, func(i, j int) bool {
+		if u.Types[i].Credits != u.Types[j].Credits {
+			return u.Types[i].Credits > u.Types[j].Credits
+		}
+		return u.Types[i].Name < u.Types[j].Name
+	})
+	return u
+}
+
+// PrintUsage prints the usage by day and by test type.
+func PrintUsage(w io.Writer, u *Usage) error {
+	if u.Total.Tests == 0 {
+		_, err := fmt.Fprintf(w, "No tests since %s\n", u.Since.Local().Format("2006-01-02"))
+		return err
+	}
+	if err := printUsageRows(w, "DAY", append(u.Days, u.Total)); err != nil {
+		return err
+	}
+	fmt.Fprintln(w)
+	if err := printUsageRows(w, "TYPE", u.Types); err != nil {
+		return err
+	}
+	if n := u.Total.Unrecorded; n > 0 {
+		_, err := fmt.Fprintf(w, "\n%d of %d tests have no recorded credits.\n", n, u.Total.Tests)
+		return err
+	}
+	return nil
+}
+
+func printUsageRows(w io.Writer, name string, rows []*UsageRow) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tTESTS\tCREDITS\n", name)
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", r.Name, r.Tests, r.Credits)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,62 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUsage(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	entries := []*HistoryEntry{
+		{ID: "a", Type: "ping", Time: now.AddDate(0, 0, -40), Credits: 100},
+		{ID: "b", Type: "ping", Time: now.AddDate(0, 0, -1), Credits: 5},
+		{ID: "c", Type: "mtr", Time: now.AddDate(0, 0, -1), Credits: 2},
+		{ID: "d", Type: "curl", Time: now, Credits: 6},
+		{ID: "e", Type: "ping", Time: now},
+	}
+	u := NewUsage(entries, now.AddDate(0, 0, -30))
+	if got, exp := *u.Total, (UsageRow{Name: "TOTAL", Tests: 4, Credits: 13, Unrecorded: 1}); got != exp {
+		t.Fatalf("expected %+v; got %+v", exp, got)
+	}
+	if len(u.Days) != 2 || u.Days[0].Name != "2026-03-09" || u.Days[0].Credits != 7 || u.Days[1].Tests != 2 {
+		t.Fatalf("unexpected days %+v %+v", u.Days[0], u.Days[1])
+	}
+	var types []string
+	for _, r := range u.Types {
+		types = append(types, r.Name)
+	}
+	if got, exp := strings.Join(types, ","), "curl,ping,mtr"; got != exp {
+		t.Fatalf("expected types %v; got %v", exp, got)
+	}
+
+	var buf bytes.Buffer
+	if err := PrintUsage(&buf, u); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for _, s := range []string{"DAY", "2026-03-10  2      6", "TOTAL       4      13", "curl  1      6", "1 of 4 tests have no recorded credits."} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("expected %q in output:\n%s", s, buf.String())
+		}
+	}
+
+	buf.Reset()
+	PrintUsage(&buf, NewUsage(nil, now))
+	if got, exp := buf.String(), "No tests since 2026-03-10\n"; got != exp {
+		t.Fatalf("expected %q; got %q", exp, got)
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+type (
+	// View selects how the final results of a test are ordered and
+	// grouped.
+	View struct {
+		// Mode is the way the results are rendered, e.g., as a map.
+		Mode string
+		// Sort is the metric or node attribute to order the results by.
+		Sort string
+		// GroupBy is the node attribute to group the results by.
+		GroupBy string
+		// Top and Bottom limit the results of each group to the first
+		// and last ones.
+		Top    int
+		Bottom int
+		// Aggregate adds tables of the results aggregated by location
+		// and ASN.
+		Aggregate bool
+		// TUI shows the results in a full-screen dashboard while the
+		// test runs.
+		TUI bool
+		// Credits guards and accounts for the credits of the test, if
+		// set.
+		Credits *CreditAccount
+		// Report is the path of the HTML report file to write, if any.
+		Report string
+		// Nodes are the known nodes, e.g., of the node catalog, used to
+		// place nodes without coordinates on the map.
+		Nodes []*perfops.Node
+	}
+
+	// ResultGroup represents a group of results, e.g., the results of the
+	// nodes of a continent.
+	ResultGroup struct {
+		Name    string
+		Results []*Result
+	}
+)
+
+// View modes.
+const (
+	ViewList = "list"
+	ViewMap  = "map"
+)
+
+var (
+	// ViewModes lists the ways results can be rendered.
+	ViewModes = []string{ViewList, ViewMap}
+	// SortKeys lists the keys results can be sorted by.
+	SortKeys = []string{MetricRTT, MetricLoss, MetricTTFB, MetricTotal, MetricResolve, "city", "country", "asn"}
+	// GroupKeys lists the keys results can be grouped by.
+	GroupKeys = []string{"continent", "country", "asn"}
+)
+
+// IsSet returns a value indicating whether the view changes the results.
+func (v *View) IsSet() bool {
+	return v != nil && (v.Mode == ViewMap || v.Sort != "" || v.GroupBy != "" || v.Top > 0 || v.Bottom > 0)
+}
+
+// Validate returns an error if the view is invalid.
+func (v *View) Validate() error {
+	if v.Mode != "" && !containsString(ViewModes, v.Mode) {
+		return fmt.Errorf("invalid view '%s', must be one of: %s", v.Mode, strings.Join(ViewModes, ", "))
+	}
+	if v.Sort != "" && !containsString(SortKeys, v.Sort) {
+		return fmt.Errorf("invalid sort key '%s', must be one of: %s", v.Sort, strings.Join(SortKeys, ", "))
+	}
+	if v.GroupBy != "" && !containsString(GroupKeys, v.GroupBy) {
+		return fmt.Errorf("invalid group key '%s', must be one of: %s", v.GroupBy, strings.Join(GroupKeys, ", "))
+	}
+	if v.Top < 0 || v.Bottom < 0 {
+		return fmt.Errorf("--top and --bottom must not be negative")
+	}
+	return nil
+}
+
+// Apply groups, orders and limits the results. Groups are ordered by
+// name or AS number. Results are ordered by
+// the primary metric of the test if only the number of results is
+// limited. Results without the metric sorted by come last.
+func (v *View) Apply(testType string, results []*Result) []*ResultGroup {
+	key := v.Sort
+	if key == "" && (v.Top > 0 || v.Bottom > 0) {
+		key = PrimaryMetric(testType)
+	}
+	var groups []*ResultGroup
+	byName := map[string]*ResultGroup{}
+	for _, r := range results {
+		name := groupName(r, v.GroupBy)
+		g, ok := byName[name]
+		if !ok {
+			g = &ResultGroup{Name: name}
+			byName[name] = g
+			groups = append(groups, g)
+		}
+		g.Results = append(g.Results, r)
+	}
+	if v.GroupBy != "" {
+		sort.SliceStable(groups, func(i, j int) bool {
+			if v.GroupBy == "asn" {
+				return nodeASN(groups[i].Results[0]) < nodeASN(groups[j].Results[0])
+			}
+			return groups[i].Name < groups[j].Name
+		})
+	}
+	for _, g := range groups {
+		if key != "" {
+			sortResults(g.Results, key)
+		}
+		g.Results = limitResults(g.Results, v.Top, v.Bottom)
+	}
+	return groups
+}
+
+// sortResults orders the results by key in ascending order.
+func sortResults(results []*Result, key string) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		switch key {
+		case "city", "country":
+			return strings.ToLower(nodeAttr(a, key)) < strings.ToLower(nodeAttr(b, key))
+		case "asn":
+			return nodeASN(a) < nodeASN(b)
+		}
+		va, oka := a.Metric(key)
+		vb, okb := b.Metric(key)
+		if oka != okb {
+			return oka
+		}
+		return va < vb
+	})
+}
+
+// limitResults returns the first top and the last bottom results. All
+// results are returned if neither is set.
+func limitResults(results []*Result, top, bottom int) []*Result {
+	if top <= 0 && bottom <= 0 || top+bottom >= len(results) {
+		return results
+	}
+	res := append([]*Result{}, results[:top]...)
+	return append(res, results[len(results)-bottom:]...)
+}
+
+func groupName(r *Result, key string) string {
+	var name string
+	switch key {
+	case "":
+		return ""
+	case "asn":
+		if n := nodeASN(r); n > 0 {
+			name = "AS" + strconv.Itoa(n)
+		}
+	default:
+		name = nodeAttr(r, key)
+	}
+	if name == "" {
+		return unknownLocation
+	}
+	return name
+}
+
+func nodeAttr(r *Result, key string) string {
+	if r.Node == nil {
+		return ""
+	}
+	switch key {
+	case "city":
+		return r.Node.City
+	case "country":
+		return r.Node.CountryName()
+	case "continent":
+		return r.Node.ContinentName()
+	case "region":
+		return r.Node.SubRegion
+	}
+	return ""
+}
+
+func nodeASN(r *Result) int {
+	if r.Node == nil {
+		return 0
+	}
+	return r.Node.AsNumber
+}
+
+// WriteReport writes the HTML report of the run if the view asks for one.
+func (v *View) WriteReport(run *ReportRun) error {
+	if v.Report == "" {
+		return nil
+	}
+	return WriteHTMLReportFile(v.Report, "PerfOps report: "+strings.TrimSpace(run.Type+" "+run.Target), []*ReportRun{run}, v.Nodes)
+}
+
+// PrintResults writes the results of the nodes to w in the order and
+// grouping of the view, or as a map. Results without data are skipped.
+func PrintResults(w io.Writer, testType string, results []*Result, v *View) {
+	groups := v.Apply(testType, results)
+	if v.Mode == ViewMap {
+		var res []*Result
+		for _, g := range groups {
+			res = append(res, g.Results...)
+		}
+		PrintMap(w, PrimaryMetric(testType), res, v.Nodes)
+		return
+	}
+	for i, g := range groups {
+		if v.GroupBy != "" {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "=== %s (%d) ===\n", g.Name, len(g.Results))
+		}
+		for _, r := range g.Results {
+			if r.Status == perfops.StatusNoData || r.Status == perfops.StatusPending {
+				continue
+			}
+			fmt.Fprintf(w, "%s\n%s\n", nodeLocation(r.Node), r.Text)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestViewApply(t *testing.T) {
+	testCases := map[string]struct {
+		view View
+		exp  [][]int
+	}{
+		"None":          {View{}, [][]int{{5, 12, 27, 28, 30}}},
+		"RTT":           {View{Sort: "rtt"}, [][]int{{5, 12, 27, 28, 30}}},
+		"City":          {View{Sort: "city"}, [][]int{{30, 5, 27, 28, 12}}},
+		"ASN":           {View{Sort: "asn"}, [][]int{{5, 30, 27, 28, 12}}},
+		"Top":           {View{Top: 2}, [][]int{{5, 12}}},
+		"Bottom":        {View{Sort: "rtt", Bottom: 1}, [][]int{{30}}},
+		"Top bottom":    {View{Sort: "rtt", Top: 1, Bottom: 1}, [][]int{{5, 30}}},
+		"Continent":     {View{GroupBy: "continent"}, [][]int{{27, 28}, {5, 12, 30}}},
+		"Continent top": {View{GroupBy: "continent", Top: 1}, [][]int{{27}, {5}}},
+		"ASN group":     {View{GroupBy: "asn", Sort: "city"}, [][]int{{30, 5}, {27, 28}, {12}}},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var got [][]int
+			for _, g := range tc.view.Apply("ping", testResults()) {
+				var ids []int
+				for _, r := range g.Results {
+					ids = append(ids, r.Node.ID)
+				}
+				got = append(got, ids)
+			}
+			if !reflect.DeepEqual(got, tc.exp) {
+				t.Fatalf("expected %v; got %v", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestViewValidate(t *testing.T) {
+	testCases := map[string]struct {
+		view   View
+		expErr bool
+	}{
+		"Empty":    {View{}, false},
+		"Valid":    {View{Sort: "ttfb", GroupBy: "country", Top: 3}, false},
+		"Sort":     {View{Sort: "speed"}, true},
+		"Group":    {View{GroupBy: "city"}, true},
+		"Negative": {View{Bottom: -1}, true},
+		"Map":      {View{Mode: "map"}, false},
+		"Mode":     {View{Mode: "globe"}, true},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if err := tc.view.Validate(); (err != nil) != tc.expErr {
+				t.Fatalf("expected error %v; got %v", tc.expErr, err)
+			}
+		})
+	}
+}
+
+func TestPrintResults(t *testing.T) {
+	results := testResults()
+	for _, r := range results {
+		r.Text = "out"
+	}
+	var b bytes.Buffer
+	PrintResults(&b, "ping", results, &View{GroupBy: "country", Top: 1})
+	exp := `=== Germany (1) ===
+Node5, AS3320, Frankfurt, Germany
+out
+
+=== Hong Kong (1) ===
+Node27, AS9304, Hong Kong, Hong Kong
+out
+`
+	if got := b.String(); got != exp {
+		t.Fatalf("expected\n%s\ngot\n%s", exp, got)
+	}
+}
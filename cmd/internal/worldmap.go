@@ -0,0 +1,243 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+// mapBucket classifies the result of a node on the map. Higher buckets
+// are worse and win if several nodes share a cell of the map.
+type mapBucket int
+
+const (
+	bucketNone mapBucket = iota
+	bucketGood
+	bucketWarn
+	bucketBad
+	bucketFailed
+)
+
+const (
+	// mapNorth and mapSouth are the latitudes of the top and bottom
+	// edges of the map. The map spans all longitudes.
+	mapNorth = 84.0
+	mapSouth = -58.0
+
+	colorRed    = 31
+	colorGreen  = 32
+	colorYellow = 33
+	colorGray   = 90
+)
+
+var (
+	// mapThresholds are the upper bounds of the good and warn buckets of
+	// the metrics.
+	mapThresholds = map[string][2]float64{
+		MetricRTT:     {50, 150},
+		MetricLoss:    {1, 10},
+		MetricTTFB:    {200, 500},
+		MetricTotal:   {500, 1500},
+		MetricResolve: {20, 100},
+	}
+
+	// mapSymbols are the colors and symbols of the buckets. The plain
+	// symbols tell the buckets apart if colors are turned off.
+	mapSymbols = map[mapBucket]struct {
+		color         int
+		symbol, plain string
+	}{
+		bucketNone:   {colorGray, "○", "○"},
+		bucketGood:   {colorGreen, "●", "●"},
+		bucketWarn:   {colorYellow, "●", "▲"},
+		bucketBad:    {colorRed, "●", "■"},
+		bucketFailed: {colorGray, "✕", "✕"},
+	}
+
+	// worldLand is a mask of the land masses of the world in an
+	// equirectangular projection between mapNorth and mapSouth.
+	worldLand = []string{
+		"                ######## ###########                                            ",
+		"             #######################                      ########              ",
+		"   #####################   #########       #####################################",
+		"  ########################  #####  ##    #######################################",
+		"        ###################           ## ####################################   ",
+		"           #################          #####################################     ",
+		"            ###############            #################################        ",
+		"            #############             ###  #############################        ",
+		"             ##########               #####    ####################  ##         ",
+		"              ###### #               ##############################             ",
+		"               ######  #            #################  ############             ",
+		"                  ####              #################   ###  ###  ##            ",
+		"                     ## ###         ################     #    ##   #            ",
+		"                      #######         #############          ##  ##             ",
+		"                      #########           ########            # ##   ###        ",
+		"                      ##########          #######              ###    ###       ",
+		"                       #########           ###### #                 ####        ",
+		"                        #######           ####### #               #######       ",
+		"                        ######             #####  #              #########      ",
+		"                        #####              ####                  #########      ",
+		"                        ####                                          ####    ##",
+		"                       ###                                                   ## ",
+		"                       ###                                                      ",
+		"                        #                                                       ",
+	}
+)
+
+// mapPoint is a node drawn on the map. The point of the highest rank is
+// drawn if several nodes share a cell of the map.
+type mapPoint struct {
+	node   *perfops.Node
+	symbol string
+	rank   int
+}
+
+// PrintMap writes a world map with the nodes of the results to w. Each
+// node is colored by the bucket of its metric value. Nodes without
+// coordinates are looked up in nodes, e.g., the node catalog.
+func PrintMap(w io.Writer, metric string, results []*Result, nodes []*perfops.Node) error {
+	var points []mapPoint
+	for _, r := range results {
+		if r.Status == perfops.StatusPending {
+			continue
+		}
+		b := resultBucket(r, metric)
+		points = append(points, mapPoint{node: r.Node, symbol: mapSymbol(b), rank: int(b)})
+	}
+	return drawMap(w, points, nodes, mapLegend(metric))
+}
+
+// drawMap writes a world map with the points and the legend below to w.
+// Nodes without coordinates are looked up in nodes.
+func drawMap(w io.Writer, points []mapPoint, nodes []*perfops.Node, legend string) error {
+	locate := nodeLocator(nodes)
+	height, width := len(worldLand), len(worldLand[0])
+	cells := map[[2]int]mapPoint{}
+	var unplaced int
+	for _, p := range points {
+		n := locate(p.node)
+		if n == nil {
+			unplaced++
+			continue
+		}
+		row := clamp(int((mapNorth-n.Latitude)/(mapNorth-mapSouth)*float64(height)), height-1)
+		col := clamp(int((n.Longitude+180)/360*float64(width)), width-1)
+		cell := [2]int{row, col}
+		if c, ok := cells[cell]; !ok || p.rank >= c.rank {
+			cells[cell] = p
+		}
+	}
+	for row, land := range worldLand {
+		var b strings.Builder
+		for col, c := range land {
+			if p, ok := cells[[2]int{row, col}]; ok {
+				b.WriteString(p.symbol)
+			} else if c == '#' {
+				b.WriteString("·")
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		if _, err := fmt.Fprintln(w, strings.TrimRight(b.String(), " ")); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, legend)
+	if err == nil && unplaced > 0 {
+		_, err = fmt.Fprintf(w, "%d nodes without a known location are not shown\n", unplaced)
+	}
+	return err
+}
+
+// nodeLocator returns a function returning the node with its coordinates.
+// Nodes without coordinates are looked up in nodes, nil is returned if
+// they are unknown.
+func nodeLocator(nodes []*perfops.Node) func(n *perfops.Node) *perfops.Node {
+	known := map[int]*perfops.Node{}
+	for _, n := range nodes {
+		known[n.ID] = n
+	}
+	return func(n *perfops.Node) *perfops.Node {
+		if n == nil || n.Latitude != 0 || n.Longitude != 0 {
+			return n
+		}
+		return known[n.ID]
+	}
+}
+
+// resultBucket returns the bucket of the result on the map.
+func resultBucket(r *Result, metric string) mapBucket {
+	if r.Status != perfops.StatusOK {
+		if r.Status.IsFailed() {
+			return bucketFailed
+		}
+		return bucketNone
+	}
+	t, ok := mapThresholds[metric]
+	if !ok {
+		return bucketGood
+	}
+	v, ok := r.Metric(metric)
+	switch {
+	case !ok:
+		return bucketNone
+	case v < t[0]:
+		return bucketGood
+	case v < t[1]:
+		return bucketWarn
+	}
+	return bucketBad
+}
+
+// mapLegend returns the legend of the buckets of the metric.
+func mapLegend(metric string) string {
+	t, ok := mapThresholds[metric]
+	if !ok {
+		return fmt.Sprintf("%s ok  %s failed  %s no data", mapSymbol(bucketGood), mapSymbol(bucketFailed), mapSymbol(bucketNone))
+	}
+	return fmt.Sprintf("%s: %s < %g  %s %g-%g  %s >= %g  %s failed  %s no data", metricLabels[metric],
+		mapSymbol(bucketGood), t[0], mapSymbol(bucketWarn), t[0], t[1], mapSymbol(bucketBad), t[1],
+		mapSymbol(bucketFailed), mapSymbol(bucketNone))
+}
+
+func clamp(i, max int) int {
+	if i < 0 {
+		return 0
+	}
+	if i > max {
+		return max
+	}
+	return i
+}
+
+// mapSymbol returns the symbol of a bucket, colored if colors are on.
+func mapSymbol(b mapBucket) string {
+	s := mapSymbols[b]
+	if !color {
+		return s.plain
+	}
+	return colorize(s.color, s.symbol)
+}
+
+// colorize returns s in the ANSI color if colors are on.
+func colorize(c int, s string) string {
+	if !color {
+		return s
+	}
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", c, s)
+}
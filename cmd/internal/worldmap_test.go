@@ -0,0 +1,73 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func TestResultBucket(t *testing.T) {
+	testCases := map[string]struct {
+		r      *Result
+		metric string
+		exp    mapBucket
+	}{
+		"Good":      {&Result{Status: perfops.StatusOK, Metrics: map[string]float64{MetricRTT: 20}}, MetricRTT, bucketGood},
+		"Warn":      {&Result{Status: perfops.StatusOK, Metrics: map[string]float64{MetricRTT: 50}}, MetricRTT, bucketWarn},
+		"Bad":       {&Result{Status: perfops.StatusOK, Metrics: map[string]float64{MetricTTFB: 800}}, MetricTTFB, bucketBad},
+		"No value":  {&Result{Status: perfops.StatusOK}, MetricRTT, bucketNone},
+		"No metric": {&Result{Status: perfops.StatusOK}, "", bucketGood},
+		"Timeout":   {&Result{Status: perfops.StatusTimeout}, MetricRTT, bucketFailed},
+		"No data":   {&Result{Status: perfops.StatusNoData}, MetricRTT, bucketNone},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if got := resultBucket(tc.r, tc.metric); got != tc.exp {
+				t.Fatalf("expected %v; got %v", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestPrintMap(t *testing.T) {
+	nodes := []*perfops.Node{
+		{ID: 5, Latitude: 50.11, Longitude: 8.69},
+		{ID: 27, Latitude: 22.29, Longitude: 114.18},
+		{ID: 28, Latitude: -33.87, Longitude: 151.21},
+	}
+	var b bytes.Buffer
+	if err := PrintMap(&b, MetricRTT, testResults(), nodes); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	lines := strings.Split(b.String(), "\n")
+	if len(lines) != len(worldLand)+3 {
+		t.Fatalf("expected %d lines; got %d", len(worldLand)+3, len(lines))
+	}
+	// Frankfurt, Hong Kong and Sydney.
+	for _, c := range []struct {
+		row    int
+		symbol string
+	}{{5, mapSymbol(bucketGood)}, {10, mapSymbol(bucketBad)}, {19, mapSymbol(bucketFailed)}} {
+		if !strings.Contains(lines[c.row], c.symbol) {
+			t.Fatalf("expected %q in row %d %q", c.symbol, c.row, lines[c.row])
+		}
+	}
+	if got, exp := lines[len(worldLand)+1], "2 nodes without a known location are not shown"; got != exp {
+		t.Fatalf("expected %q; got %q", exp, got)
+	}
+}
@@ -47,6 +47,7 @@ func initLatencyCmd(parentCmd *cobra.Command) {
 	parentCmd.AddCommand(latencyCmd)
 	latencyCmd.Flags().IntVarP(&latencyLimit, "limit", "L", 1, "The maximum number of nodes to use")
 	latencyCmd.Flags().BoolVarP(&latencyIpv6, "ipv6", "6", false, "Use IPv6")
+	latencyCmd.Flags().BoolVarP(&resultView.Aggregate, "aggregate", "", false, aggregateUsage)
 }
 
 func runLatency(c *perfops.Client, target, from string, nodeIDs []int, limit int, ipv6 bool) error {
@@ -55,5 +56,19 @@ func runLatency(c *perfops.Client, target, from string, nodeIDs []int, limit int
 	if ipv6 {
 		ipversion = 6
 	}
-	return internal.RunTest(ctx, target, from, nodeIDs, limit, ipversion, debug, outputJSON, c.Run.Latency, c.Run.LatencyOutput)
+	from, nodeIDs, limit, err := selectNodes(c, "latency", from, nodeIDs, limit)
+	if err != nil {
+		return err
+	}
+	if err := prepareView(c); err != nil {
+		return err
+	}
+	if dryRun {
+		req, err := internal.NewRunRequest(target, from, nodeIDs, limit, ipversion)
+		if err != nil {
+			return err
+		}
+		return printPlan(c, "latency", target, from, nodeIDs, limit, req)
+	}
+	return internal.RunTest(ctx, "latency", target, from, nodeIDs, limit, ipversion, debug, outputFormat(), &resultView, withHistory("latency", c.Run.Latency), c.Run.LatencyOutput)
 }
@@ -3,21 +3,27 @@ package cmd
 import (
 	"errors"
 	"fmt"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
 	"github.com/ProspectOne/perfops-cli/perfops"
 	"github.com/spf13/cobra"
 )
 
 var (
-	listTypesMap = map[string]func(client *perfops.Client) error{
-		"countries": runCountriesCmd,
-		"cities":    runCitiesCmd,
+	listTypesMap = map[string]func(client *perfops.Client, output string) error{
+		"asns":       runASNsCmd,
+		"cities":     runCitiesCmd,
+		"continents": runContinentsCmd,
+		"countries":  runCountriesCmd,
+		"regions":    runRegionsCmd,
 	}
 
 	listCmd = &cobra.Command{
-		Use:     "list [type]",
-		Short:   "Get a locations where PerfOps nodes are present",
-		Long:    `Get a locations where PerfOps nodes are present, e.g., 'list countries' or 'list cities'`,
-		Example: `perfops list countries`,
+		Use:       "list [type]",
+		Short:     "Get a locations where PerfOps nodes are present",
+		Long:      `Get a locations where PerfOps nodes are present, e.g., 'list countries', 'list cities', 'list continents', 'list regions' or 'list asns'`,
+		Example:   `perfops list countries --output tree`,
+		ValidArgs: []string{"asns", "cities", "continents", "countries", "regions"},
 		Args: func(cmd *cobra.Command, args []string) error {
 			if len(args) < 1 {
 				return errors.New("specify the type of data You want to get")
@@ -29,18 +35,22 @@ var (
 			if err != nil {
 				return err
 			}
-			return chkRunError(runListCmd(c, args[0]))
+			return chkRunError(runListCmd(c, args[0], listOutput))
 		},
 	}
+
+	listOutput string
 )
 
 func initListCmd(parentCmd *cobra.Command) {
+	listCmd.Flags().StringVarP(&listOutput, "output", "o", "json", "The output format. One of: json, table, tree")
+
 	parentCmd.AddCommand(listCmd)
 }
 
-func runListCmd(c *perfops.Client, dataType string) error {
+func runListCmd(c *perfops.Client, dataType, output string) error {
 	if f, ok := listTypesMap[dataType]; ok {
-		err := f(c)
+		err := f(c, output)
 		if err != nil {
 			return errors.New(fmt.Sprintf("error happened %v", err))
 		}
@@ -50,3 +60,30 @@ func runListCmd(c *perfops.Client, dataType string) error {
 
 	return errors.New(fmt.Sprintf("no data with type '%s'", dataType))
 }
+
+// printList prints v in the given output format using the table and tree
+// printers. A nil tree printer means the data cannot be shown as a tree.
+func printList(output string, v interface{}, table, tree func() error) error {
+	switch output {
+	case "json":
+		return internal.PrintOutputJSON(v)
+	case "table":
+		return table()
+	case "tree":
+		if tree == nil {
+			return errors.New("tree output is not supported for this type")
+		}
+		return tree()
+	}
+	return fmt.Errorf("unsupported output format '%s'", output)
+}
+
+// chkListOutput returns an error if output is not a known list output
+// format.
+func chkListOutput(output string) error {
+	switch output {
+	case "json", "table", "tree":
+		return nil
+	}
+	return fmt.Errorf("unsupported output format '%s'", output)
+}
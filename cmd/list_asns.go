@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func runASNsCmd(c *perfops.Client, output string) error {
+	if err := chkListOutput(output); err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	f := internal.NewFormatter(debug)
+	f.StartSpinner()
+	nodes, err := c.Nodes.List(ctx)
+	f.StopSpinner()
+	if err != nil {
+		return err
+	}
+
+	res := internal.NodeASNs(nodes)
+	return printList(output, res, func() error {
+		return internal.PrintASNsTable(os.Stdout, res)
+	}, nil)
+}
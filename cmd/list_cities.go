@@ -2,31 +2,33 @@ package cmd
 
 import (
 	"context"
+	"os"
+
 	"github.com/ProspectOne/perfops-cli/cmd/internal"
 	"github.com/ProspectOne/perfops-cli/perfops"
-	"net/http"
 )
 
-func runCitiesCmd(c *perfops.Client) error {
-	var res *[]perfops.City
-
+func runCitiesCmd(c *perfops.Client, output string) error {
+	if err := chkListOutput(output); err != nil {
+		return err
+	}
 	ctx := context.Background()
-	u := c.BasePath + "/analytics/dns/city"
 
 	f := internal.NewFormatter(debug)
 	f.StartSpinner()
-
-	req, _ := http.NewRequest("GET", u, nil)
-	req = req.WithContext(ctx)
-
-	err := c.DoRequest(req, &res);
+	res, err := c.Geo.Cities(ctx)
 	f.StopSpinner()
-
 	if err != nil {
 		return err
 	}
 
-	internal.PrintOutputJSON(res)
-
-	return nil
+	return printList(output, res, func() error {
+		return internal.PrintCitiesTable(os.Stdout, res)
+	}, func() error {
+		var paths [][]string
+		for _, c := range res {
+			paths = append(paths, []string{c.Continent.GetName(), c.Country.GetName(), c.Name})
+		}
+		return internal.PrintLocationTree(os.Stdout, paths)
+	})
 }
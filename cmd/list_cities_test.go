@@ -11,7 +11,7 @@ func TestGetCitiesList(t *testing.T) {
 		t.Fatalf("unexpected error %v", err)
 	}
 
-	runCitiesCmd(c)
+	runCitiesCmd(c, "json")
 	if got, exp := tr.req.URL.Path, "/analytics/dns/city"; got != exp {
 		t.Fatalf("expected %v; got %v", exp, got)
 	}
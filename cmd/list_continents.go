@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func runContinentsCmd(c *perfops.Client, output string) error {
+	if err := chkListOutput(output); err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	f := internal.NewFormatter(debug)
+	f.StartSpinner()
+	res, err := c.Geo.Continents(ctx)
+	f.StopSpinner()
+	if err != nil {
+		return err
+	}
+
+	return printList(output, res, func() error {
+		return internal.PrintContinentsTable(os.Stdout, res)
+	}, func() error {
+		var paths [][]string
+		for _, c := range res {
+			paths = append(paths, []string{c.Name})
+		}
+		return internal.PrintLocationTree(os.Stdout, paths)
+	})
+}
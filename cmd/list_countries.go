@@ -2,31 +2,33 @@ package cmd
 
 import (
 	"context"
+	"os"
+
 	"github.com/ProspectOne/perfops-cli/cmd/internal"
 	"github.com/ProspectOne/perfops-cli/perfops"
-	"net/http"
 )
 
-func runCountriesCmd(c *perfops.Client) error {
-	var res *[]perfops.Country
-
+func runCountriesCmd(c *perfops.Client, output string) error {
+	if err := chkListOutput(output); err != nil {
+		return err
+	}
 	ctx := context.Background()
-	u := c.BasePath + "/analytics/dns/countries"
 
 	f := internal.NewFormatter(debug)
 	f.StartSpinner()
-
-	req, _ := http.NewRequest("GET", u, nil)
-	req = req.WithContext(ctx)
-
-	err := c.DoRequest(req, &res);
+	res, err := c.Geo.Countries(ctx)
 	f.StopSpinner()
-
 	if err != nil {
 		return err
 	}
 
-	internal.PrintOutputJSON(res)
-
-	return nil
+	return printList(output, res, func() error {
+		return internal.PrintCountriesTable(os.Stdout, res)
+	}, func() error {
+		var paths [][]string
+		for _, c := range res {
+			paths = append(paths, []string{c.Continent.GetName(), c.Name})
+		}
+		return internal.PrintLocationTree(os.Stdout, paths)
+	})
 }
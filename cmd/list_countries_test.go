@@ -11,7 +11,7 @@ func TestGetCountriesList(t *testing.T) {
 		t.Fatalf("unexpected error %v", err)
 	}
 
-	runCountriesCmd(c)
+	runCountriesCmd(c, "json")
 	if got, exp := tr.req.URL.Path, "/analytics/dns/countries"; got != exp {
 		t.Fatalf("expected %v; got %v", exp, got)
 	}
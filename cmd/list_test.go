@@ -9,9 +9,8 @@ func TestInitListCmd(t *testing.T) {
 	parent := &cobra.Command{}
 	listCmd.ResetFlags()
 	initListCmd(parent)
-	flags := listCmd.HasAvailableLocalFlags()
-	if flags == true {
-		t.Fatalf("expected that command doesn't have flags")
+	if got, exp := listCmd.Flag("output").DefValue, "json"; got != exp {
+		t.Fatalf("expected output flag default %v; got %v", exp, got)
 	}
 }
 
@@ -23,6 +22,9 @@ func TestRunListCmd(t *testing.T) {
 	}{
 		"Countries":  {"countries", "/analytics/dns/countries", nil},
 		"Cities":     {"cities", "/analytics/dns/city", nil},
+		"Continents": {"continents", "/analytics/dns/countries", nil},
+		"Regions":    {"regions", "/nodes", nil},
+		"ASNs":       {"asns", "/nodes", nil},
 		"Wrong type": {"country", "/analytics/dns/country", "no data with type 'country'"},
 	}
 
@@ -34,7 +36,7 @@ func TestRunListCmd(t *testing.T) {
 				t.Fatalf("unexpected error %v", err)
 			}
 
-			err = runListCmd(c, tc.dataType)
+			err = runListCmd(c, tc.dataType, "json")
 
 			if tc.expectedErr != nil {
 				if tc.expectedErr != err.Error() {
@@ -51,3 +53,18 @@ func TestRunListCmd(t *testing.T) {
 		})
 	}
 }
+
+func TestRunListCmdOutput(t *testing.T) {
+	tr := &recordingTransport{}
+	c, err := newTestPerfopsClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	err = runListCmd(c, "countries", "yaml")
+	if exp := "error happened unsupported output format 'yaml'"; err == nil || err.Error() != exp {
+		t.Fatalf("expected %v error; got %v", exp, err)
+	}
+	if tr.req != nil {
+		t.Fatalf("expected no request for an unsupported output format")
+	}
+}
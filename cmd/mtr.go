@@ -55,5 +55,19 @@ func runMTR(c *perfops.Client, target, from string, nodeIDs []int, limit int, ip
 	if ipv6 {
 		ipversion = 6
 	}
-	return internal.RunTest(ctx, target, from, nodeIDs, limit, ipversion, debug, outputJSON, c.Run.MTR, c.Run.MTROutput)
+	from, nodeIDs, limit, err := selectNodes(c, "mtr", from, nodeIDs, limit)
+	if err != nil {
+		return err
+	}
+	if err := prepareView(c); err != nil {
+		return err
+	}
+	if dryRun {
+		req, err := internal.NewRunRequest(target, from, nodeIDs, limit, ipversion)
+		if err != nil {
+			return err
+		}
+		return printPlan(c, "mtr", target, from, nodeIDs, limit, req)
+	}
+	return internal.RunTest(ctx, "mtr", target, from, nodeIDs, limit, ipversion, debug, outputFormat(), &resultView, withHistory("mtr", c.Run.MTR), c.Run.MTROutput)
 }
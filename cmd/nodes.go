@@ -0,0 +1,81 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+var (
+	nodesCmd = &cobra.Command{
+		Use:   "nodes",
+		Short: "List the PerfOps test nodes",
+		Long: `List the PerfOps test nodes and their location, e.g., to pick stable node IDs for --nodeid.
+The nodes are read from the local catalog, which is refreshed once a day or with
+perfops cache refresh.`,
+		Example: `perfops nodes --country DE --asn 3320 --continent EU`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newPerfOpsClient()
+			if err != nil {
+				return err
+			}
+			return chkRunError(runNodes(c, &nodesFilter, nodesOutput))
+		},
+	}
+
+	nodesFilter perfops.NodeFilter
+	nodesOutput string
+)
+
+func initNodesCmd(parentCmd *cobra.Command) {
+	nodesCmd.Flags().StringSliceVarP(&nodesFilter.Countries, "country", "C", []string{}, "A comma separated list of country names or ISO codes")
+	nodesCmd.Flags().StringSliceVarP(&nodesFilter.Continents, "continent", "", []string{}, "A comma separated list of continent names or ISO codes")
+	nodesCmd.Flags().StringSliceVarP(&nodesFilter.Cities, "city", "", []string{}, "A comma separated list of city names")
+	nodesCmd.Flags().IntSliceVarP(&nodesFilter.ASNs, "asn", "", []int{}, "A comma separated list of AS numbers")
+	nodesCmd.Flags().BoolVarP(&nodesFilter.IPv6, "ipv6-capable", "", false, "List only nodes able to run tests over IPv6")
+	nodesCmd.Flags().StringVarP(&nodesOutput, "output", "o", "table", "The output format. One of: table, csv, json")
+
+	parentCmd.AddCommand(nodesCmd)
+}
+
+func runNodes(c *perfops.Client, filter *perfops.NodeFilter, output string) error {
+	var printNodes func(nodes []*perfops.Node) error
+	switch output {
+	case "table":
+		printNodes = func(nodes []*perfops.Node) error { return internal.PrintNodesTable(os.Stdout, nodes) }
+	case "csv":
+		printNodes = func(nodes []*perfops.Node) error { return internal.PrintNodesCSV(os.Stdout, nodes) }
+	case "json":
+		printNodes = func(nodes []*perfops.Node) error { return internal.PrintOutputJSON(nodes) }
+	default:
+		return fmt.Errorf("unsupported output format '%s'", output)
+	}
+
+	f := internal.NewFormatter(debug)
+	f.StartSpinner()
+	cat, err := loadCatalog(c)
+	f.StopSpinner()
+	if err != nil {
+		return err
+	}
+
+	return printNodes(perfops.FilterNodes(cat.Nodes, filter))
+}
@@ -0,0 +1,94 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func TestInitNodesCmd(t *testing.T) {
+	testCases := map[string]struct {
+		args   []string
+		gotexp func() (interface{}, interface{})
+	}{
+		"country":      {[]string{"--country", "DE,FR"}, func() (interface{}, interface{}) { return nodesFilter.Countries, []string{"DE", "FR"} }},
+		"continent":    {[]string{"--continent", "EU"}, func() (interface{}, interface{}) { return nodesFilter.Continents, []string{"EU"} }},
+		"city":         {[]string{"--city", "Frankfurt"}, func() (interface{}, interface{}) { return nodesFilter.Cities, []string{"Frankfurt"} }},
+		"asn":          {[]string{"--asn", "3320"}, func() (interface{}, interface{}) { return nodesFilter.ASNs, []int{3320} }},
+		"ipv6-capable": {[]string{"--ipv6-capable"}, func() (interface{}, interface{}) { return nodesFilter.IPv6, true }},
+		"output":       {[]string{"--output", "csv"}, func() (interface{}, interface{}) { return nodesOutput, "csv" }},
+	}
+	parent := &cobra.Command{}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			nodesCmd.ResetFlags()
+			initNodesCmd(parent)
+			if err := nodesCmd.ParseFlags(tc.args); err != nil {
+				t.Fatalf("exepected nil; got %v", err)
+			}
+			if f := nodesCmd.Flags().Lookup(name); f == nil {
+				t.Fatal("expected flag; got nil")
+			}
+
+			got, exp := tc.gotexp()
+			if reflect.DeepEqual(got, exp) == false {
+				t.Fatalf("expected %v; got %v", exp, got)
+			}
+		})
+	}
+}
+
+func TestRunNodes(t *testing.T) {
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	tr := &recordingTransport{}
+	c, err := newTestPerfopsClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	runNodes(c, &perfops.NodeFilter{}, "table")
+	if got, exp := tr.req.URL.Path, "/nodes"; got != exp {
+		t.Fatalf("expected %v; got %v", exp, got)
+	}
+
+	if err := runNodes(c, &perfops.NodeFilter{}, "xml"); err == nil {
+		t.Fatal("expected error for unsupported output format; got nil")
+	}
+
+	// The nodes of a fresh catalog are listed without a request.
+	p, err := catalogPath()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	cat := &internal.Catalog{Updated: time.Now(), Nodes: []*perfops.Node{{ID: 5}}}
+	if err := cat.Save(p); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	tr.req = nil
+	if err := runNodes(c, &perfops.NodeFilter{}, "json"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if tr.req != nil {
+		t.Fatalf("expected no request; got %v", tr.req.URL)
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+var (
+	// selection holds the node selection flags common to all tests.
+	selection internal.NodeSelection
+	// sameNodesAs references a previous test to take the nodes from.
+	sameNodesAs string
+)
+
+// configPath returns the path of the user config file.
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "perfops", "config.json"), nil
+}
+
+func loadConfig() (*internal.Config, error) {
+	p, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	return internal.LoadConfig(p)
+}
+
+// nodeSelection returns the node selection flags merged with the
+// exclusions of the config file.
+func nodeSelection() (*internal.NodeSelection, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	sel := selection
+	sel.Filter.ExcludeNodes = append(append([]int{}, selection.Filter.ExcludeNodes...), cfg.ExcludeNodes...)
+	sel.Filter.ExcludeCountries = append(append([]string{}, selection.Filter.ExcludeCountries...), cfg.ExcludeCountries...)
+	return &sel, nil
+}
+
+// selectNodes resolves the node selection against the node catalog into
+// an explicit list of node IDs and the limit matching it. The arguments
+// are returned unchanged if there is nothing to select.
+func selectNodes(c *perfops.Client, testType, from string, nodeIDs []int, limit int) (string, []int, int, error) {
+	if sameNodesAs != "" {
+		if from != "" || len(nodeIDs) > 0 {
+			return "", nil, 0, errors.New("--same-nodes-as cannot be combined with --from or --nodeid")
+		}
+		ids, err := sameNodes(c, testType, sameNodesAs)
+		if err != nil {
+			return "", nil, 0, err
+		}
+		nodeIDs, limit = ids, len(ids)
+	}
+	sel, err := nodeSelection()
+	if err != nil {
+		return "", nil, 0, err
+	}
+	if sel.IsEmpty() {
+		return from, nodeIDs, limit, nil
+	}
+	location, quotas, err := internal.ParseLocation(from)
+	if err != nil {
+		return "", nil, 0, err
+	}
+	nodes, err := shuffledNodes(c)
+	if err != nil {
+		return "", nil, 0, err
+	}
+	ids, err := sel.Select(nodes, location, quotas, nodeIDs, limit)
+	if err != nil {
+		// The catalog has no nodes in some valid locations, e.g., US
+		// states, to apply the selection to.
+		return "", nil, 0, fmt.Errorf("%v, select the nodes by country, city or continent instead", err)
+	}
+	if debug {
+		fmt.Fprintf(os.Stderr, "Selected nodes: %v\n", ids)
+	}
+	return "", ids, len(ids), nil
+}
+
+// pinNodes selects the nodes of tests that must run on the same nodes,
+// e.g., against several DNS servers, into an explicit list of node IDs.
+// The nodes are picked from the node catalog if not selected otherwise.
+func pinNodes(c *perfops.Client, testType, from string, nodeIDs []int, limit int) ([]int, error) {
+	from, nodeIDs, limit, err := selectNodes(c, testType, from, nodeIDs, limit)
+	if err != nil || len(nodeIDs) > 0 {
+		return nodeIDs, err
+	}
+	location, quotas, err := internal.ParseLocation(from)
+	if err != nil {
+		return nil, err
+	}
+	sel, err := nodeSelection()
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := shuffledNodes(c)
+	if err != nil {
+		return nil, err
+	}
+	return sel.Select(nodes, location, quotas, nil, limit)
+}
+
+// balancedNodes selects nodes spread evenly over the continents and
+// countries of the node catalog, or of the location given by from. Node
+// IDs, a previous test or per-location node limits are selected as usual.
+func balancedNodes(c *perfops.Client, testType, from string, nodeIDs []int, limit int) ([]int, error) {
+	location, quotas, err := internal.ParseLocation(from)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodeIDs) > 0 || sameNodesAs != "" || len(quotas) > 0 {
+		return pinNodes(c, testType, from, nodeIDs, limit)
+	}
+	sel, err := nodeSelection()
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := shuffledNodes(c)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := sel.SelectBalanced(internal.NodesInLocation(nodes, location), limit)
+	if err != nil {
+		return nil, err
+	}
+	if debug {
+		fmt.Fprintf(os.Stderr, "Selected nodes: %v\n", ids)
+	}
+	return ids, nil
+}
+
+// shuffledNodes returns the nodes of the catalog in random order to not
+// always pick the same ones.
+func shuffledNodes(c *perfops.Client) ([]*perfops.Node, error) {
+	cat, err := loadCatalog(c)
+	if err != nil {
+		return nil, err
+	}
+	nodes := append([]*perfops.Node{}, cat.Nodes...)
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	rnd.Shuffle(len(nodes), func(i, j int) { nodes[i], nodes[j] = nodes[j], nodes[i] })
+	return nodes, nil
+}
+
+// sameNodes returns the IDs of the nodes a previous test ran on. The test
+// is referenced by a JSON output file, by "@n" for the n-th most recent
+// test in the history, or by its test ID. Test IDs not in the history are
+// assumed to be of testType.
+func sameNodes(c *perfops.Client, testType, ref string) ([]int, error) {
+	if fi, err := os.Stat(ref); err == nil && !fi.IsDir() {
+		b, err := ioutil.ReadFile(ref)
+		if err != nil {
+			return nil, err
+		}
+		ids, err := internal.NodeIDsFromJSON(b)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", ref, err)
+		}
+		return ids, nil
+	}
+
+	e, err := findTest(ref, testType)
+	if err != nil {
+		return nil, err
+	}
+	results, err := fetchResults(c, e)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[int]bool{}
+	var ids []int
+	for _, r := range results {
+		if r.Node != nil && !seen[r.Node.ID] {
+			seen[r.Node.ID] = true
+			ids = append(ids, r.Node.ID)
+		}
+	}
+	sort.Ints(ids)
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no nodes found in test '%s'", e.ID)
+	}
+	return ids, nil
+}
+
+// findTest returns the history entry of a test referenced by "@n" for
+// the n-th most recent test in the history or by its test ID. Test IDs
+// not in the history are assumed to be of testType.
+func findTest(ref, testType string) (*internal.HistoryEntry, error) {
+	entries, err := readHistory()
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(ref, "@") {
+		n, err := strconv.Atoi(ref[1:])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid history entry '%s'", ref)
+		}
+		recent := recentHistory(entries, n)
+		if len(recent) < n {
+			return nil, fmt.Errorf("history entry '%s' not found", ref)
+		}
+		return recent[n-1], nil
+	}
+	if e := internal.FindHistory(entries, perfops.TestID(ref)); e != nil {
+		return e, nil
+	}
+	return &internal.HistoryEntry{ID: perfops.TestID(ref), Type: testType}, nil
+}
+
+// fetchResults retrieves the output of a test and returns its results.
+func fetchResults(c *perfops.Client, e *internal.HistoryEntry) ([]*internal.Result, error) {
+	ctx := context.Background()
+	switch e.Type {
+	case "dnsperf", "resolve":
+		getOutput := c.Run.DNSPerfOutput
+		if e.Type == "resolve" {
+			getOutput = c.Run.DNSResolveOutput
+		}
+		output, err := getOutput(ctx, e.ID)
+		if err != nil {
+			return nil, err
+		}
+		return internal.DNSResults(e.Type, output), nil
+	}
+	getOutput := runOutputFunc(c, e.Type)
+	if getOutput == nil {
+		return nil, fmt.Errorf("unsupported test type '%s'", e.Type)
+	}
+	output, err := getOutput(ctx, e.ID)
+	if err != nil {
+		return nil, err
+	}
+	return internal.RunResults(e.Type, output), nil
+}
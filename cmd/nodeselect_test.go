@@ -0,0 +1,153 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+func TestSelectNodes(t *testing.T) {
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+	os.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CONFIG_HOME")
+
+	c, err := newTestPerfopsClient(&recordingTransport{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// Nothing to select
+	gotFrom, gotIDs, gotLimit, err := selectNodes(c, "ping", "Europe", nil, 3)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if gotFrom != "Europe" || gotIDs != nil || gotLimit != 3 {
+		t.Fatalf("expected arguments unchanged; got %v, %v, %v", gotFrom, gotIDs, gotLimit)
+	}
+
+	p, err := catalogPath()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	cat := &internal.Catalog{Updated: time.Now(), Nodes: []*perfops.Node{
+		{ID: 5, Country: &perfops.Country{Name: "Germany", ISO: "DE", Continent: &perfops.Continent{Name: "Europe", ISO: "EU"}}},
+		{ID: 7, Country: &perfops.Country{Name: "France", ISO: "FR", Continent: &perfops.Continent{Name: "Europe", ISO: "EU"}}},
+	}}
+	if err := cat.Save(p); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	cp, err := configPath()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	os.MkdirAll(filepath.Dir(cp), 0755)
+	if err := ioutil.WriteFile(cp, []byte(`{"exclude_nodes": [5]}`), 0644); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// Configured exclusions apply to every run.
+	gotFrom, gotIDs, gotLimit, err = selectNodes(c, "ping", "Europe", nil, 3)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if exp := []int{7}; gotFrom != "" || !reflect.DeepEqual(gotIDs, exp) || gotLimit != 1 {
+		t.Fatalf("expected %v; got %v, %v, %v", exp, gotFrom, gotIDs, gotLimit)
+	}
+	if len(selection.Filter.ExcludeNodes) != 0 {
+		t.Fatalf("expected flags not to be modified; got %v", selection.Filter.ExcludeNodes)
+	}
+
+	// Explicit node IDs are filtered client-side.
+	if _, gotIDs, _, err = selectNodes(c, "ping", "", []int{5, 7}, 2); err != nil || !reflect.DeepEqual(gotIDs, []int{7}) {
+		t.Fatalf("expected [7]; got %v, %v", gotIDs, err)
+	}
+
+	// Without a location the nodes are picked from the whole catalog.
+	if _, gotIDs, _, err = selectNodes(c, "ping", "", nil, 5); err != nil || !reflect.DeepEqual(gotIDs, []int{7}) {
+		t.Fatalf("expected [7]; got %v, %v", gotIDs, err)
+	}
+
+	// The catalog has no nodes in US states to select from.
+	for name, sel := range map[string]internal.NodeSelection{
+		"Exclusions": {},
+		"Selector":   {Filter: perfops.NodeFilter{Countries: []string{"FR"}}},
+	} {
+		selection = sel
+		_, _, _, err = selectNodes(c, "ping", "Texas", nil, 3)
+		if exp := "no nodes known in 'Texas', select the nodes by country, city or continent instead"; err == nil || err.Error() != exp {
+			t.Fatalf("%s: expected %q; got %v", name, exp, err)
+		}
+	}
+	selection = internal.NodeSelection{}
+}
+
+func TestSameNodes(t *testing.T) {
+	os.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CONFIG_HOME")
+
+	tr := &recordingTransport{}
+	c, err := newTestPerfopsClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	p := filepath.Join(t.TempDir(), "ping.json")
+	ioutil.WriteFile(p, []byte(`{"id":"abc","finished":true,"nodes":[5,27]}`), 0644)
+	ids, err := sameNodes(c, "ping", p)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if exp := []int{5, 27}; !reflect.DeepEqual(ids, exp) {
+		t.Fatalf("expected %v; got %v", exp, ids)
+	}
+
+	if _, err := sameNodes(c, "ping", "@1"); err == nil || err.Error() != "history entry '@1' not found" {
+		t.Fatalf("expected history error; got %v", err)
+	}
+	hp, err := historyPath()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	internal.AppendHistory(hp, &internal.HistoryEntry{ID: "abc123", Type: "resolve", Target: "example.com", Time: time.Now()})
+	sameNodes(c, "ping", "@1")
+	if got, exp := tr.req.URL.Path, "/run/dns-resolve/abc123"; got != exp {
+		t.Fatalf("expected %v; got %v", exp, got)
+	}
+	sameNodes(c, "mtr", "def456")
+	if got, exp := tr.req.URL.Path, "/run/mtr/def456"; got != exp {
+		t.Fatalf("expected %v; got %v", exp, got)
+	}
+
+	sameNodesAs = p
+	defer func() { sameNodesAs = "" }()
+	if _, _, _, err := selectNodes(c, "ping", "Europe", nil, 1); err == nil {
+		t.Fatal("expected error combining --same-nodes-as and --from; got nil")
+	}
+	from, ids, limit, err := selectNodes(c, "ping", "", nil, 1)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if exp := []int{5, 27}; from != "" || !reflect.DeepEqual(ids, exp) || limit != 2 {
+		t.Fatalf("expected %v; got %v, %v, %v", exp, from, ids, limit)
+	}
+}
@@ -46,6 +46,7 @@ func initPingCmd(parentCmd *cobra.Command) {
 	addCommonFlags(pingCmd)
 	pingCmd.Flags().IntVarP(&pingLimit, "limit", "L", 1, "The maximum number of nodes to use")
 	pingCmd.Flags().BoolVarP(&pingIpv6, "ipv6", "6", false, "Use IPv6")
+	pingCmd.Flags().BoolVarP(&resultView.Aggregate, "aggregate", "", false, aggregateUsage)
 	parentCmd.AddCommand(pingCmd)
 }
 
@@ -55,5 +56,19 @@ func runPing(c *perfops.Client, target, from string, nodeIDs []int, limit int, i
 	if ipv6 {
 		ipversion = 6
 	}
-	return internal.RunTest(ctx, target, from, nodeIDs, limit, ipversion, debug, outputJSON, c.Run.Ping, c.Run.PingOutput)
+	from, nodeIDs, limit, err := selectNodes(c, "ping", from, nodeIDs, limit)
+	if err != nil {
+		return err
+	}
+	if err := prepareView(c); err != nil {
+		return err
+	}
+	if dryRun {
+		req, err := internal.NewRunRequest(target, from, nodeIDs, limit, ipversion)
+		if err != nil {
+			return err
+		}
+		return printPlan(c, "ping", target, from, nodeIDs, limit, req)
+	}
+	return internal.RunTest(ctx, "ping", target, from, nodeIDs, limit, ipversion, debug, outputFormat(), &resultView, withHistory("ping", c.Run.Ping), c.Run.PingOutput)
 }
@@ -0,0 +1,90 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+var (
+	reportCmd = &cobra.Command{
+		Use:     "report",
+		Short:   "Generate reports of previously run tests",
+		Long:    `Generate reports of previously run tests to attach them to tickets or share them.`,
+		Example: `perfops report html --file report.html @1`,
+	}
+
+	reportHTMLCmd = &cobra.Command{
+		Use:   "html [test-id|@n]...",
+		Short: "Generate a self-contained HTML report of previously run tests",
+		Long: `Generate a single offline HTML file with sortable tables, a map of the nodes,
+histograms and the raw output of each node of one or more previously run tests.
+Tests are given by their test ID or by @n for the n-th most recent test in the
+local history.`,
+		Example: `perfops report html --file incident.html @2 @1`,
+		Args:    cobra.MinimumNArgs(1),
+		Annotations: map[string]string{
+			completionAnnotation: "testid",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newPerfOpsClient()
+			if err != nil {
+				return err
+			}
+			return chkRunError(runReportHTML(c, args, reportType, reportTitle, reportFile))
+		},
+	}
+
+	reportFile  string
+	reportTitle string
+	reportType  string
+)
+
+func initReportCmd(parentCmd *cobra.Command) {
+	reportHTMLCmd.Flags().StringVarP(&reportFile, "file", "f", "", "The file to write the report to instead of the standard output")
+	reportHTMLCmd.Flags().StringVarP(&reportTitle, "title", "", "PerfOps report", "The title of the report")
+	reportHTMLCmd.Flags().StringVarP(&reportType, "type", "T", "", "The type of tests not in the history. One of: "+strings.Join(testTypes, ", "))
+	setFlagCompletion(reportHTMLCmd.Flags(), "type", "testtype")
+	reportCmd.AddCommand(reportHTMLCmd)
+	parentCmd.AddCommand(reportCmd)
+}
+
+func runReportHTML(c *perfops.Client, refs []string, testType, title, file string) error {
+	var runs []*internal.ReportRun
+	for _, ref := range refs {
+		e, err := findTest(ref, testType)
+		if err != nil {
+			return err
+		}
+		results, err := fetchResults(c, e)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, &internal.ReportRun{ID: string(e.ID), Type: e.Type, Target: e.Target, Time: e.Time, Results: results})
+	}
+	cat, err := loadCatalog(c)
+	if err != nil {
+		return err
+	}
+	if file == "" {
+		return internal.WriteHTMLReport(os.Stdout, title, runs, cat.Nodes)
+	}
+	return internal.WriteHTMLReportFile(file, title, runs, cat.Nodes)
+}
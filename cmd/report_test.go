@@ -0,0 +1,60 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+)
+
+func TestRunReportHTML(t *testing.T) {
+	os.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CONFIG_HOME")
+
+	tr := &recordingTransport{}
+	c, err := newTestPerfopsClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	hp, err := historyPath()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	internal.AppendHistory(hp, &internal.HistoryEntry{ID: "abc123", Type: "curl", Target: "https://example.com", Time: time.Now()})
+
+	testCases := map[string]struct {
+		ref      string
+		testType string
+		exp      string
+	}{
+		"History": {"@1", "", "/run/curl/abc123"},
+		"Test ID": {"def456", "dnsperf", "/run/dns-perf/def456"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if err := runReportHTML(c, []string{tc.ref}, tc.testType, "Report", ""); err == nil {
+				t.Fatal("expected error; got nil")
+			}
+			if got := tr.req.URL.Path; got != tc.exp {
+				t.Fatalf("expected %v; got %v", tc.exp, got)
+			}
+		})
+	}
+	if err := runReportHTML(c, []string{"@2"}, "", "Report", ""); err == nil || err.Error() != "history entry '@2' not found" {
+		t.Fatalf("expected history error; got %v", err)
+	}
+}
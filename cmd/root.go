@@ -18,10 +18,12 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"strings"
 
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
 
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
 	"github.com/ProspectOne/perfops-cli/perfops"
 )
 
@@ -34,14 +36,19 @@ const versionTmpl = `perfops:
 	platform:    %s/%s
 `
 
+// aggregateUsage is the help of the --aggregate flag of the test
+// commands that support it.
+const aggregateUsage = "Add tables of the results aggregated by continent, region, country and ASN"
+
 var (
 	// rootCmd is the root command of the application.
 	rootCmd = &cobra.Command{
-		Use:          "perfops",
-		Short:        "perfops is a simple command line tool to interact with hunderds of servers around the world.",
-		Long:         `perfops is a simple command line tool to interact with hunderds of servers around the world. Run benchmarks and debug your infrastructure without leaving your console.`,
-		Example:      `perfops traceroute --from "New York" google.com`,
-		SilenceUsage: true,
+		Use:               "perfops",
+		Short:             "perfops is a simple command line tool to interact with hunderds of servers around the world.",
+		Long:              `perfops is a simple command line tool to interact with hunderds of servers around the world. Run benchmarks and debug your infrastructure without leaving your console.`,
+		Example:           `perfops traceroute --from "New York" google.com`,
+		SilenceUsage:      true,
+		PersistentPreRunE: preRun,
 		Run: func(cmd *cobra.Command, args []string) {
 			if showVersion {
 				cmd.Printf(versionTmpl,
@@ -57,10 +64,16 @@ var (
 	apiKey      string
 	showVersion bool
 	debug       bool
+	noProgress  bool
+	quiet       bool
+	colorMode   string
 
 	from       string
 	nodeIDs    []int
 	outputJSON bool
+	testOutput string
+	reportSpec string
+	resultView internal.View
 
 	// Version information set at build time
 	version    = "devel"
@@ -77,9 +90,17 @@ func Execute() error {
 	initTracerouteCmd(rootCmd)
 	initDNSPerfCmd(rootCmd)
 	initDNSResolveCmd(rootCmd)
+	initDNSCmd(rootCmd)
 	initCurlCmd(rootCmd)
 	initCreditsCmd(rootCmd)
+	initUsageCmd(rootCmd)
 	initListCmd(rootCmd)
+	initNodesCmd(rootCmd)
+	initCacheCmd(rootCmd)
+	initHistoryCmd(rootCmd)
+	initFetchCmd(rootCmd)
+	initReportCmd(rootCmd)
+	initCompletionCmd(rootCmd)
 	return rootCmd.Execute()
 }
 
@@ -90,13 +111,55 @@ func initRootCmd() {
 	rootCmd.PersistentFlags().StringVarP(&apiKey, "key", "K", "", "The PerfOps API key (default is $PERFOPS_API_KEY)")
 	rootCmd.PersistentFlags().BoolVarP(&showVersion, "version", "v", false, "Prints the version information of perfops")
 	rootCmd.PersistentFlags().BoolVarP(&debug, "debug", "", false, "Enables debug output")
+	rootCmd.PersistentFlags().BoolVarP(&noProgress, "no-progress", "", false, "Do not show spinners and live updates of the output")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only print the final results")
+	rootCmd.PersistentFlags().StringVarP(&colorMode, "color", "", internal.ColorAuto, "Color the output, one of: "+strings.Join(internal.ColorModes, ", "))
+	setFlagCompletion(rootCmd.PersistentFlags(), "color", "color")
+}
+
+// preRun configures the terminal output and validates the --from flag.
+func preRun(cmd *cobra.Command, args []string) error {
+	if err := internal.SetOutputMode(noProgress, quiet, colorMode); err != nil {
+		return err
+	}
+	return validateFrom(cmd, args)
 }
 
 // Common Flags for almost all tests we have
 func addCommonFlags(cmd *cobra.Command) {
-	cmd.PersistentFlags().StringVarP(&from, "from", "F", "", "A continent, region (e.g eastern europe), country, US state or city")
-	cmd.PersistentFlags().IntSliceVarP(&nodeIDs, "nodeid", "N", []int{}, "A comma separated list of node IDs to run a test from")
+	addNodeFlags(cmd)
 	cmd.PersistentFlags().BoolVarP(&outputJSON, "json", "J", false, "Print the result of a command in JSON format")
+	cmd.PersistentFlags().StringVarP(&testOutput, "output", "o", internal.OutputText, "The output format. One of: "+strings.Join(internal.OutputFormats, ", "))
+	cmd.PersistentFlags().StringVarP(&resultView.Sort, "sort", "", "", "Sort the results by one of: "+strings.Join(internal.SortKeys, ", "))
+	cmd.PersistentFlags().StringVarP(&resultView.GroupBy, "group-by", "", "", "Group the results by one of: "+strings.Join(internal.GroupKeys, ", "))
+	cmd.PersistentFlags().IntVarP(&resultView.Top, "top", "", 0, "Show only the first N results of each group")
+	cmd.PersistentFlags().IntVarP(&resultView.Bottom, "bottom", "", 0, "Show only the last N results of each group")
+	cmd.PersistentFlags().StringVarP(&resultView.Mode, "view", "", internal.ViewList, "Show the results as a list or on a world map, one of: "+strings.Join(internal.ViewModes, ", "))
+	cmd.PersistentFlags().BoolVarP(&resultView.TUI, "tui", "", false, "Show the results in a full-screen dashboard while the test runs")
+	cmd.PersistentFlags().IntVarP(&maxCredits, "max-credits", "", 0, "Refuse to run the test if it needs more than this number of credits")
+	cmd.PersistentFlags().BoolVarP(&dryRun, "dry-run", "", false, "Validate the test, select its nodes and estimate its credits without running it")
+	cmd.PersistentFlags().StringVarP(&reportSpec, "report", "", "", "Write a self-contained HTML report of the results, e.g., html=report.html")
+	setFlagCompletion(cmd.PersistentFlags(), "sort", "sortkey")
+	setFlagCompletion(cmd.PersistentFlags(), "group-by", "groupkey")
+	setFlagCompletion(cmd.PersistentFlags(), "view", "viewmode")
+	setFlagCompletion(cmd.PersistentFlags(), "output", "output")
+}
+
+// addNodeFlags adds the flags selecting the nodes of a test.
+func addNodeFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVarP(&from, "from", "F", "", "A continent, region (e.g eastern europe), country, US state or city, or per-location node limits, e.g., Europe:5,Asia:3")
+	cmd.PersistentFlags().IntSliceVarP(&nodeIDs, "nodeid", "N", []int{}, "A comma separated list of node IDs to run a test from")
+	cmd.PersistentFlags().StringVarP(&sameNodesAs, "same-nodes-as", "", "", "Run a test from the nodes of a previous test given by test ID, @n for the n-th most recent test or JSON output file")
+	cmd.PersistentFlags().IntSliceVarP(&selection.Filter.ExcludeNodes, "exclude-node", "", []int{}, "A comma separated list of node IDs not to run a test from")
+	cmd.PersistentFlags().StringSliceVarP(&selection.Filter.ExcludeCountries, "exclude-country", "", []string{}, "A comma separated list of country names or ISO codes not to run a test from")
+	cmd.PersistentFlags().IntSliceVarP(&selection.Filter.ASNs, "asn", "", []int{}, "A comma separated list of AS numbers to run a test from")
+	cmd.PersistentFlags().BoolVarP(&selection.Filter.IPv6, "ipv6-capable", "", false, "Only run a test from nodes able to run tests over IPv6")
+	cmd.PersistentFlags().IntVarP(&selection.MaxPerCountry, "max-per-country", "", 0, "The maximum number of nodes to use per country")
+	setFlagCompletion(cmd.PersistentFlags(), "from", "location")
+	setFlagCompletion(cmd.PersistentFlags(), "nodeid", "nodeid")
+	setFlagCompletion(cmd.PersistentFlags(), "same-nodes-as", "testid")
+	setFlagCompletion(cmd.PersistentFlags(), "exclude-node", "nodeid")
+	setFlagCompletion(cmd.PersistentFlags(), "exclude-country", "country")
 }
 
 // newPerfOpsClient returns a perfops.Client object initialized with the
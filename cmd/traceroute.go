@@ -55,5 +55,19 @@ func runTraceroute(c *perfops.Client, target, from string, nodeIDs []int, limit
 	if ipv6 {
 		ipversion = 6
 	}
-	return internal.RunTest(ctx, target, from, nodeIDs, limit, ipversion, debug, outputJSON, c.Run.Traceroute, c.Run.TracerouteOutput)
+	from, nodeIDs, limit, err := selectNodes(c, "traceroute", from, nodeIDs, limit)
+	if err != nil {
+		return err
+	}
+	if err := prepareView(c); err != nil {
+		return err
+	}
+	if dryRun {
+		req, err := internal.NewRunRequest(target, from, nodeIDs, limit, ipversion)
+		if err != nil {
+			return err
+		}
+		return printPlan(c, "traceroute", target, from, nodeIDs, limit, req)
+	}
+	return internal.RunTest(ctx, "traceroute", target, from, nodeIDs, limit, ipversion, debug, outputFormat(), &resultView, withHistory("traceroute", c.Run.Traceroute), c.Run.TracerouteOutput)
 }
@@ -0,0 +1,64 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+)
+
+var (
+	usageCmd = &cobra.Command{
+		Use:   "usage",
+		Short: "Show the credits used over time",
+		Long: `Show the tests run and the credits they used by day and by test type, from the local history.
+
+Only tests run with this machine are included. Tests run before credits were recorded count towards the tests but not the credits.`,
+		Example: `perfops usage --days 7`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUsage(usageDays)
+		},
+	}
+
+	usageDays int
+)
+
+func initUsageCmd(parentCmd *cobra.Command) {
+	usageCmd.Flags().IntVar(&usageDays, "days", 30, "The number of days to report, including today")
+	usageCmd.Flags().BoolVarP(&outputJSON, "json", "J", false, "Print the result of a command in JSON format")
+	parentCmd.AddCommand(usageCmd)
+}
+
+func runUsage(days int) error {
+	if days < 1 {
+		return errors.New("--days must be at least 1")
+	}
+	entries, err := readHistory()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), now.Day()-days+1, 0, 0, 0, 0, time.Local)
+	u := internal.NewUsage(entries, since)
+	if outputJSON {
+		return internal.PrintOutputJSON(u)
+	}
+	return internal.PrintUsage(os.Stdout, u)
+}
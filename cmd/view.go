@@ -0,0 +1,75 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+	"github.com/ProspectOne/perfops-cli/perfops"
+)
+
+// outputFormat returns the output format of a test command. --json is
+// short for --output json.
+func outputFormat() string {
+	if outputJSON {
+		return internal.OutputJSON
+	}
+	if testOutput == "" {
+		return internal.OutputText
+	}
+	return testOutput
+}
+
+// prepareView validates the result view, the output format and the
+// report, and sets up the credit account of the test. The nodes of the
+// node catalog are added to place the result nodes on the map, in the
+// report or in the GeoJSON and KML output.
+func prepareView(c *perfops.Client) error {
+	if err := resultView.Validate(); err != nil {
+		return err
+	}
+	credits, err := creditAccount(c)
+	if err != nil {
+		return err
+	}
+	resultView.Credits = credits
+	format := outputFormat()
+	switch format {
+	case internal.OutputText, internal.OutputJSON, internal.OutputGeoJSON, internal.OutputKML, internal.OutputMarkdown:
+	default:
+		return fmt.Errorf("unsupported output format '%s'", format)
+	}
+	if resultView.TUI && format != internal.OutputText {
+		return fmt.Errorf("--tui cannot be combined with --output %s", format)
+	}
+	resultView.Report = ""
+	if reportSpec != "" {
+		path, err := internal.ParseReport(reportSpec)
+		if err != nil {
+			return err
+		}
+		resultView.Report = path
+	}
+	if resultView.Mode != internal.ViewMap && resultView.Report == "" &&
+		format != internal.OutputGeoJSON && format != internal.OutputKML {
+		return nil
+	}
+	cat, err := loadCatalog(c)
+	if err != nil {
+		return err
+	}
+	resultView.Nodes = cat.Nodes
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ProspectOne/perfops-cli/cmd/internal"
+)
+
+func TestOutputFormat(t *testing.T) {
+	defer func() { outputJSON, testOutput, resultView.TUI = false, "", false }()
+	testCases := map[string]struct {
+		json   bool
+		output string
+		tui    bool
+		exp    string
+		expErr bool
+	}{
+		"Default":  {false, "", false, internal.OutputText, false},
+		"JSON":     {true, internal.OutputText, false, internal.OutputJSON, false},
+		"KML":      {false, internal.OutputKML, false, internal.OutputKML, false},
+		"Invalid":  {false, "yaml", false, "yaml", true},
+		"TUI JSON": {true, "", true, internal.OutputJSON, true},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			outputJSON, testOutput, resultView.TUI = tc.json, tc.output, tc.tui
+			if got := outputFormat(); got != tc.exp {
+				t.Fatalf("expected %v; got %v", tc.exp, got)
+			}
+			if tc.expErr {
+				if err := prepareView(nil); err == nil {
+					t.Fatal("expected error; got nil")
+				}
+			}
+		})
+	}
+}
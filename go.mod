@@ -4,12 +4,10 @@ go 1.19
 
 require (
 	github.com/gosuri/uilive v0.0.3
+	github.com/mattn/go-isatty v0.0.9
 	github.com/spf13/cobra v0.0.5
 	github.com/spf13/pflag v1.0.5
+	golang.org/x/sys v0.0.0-20191002091554-b397fe3ad8ed
 )
 
-require (
-	github.com/inconshreveable/mousetrap v1.0.0 // indirect
-	github.com/mattn/go-isatty v0.0.9 // indirect
-	golang.org/x/sys v0.0.0-20191002091554-b397fe3ad8ed // indirect
-)
+require github.com/inconshreveable/mousetrap v1.0.0 // indirect
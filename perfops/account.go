@@ -0,0 +1,102 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// AccountService defines the interface for the account API
+	AccountService service
+
+	// Credits represents the credits of an API key.
+	Credits struct {
+		// Remaining is the number of remaining credits.
+		Remaining int `json:"remaining_credits"`
+		// Unlimited is true if the credits of the key are not limited.
+		Unlimited bool `json:"unlimited,omitempty"`
+		// Plan is the name of the plan of the key, if available.
+		Plan string `json:"plan,omitempty"`
+		// ResetAt is the time the credits are reset, if available.
+		ResetAt *time.Time `json:"reset_at,omitempty"`
+	}
+)
+
+// Credits retrieves the credits of the API key from the server.
+func (s *AccountService) Credits(ctx context.Context) (*Credits, error) {
+	u := s.client.BasePath + "/remaining-credits"
+	req, _ := http.NewRequest("GET", u, nil)
+	req = req.WithContext(ctx)
+	var v *Credits
+	if err := s.client.do(req, &v); err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("no credits returned")
+	}
+	return v, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The remaining credits are a
+// number, a numeric string or "unlimited". The unlimited key of marshaled
+// credits is read as well.
+func (c *Credits) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Remaining json.RawMessage `json:"remaining_credits"`
+		Unlimited bool            `json:"unlimited"`
+		Plan      string          `json:"plan"`
+		ResetAt   *time.Time      `json:"reset_at"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*c = Credits{Unlimited: raw.Unlimited, Plan: raw.Plan, ResetAt: raw.ResetAt}
+	if len(raw.Remaining) == 0 || string(raw.Remaining) == "null" {
+		return nil
+	}
+	var n float64
+	if err := json.Unmarshal(raw.Remaining, &n); err == nil {
+		c.Remaining = int(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(raw.Remaining, &s); err != nil {
+		return fmt.Errorf("invalid remaining credits %s", raw.Remaining)
+	}
+	if strings.EqualFold(s, "unlimited") {
+		c.Unlimited = true
+		return nil
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid remaining credits %q", s)
+	}
+	c.Remaining = int(n)
+	return nil
+}
+
+// String returns the remaining credits.
+func (c *Credits) String() string {
+	if c.Unlimited {
+		return "unlimited"
+	}
+	return strconv.Itoa(c.Remaining)
+}
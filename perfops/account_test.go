@@ -0,0 +1,81 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfops
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCredits(t *testing.T) {
+	reset := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	testCases := map[string]struct {
+		body string
+		exp  *Credits
+		err  bool
+	}{
+		"Numeric":   {`{"remaining_credits": 5}`, &Credits{Remaining: 5}, false},
+		"String":    {`{"remaining_credits": "42"}`, &Credits{Remaining: 42}, false},
+		"Unlimited": {`{"remaining_credits": "unlimited"}`, &Credits{Unlimited: true}, false},
+		"Plan":      {`{"remaining_credits": 900, "plan": "Pro", "reset_at": "2026-11-01T00:00:00Z"}`, &Credits{Remaining: 900, Plan: "Pro", ResetAt: &reset}, false},
+		"Invalid":   {`{"remaining_credits": "many"}`, nil, true},
+		"Marshaled": {`{"remaining_credits": 0, "unlimited": true}`, &Credits{Unlimited: true}, false},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tr := &respondingTransport{resp: dummyResp(200, "GET", tc.body)}
+			c, err := newTestClient(tr)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			got, err := c.Account.Credits(context.Background())
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error; got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.exp) {
+				t.Fatalf("expected %+v; got %+v", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestCreditsRoundTrip(t *testing.T) {
+	reset := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	for _, exp := range []*Credits{
+		{Remaining: 12},
+		{Unlimited: true, Plan: "Enterprise"},
+		{Remaining: 900, Plan: "Pro", ResetAt: &reset},
+	} {
+		b, err := json.Marshal(exp)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		var got *Credits
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if !reflect.DeepEqual(got, exp) {
+			t.Fatalf("expected %+v; got %+v from %s", exp, got, b)
+		}
+	}
+}
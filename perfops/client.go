@@ -45,8 +45,11 @@ type (
 		UserAgent string // optional additional User-Agent fragment
 		apiKey    string
 
-		DNS *DNSService
-		Run *RunService
+		Account *AccountService
+		DNS     *DNSService
+		Geo     *GeoService
+		Nodes   *NodeService
+		Run     *RunService
 	}
 
 	service struct {
@@ -118,7 +121,10 @@ func NewClient(opts ...func(c *Client) error) (*Client, error) {
 		}
 	}
 
+	c.Account = (*AccountService)(&c.common)
 	c.DNS = (*DNSService)(&c.common)
+	c.Geo = (*GeoService)(&c.common)
+	c.Nodes = (*NodeService)(&c.common)
 	c.Run = (*RunService)(&c.common)
 
 	return c, nil
@@ -15,8 +15,6 @@ package perfops
 
 import (
 	"context"
-	"net/http"
-	"reflect"
 )
 
 type (
@@ -24,22 +22,17 @@ type (
 	DNSService service
 )
 
-// RemainingCredits retrieves the ramining credits from the server.
+// RemainingCredits retrieves the ramining credits from the server. The
+// credits are an int or "unlimited".
+//
+// Deprecated: Use AccountService.Credits.
 func (s *DNSService) RemainingCredits(ctx context.Context) (interface{}, error) {
-	u := s.client.BasePath + "/remaining-credits"
-	req, _ := http.NewRequest("GET", u, nil)
-	var v *struct {
-		Val interface{} `json:"remaining_credits"`
-	}
-	err := s.client.do(req, &v)
+	credits, err := (*AccountService)(s).Credits(ctx)
 	if err != nil {
 		return 0, err
 	}
-
-	credits := v.Val
-	if "float64" == reflect.TypeOf(v.Val).String() {
-		credits = int(v.Val.(float64))
+	if credits.Unlimited {
+		return credits.String(), nil
 	}
-
-	return credits, nil
+	return credits.Remaining, nil
 }
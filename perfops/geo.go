@@ -0,0 +1,101 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfops
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+type (
+	// GeoService defines the interface for the locations PerfOps nodes
+	// are present in.
+	GeoService service
+)
+
+// Countries retrieves the countries with PerfOps nodes.
+func (s *GeoService) Countries(ctx context.Context) ([]*Country, error) {
+	var v []*Country
+	if err := s.get(ctx, "/analytics/dns/countries", &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Cities retrieves the cities with PerfOps nodes.
+func (s *GeoService) Cities(ctx context.Context) ([]*City, error) {
+	var v []*City
+	if err := s.get(ctx, "/analytics/dns/city", &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Continents retrieves the continents with PerfOps nodes ordered by
+// name.
+func (s *GeoService) Continents(ctx context.Context) ([]*Continent, error) {
+	countries, err := s.Countries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var res []*Continent
+	for _, c := range countries {
+		if c.Continent == nil || seen[c.Continent.Name] {
+			continue
+		}
+		seen[c.Continent.Name] = true
+		res = append(res, c.Continent)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// Regions retrieves the sub-regions with PerfOps nodes ordered by
+// continent and name.
+func (s *GeoService) Regions(ctx context.Context) ([]*Region, error) {
+	nodes, err := (*NodeService)(s).List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var res []*Region
+	for _, n := range nodes {
+		if n.SubRegion == "" || seen[strings.ToLower(n.SubRegion)] {
+			continue
+		}
+		seen[strings.ToLower(n.SubRegion)] = true
+		r := &Region{Name: n.SubRegion}
+		if n.Country != nil {
+			r.Continent = n.Country.Continent
+		}
+		res = append(res, r)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		ci, cj := res[i].Continent.GetName(), res[j].Continent.GetName()
+		if ci != cj {
+			return ci < cj
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
+
+func (s *GeoService) get(ctx context.Context, path string, v interface{}) error {
+	req, _ := http.NewRequest("GET", s.client.BasePath+path, nil)
+	req = req.WithContext(ctx)
+	return s.client.do(req, v)
+}
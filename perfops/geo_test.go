@@ -0,0 +1,145 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfops
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+const testCountriesBody = `[{"id": 116,"name": "Germany","iso": "DE","isoNumeric": "276","continent": {"id": 3,"name": "Europe","iso": "EU"}},{"id": 195,"name": "Hong Kong","iso": "HK","isoNumeric": 344,"continent": {"id": 2,"name": "Asia","iso": "AS"}},{"id": 75,"name": "France","iso": "FR","iso_numeric": "250","continent": {"id": 3,"name": "Europe","iso": "EU"}}]`
+
+func TestGeoCountries(t *testing.T) {
+	ctx := context.Background()
+	tr := &testTransport{resp: dummyResp(200, "GET", testCountriesBody)}
+	c, err := newTestClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	countries, err := c.Geo.Countries(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got, exp := tr.req.URL.Path, "/analytics/dns/countries"; got != exp {
+		t.Fatalf("expected path %v; got %v", exp, got)
+	}
+	for i, exp := range []ISONumeric{276, 344, 250} {
+		if got := countries[i].ISONumeric; got != exp {
+			t.Fatalf("expected ISO numeric %v for %v; got %v", exp, countries[i].Name, got)
+		}
+	}
+}
+
+func TestGeoCities(t *testing.T) {
+	ctx := context.Background()
+	body := `[{"name": "Frankfurt","country": {"name": "Germany","iso": "DE"},"continent": {"name": "Europe"}}]`
+	tr := &testTransport{resp: dummyResp(200, "GET", body)}
+	c, err := newTestClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	cities, err := c.Geo.Cities(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got, exp := tr.req.URL.Path, "/analytics/dns/city"; got != exp {
+		t.Fatalf("expected path %v; got %v", exp, got)
+	}
+	if got, exp := cities[0].Country.ISO, "DE"; got != exp {
+		t.Fatalf("expected %v; got %v", exp, got)
+	}
+}
+
+func TestGeoContinents(t *testing.T) {
+	ctx := context.Background()
+	tr := &respondingTransport{resp: dummyResp(200, "GET", testCountriesBody)}
+	c, err := newTestClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	continents, err := c.Geo.Continents(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var got []string
+	for _, c := range continents {
+		got = append(got, c.Name)
+	}
+	if exp := "[Asia Europe]"; fmt.Sprint(got) != exp {
+		t.Fatalf("expected %v; got %v", exp, got)
+	}
+}
+
+func TestGeoRegions(t *testing.T) {
+	ctx := context.Background()
+	tr := &respondingTransport{resp: dummyResp(200, "GET", testNodesBody)}
+	c, err := newTestClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	regions, err := c.Geo.Regions(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var got []string
+	for _, r := range regions {
+		got = append(got, r.Continent.Name+"/"+r.Name)
+	}
+	if exp := "[Asia/Eastern Asia Europe/Western Europe]"; fmt.Sprint(got) != exp {
+		t.Fatalf("expected %v; got %v", exp, got)
+	}
+}
+
+func TestISONumericUnmarshal(t *testing.T) {
+	testCases := map[string]struct {
+		data string
+		exp  ISONumeric
+	}{
+		"Number": {`276`, 276},
+		"String": {`"276"`, 276},
+		"Empty":  {`""`, 0},
+		"Null":   {`null`, 0},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var n ISONumeric
+			if err := n.UnmarshalJSON([]byte(tc.data)); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if n != tc.exp {
+				t.Fatalf("expected %v; got %v", tc.exp, n)
+			}
+		})
+	}
+	var n ISONumeric
+	if err := n.UnmarshalJSON([]byte(`"DE"`)); err == nil {
+		t.Fatal("expected error; got nil")
+	}
+}
+
+func TestGetName(t *testing.T) {
+	var continent *Continent
+	var country *Country
+	if continent.GetName() != "" || country.GetName() != "" {
+		t.Fatal("expected empty names of nil")
+	}
+	continent, country = &Continent{Name: "Europe"}, &Country{Name: "Germany"}
+	if got := continent.GetName(); got != "Europe" {
+		t.Fatalf("expected Europe; got %v", got)
+	}
+	if got := country.GetName(); got != "Germany" {
+		t.Fatalf("expected Germany; got %v", got)
+	}
+}
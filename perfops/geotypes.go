@@ -1,30 +1,94 @@
 package perfops
 
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
 type (
 	// Continent contains information about a continent.
 	Continent struct {
-		ID   int    `json:"id"`
+		ID   int    `json:"id,omitempty"`
 		Name string `json:"name"`
-		ISO  string `json:"iso"`
+		ISO  string `json:"iso,omitempty"`
 	}
 
 	// Country contains information about a country.
 	Country struct {
-		ID         int         `json:"id"`
-		Name       string      `json:"name"`
-		ISO        string      `json:"iso"`
-		ISONumeric interface{} `json:"isoNumeric"` // TODO: Must fix API to return same type for tests and for list endpoints
-		Continent  *Continent  `json:"continent,omitempty"`
+		ID         int        `json:"id,omitempty"`
+		Name       string     `json:"name"`
+		ISO        string     `json:"iso,omitempty"`
+		ISONumeric ISONumeric `json:"isoNumeric,omitempty"`
+		Continent  *Continent `json:"continent,omitempty"`
 	}
 
 	// City contains information about a city.
 	City struct {
-		Name    string `json:"name"`
-		Country *struct {
-			Name string `json:"name"`
-		} `json:"country,omitempty"`
-		Continent *struct {
-			Name string `json:"name"`
-		} `json:"continent,omitempty"`
+		Name      string     `json:"name"`
+		Country   *Country   `json:"country,omitempty"`
+		Continent *Continent `json:"continent,omitempty"`
+	}
+
+	// Region contains information about a sub-region of a continent,
+	// e.g., Eastern Europe.
+	Region struct {
+		Name      string     `json:"name"`
+		Continent *Continent `json:"continent,omitempty"`
 	}
+
+	// ISONumeric is the ISO 3166-1 numeric code of a country. The API
+	// returns it either as a number or as a string.
+	ISONumeric int
 )
+
+// GetName returns the name of the continent or an empty string if c is
+// nil.
+func (c *Continent) GetName() string {
+	if c == nil {
+		return ""
+	}
+	return c.Name
+}
+
+// GetName returns the name of the country or an empty string if c is nil.
+func (c *Country) GetName() string {
+	if c == nil {
+		return ""
+	}
+	return c.Name
+}
+
+// UnmarshalJSON parses the JSON-encoded data. Both the isoNumeric key of
+// the list endpoints and the iso_numeric key of test results are
+// accepted.
+func (c *Country) UnmarshalJSON(data []byte) error {
+	type country Country
+	var v struct {
+		country
+		ISONumericAlt ISONumeric `json:"iso_numeric"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*c = Country(v.country)
+	if c.ISONumeric == 0 {
+		c.ISONumeric = v.ISONumericAlt
+	}
+	return nil
+}
+
+// UnmarshalJSON parses the JSON-encoded data.
+func (n *ISONumeric) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*n = 0
+		return nil
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*n = ISONumeric(i)
+	return nil
+}
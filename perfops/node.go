@@ -13,7 +13,17 @@
 
 package perfops
 
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+)
+
 type (
+	// NodeService defines the interface for the node API
+	NodeService service
+
 	// Node contains informatin about a test node.
 	Node struct {
 		ID        int      `json:"id"`
@@ -23,5 +33,119 @@ type (
 		City      string   `json:"city"`
 		SubRegion string   `json:"sub_region"`
 		Country   *Country `json:"country,omitempty"`
+		// IPv6 is true if the node is able to run tests over IPv6.
+		IPv6 bool `json:"ipv6,omitempty"`
+	}
+
+	// NodeFilter selects nodes by their attributes. Empty fields match
+	// all nodes.
+	NodeFilter struct {
+		// Country names or ISO codes
+		Countries []string
+		// Continent names or ISO codes
+		Continents []string
+		// City names
+		Cities []string
+		// AS numbers
+		ASNs []int
+		// Only match nodes able to run tests over IPv6
+		IPv6 bool
+		// Node IDs never to match
+		ExcludeNodes []int
+		// Country names or ISO codes never to match
+		ExcludeCountries []string
 	}
 )
+
+// List retrieves the catalog of all test nodes ordered by node ID.
+func (s *NodeService) List(ctx context.Context) ([]*Node, error) {
+	u := s.client.BasePath + "/nodes"
+	req, _ := http.NewRequest("GET", u, nil)
+	req = req.WithContext(ctx)
+	var v []*Node
+	if err := s.client.do(req, &v); err != nil {
+		return nil, err
+	}
+	sort.Slice(v, func(i, j int) bool { return v[i].ID < v[j].ID })
+	return v, nil
+}
+
+// CountryName returns the name of the node's country, if known.
+func (n *Node) CountryName() string {
+	if n.Country == nil {
+		return ""
+	}
+	return n.Country.Name
+}
+
+// ContinentName returns the name of the node's continent, if known.
+func (n *Node) ContinentName() string {
+	if n.Country == nil || n.Country.Continent == nil {
+		return ""
+	}
+	return n.Country.Continent.Name
+}
+
+// Match returns a value indicating whether the node matches the filter.
+func (f *NodeFilter) Match(n *Node) bool {
+	if containsInt(f.ExcludeNodes, n.ID) {
+		return false
+	}
+	if n.Country != nil && containsFold(f.ExcludeCountries, n.Country.Name, n.Country.ISO) {
+		return false
+	}
+	if f.IPv6 && !n.IPv6 {
+		return false
+	}
+	if len(f.ASNs) > 0 && !containsInt(f.ASNs, n.AsNumber) {
+		return false
+	}
+	if len(f.Cities) > 0 && !containsFold(f.Cities, n.City) {
+		return false
+	}
+	if len(f.Countries) > 0 {
+		if n.Country == nil || !containsFold(f.Countries, n.Country.Name, n.Country.ISO) {
+			return false
+		}
+	}
+	if len(f.Continents) > 0 {
+		c := n.Country
+		if c == nil || c.Continent == nil || !containsFold(f.Continents, c.Continent.Name, c.Continent.ISO) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterNodes returns the nodes matching the filter.
+func FilterNodes(nodes []*Node, f *NodeFilter) []*Node {
+	var res []*Node
+	for _, n := range nodes {
+		if f.Match(n) {
+			res = append(res, n)
+		}
+	}
+	return res
+}
+
+func containsInt(a []int, v int) bool {
+	for _, i := range a {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold returns a value indicating whether any of the values is
+// in a, ignoring case.
+func containsFold(a []string, values ...string) bool {
+	for _, s := range a {
+		for _, v := range values {
+			if v != "" && strings.EqualFold(s, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfops
+
+import (
+	"context"
+	"testing"
+)
+
+const testNodesBody = `[{"id": 27,"as_number": 9304,"latitude": 22.28512548314,"longitude": 114.17507171631,"country": {"id": 195,"name": "Hong Kong","continent": {"id": 2,"name": "Asia","iso": "AS"},"iso": "HK"},"city": "Hong Kong","sub_region": "Eastern Asia"},{"id": 5,"as_number": 3320,"latitude": 50.110781326572834,"longitude": 8.68984222412098,"country": {"id": 116,"name": "Germany","continent": {"id": 3,"name": "Europe","iso": "EU"},"iso": "DE"},"city": "Frankfurt","sub_region": "Western Europe","ipv6": true},{"id": 12,"as_number": 24940,"latitude": 49.4478,"longitude": 11.0683,"country": {"id": 116,"name": "Germany","continent": {"id": 3,"name": "Europe","iso": "EU"},"iso": "DE"},"city": "Nuremberg","sub_region": "Western Europe"}]`
+
+func TestNodesList(t *testing.T) {
+	ctx := context.Background()
+	tr := &testTransport{resp: dummyResp(200, "GET", testNodesBody)}
+	c, err := newTestClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	nodes, err := c.Nodes.List(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got, exp := tr.req.URL.Path, "/nodes"; got != exp {
+		t.Fatalf("expected path %v; got %v", exp, got)
+	}
+	if got, exp := len(nodes), 3; got != exp {
+		t.Fatalf("expected %v nodes; got %v", exp, got)
+	}
+	for i, id := range []int{5, 12, 27} {
+		if got := nodes[i].ID; got != id {
+			t.Fatalf("expected node %v at %d; got %v", id, i, got)
+		}
+	}
+	if !nodes[0].IPv6 {
+		t.Fatalf("expected node 5 to be IPv6 capable")
+	}
+}
+
+func TestNodeFilter(t *testing.T) {
+	ctx := context.Background()
+	tr := &respondingTransport{resp: dummyResp(200, "GET", testNodesBody)}
+	c, err := newTestClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	nodes, err := c.Nodes.List(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	testCases := map[string]struct {
+		filter NodeFilter
+		exp    []int
+	}{
+		"Empty":              {NodeFilter{}, []int{5, 12, 27}},
+		"Country ISO":        {NodeFilter{Countries: []string{"de"}}, []int{5, 12}},
+		"Country name":       {NodeFilter{Countries: []string{"Hong Kong"}}, []int{27}},
+		"Continent":          {NodeFilter{Continents: []string{"EU"}}, []int{5, 12}},
+		"Continent name":     {NodeFilter{Continents: []string{"asia"}}, []int{27}},
+		"City":               {NodeFilter{Cities: []string{"Nuremberg"}}, []int{12}},
+		"ASN":                {NodeFilter{ASNs: []int{3320, 9304}}, []int{5, 27}},
+		"IPv6":               {NodeFilter{IPv6: true}, []int{5}},
+		"Country and ASN":    {NodeFilter{Countries: []string{"DE"}, ASNs: []int{3320}}, []int{5}},
+		"Exclude nodes":      {NodeFilter{ExcludeNodes: []int{12}}, []int{5, 27}},
+		"Exclude country":    {NodeFilter{ExcludeCountries: []string{"de"}}, []int{27}},
+		"Nothing matches":    {NodeFilter{Countries: []string{"FR"}}, []int{}},
+		"Conflicting fields": {NodeFilter{Continents: []string{"AS"}, Countries: []string{"DE"}}, []int{}},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := FilterNodes(nodes, &tc.filter)
+			if len(got) != len(tc.exp) {
+				t.Fatalf("expected %v nodes; got %v", len(tc.exp), len(got))
+			}
+			for i, id := range tc.exp {
+				if got[i].ID != id {
+					t.Fatalf("expected node %v; got %v", id, got[i].ID)
+				}
+			}
+		})
+	}
+}
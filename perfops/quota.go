@@ -0,0 +1,235 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfops
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// testIDSep separates the IDs of the sub-tests of a composite test ID.
+const testIDSep = ","
+
+type (
+	// LocationQuota represents the maximum number of nodes to use from a
+	// location.
+	LocationQuota struct {
+		Location string `json:"location"`
+		Limit    int    `json:"limit,omitempty"`
+	}
+
+	// LocationQuotas represents a list of per-location node quotas. A
+	// request with quotas runs one sub-test per location and its test ID
+	// combines the IDs of the sub-tests.
+	LocationQuotas []LocationQuota
+
+	// FanOutError is returned if the sub-test of a location failed to
+	// start after the sub-tests of other locations were started. TestID
+	// combines the IDs of the started sub-tests, e.g., to fetch their
+	// results.
+	FanOutError struct {
+		TestID   TestID
+		Location string
+		Err      error
+	}
+)
+
+// Error returns the string representation of the error.
+func (e *FanOutError) Error() string {
+	return fmt.Sprintf("the test in '%s' failed to start: %v (test ID of the started tests: %s)", e.Location, e.Err, e.TestID)
+}
+
+// Unwrap returns the error of the sub-test.
+func (e *FanOutError) Unwrap() error {
+	return e.Err
+}
+
+// IsLocationQuotas returns a value indicating whether s is a list of
+// per-location quotas, e.g., "Europe:5,North America:5,Asia:3".
+func IsLocationQuotas(s string) bool {
+	return strings.Contains(s, ":")
+}
+
+// ParseLocationQuotas parses a comma separated list of locations and
+// their node limits, e.g., "Europe:5,North America:5,Asia:3". A location
+// without a limit uses the limit of the request.
+func ParseLocationQuotas(s string) (LocationQuotas, error) {
+	var res LocationQuotas
+	for _, part := range strings.Split(s, ",") {
+		q := LocationQuota{Location: strings.TrimSpace(part)}
+		if i := strings.LastIndex(part, ":"); i >= 0 {
+			q.Location = strings.TrimSpace(part[:i])
+			limit, err := strconv.Atoi(strings.TrimSpace(part[i+1:]))
+			if err != nil || limit < 1 {
+				return nil, fmt.Errorf("invalid node limit in '%s'", strings.TrimSpace(part))
+			}
+			q.Limit = limit
+		}
+		if q.Location == "" {
+			return nil, fmt.Errorf("missing location in '%s'", s)
+		}
+		res = append(res, q)
+	}
+	return res, nil
+}
+
+// String returns the string representation of the quotas as accepted by
+// ParseLocationQuotas.
+func (q LocationQuotas) String() string {
+	parts := make([]string, len(q))
+	for i, v := range q {
+		parts[i] = v.Location
+		if v.Limit > 0 {
+			parts[i] += ":" + strconv.Itoa(v.Limit)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Total returns the maximum number of nodes of all quotas. Quotas without
+// a limit use defLimit.
+func (q LocationQuotas) Total(defLimit int) int {
+	total := 0
+	for _, v := range q {
+		if v.Limit > 0 {
+			total += v.Limit
+		} else {
+			total += defLimit
+		}
+	}
+	return total
+}
+
+// Parts returns the IDs of the sub-tests of a composite test ID, or the
+// test ID itself.
+func (id TestID) Parts() []TestID {
+	var res []TestID
+	for _, s := range strings.Split(string(id), testIDSep) {
+		res = append(res, TestID(s))
+	}
+	return res
+}
+
+// joinTestIDs returns the composite test ID of the sub-tests.
+func joinTestIDs(ids []TestID) TestID {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = string(id)
+	}
+	return TestID(strings.Join(parts, testIDSep))
+}
+
+// fanOut runs one sub-test per location quota and returns the composite
+// test ID. Quotas without a limit use defLimit. All quotas are validated
+// before any sub-test is started. If a sub-test fails to start, the test
+// ID of the sub-tests started before is returned with a *FanOutError.
+func (s *RunService) fanOut(quotas LocationQuotas, defLimit int, run func(location string, limit int) (TestID, error)) (TestID, error) {
+	if err := quotas.validate(s.client.apiKey, defLimit); err != nil {
+		return "", err
+	}
+	var ids []TestID
+	for _, q := range quotas {
+		limit := q.Limit
+		if limit == 0 {
+			limit = defLimit
+		}
+		id, err := run(q.Location, limit)
+		if err != nil {
+			if len(ids) == 0 {
+				return "", err
+			}
+			partial := joinTestIDs(ids)
+			return partial, &FanOutError{TestID: partial, Location: q.Location, Err: err}
+		}
+		ids = append(ids, id)
+	}
+	return joinTestIDs(ids), nil
+}
+
+// validate returns an error if the sub-test of any quota would be
+// rejected, e.g., for a missing location or a limit too high.
+func (q LocationQuotas) validate(apiKey string, defLimit int) error {
+	for _, v := range q {
+		limit := v.Limit
+		if limit == 0 {
+			limit = defLimit
+		}
+		if strings.TrimSpace(v.Location) == "" {
+			return &argError{"location"}
+		}
+		if !isValidLimit(apiKey, limit) {
+			return &argError{"limit"}
+		}
+	}
+	if !isValidLimit(apiKey, q.Total(defLimit)) {
+		return &argError{"limit"}
+	}
+	return nil
+}
+
+// mergeRunOutputs merges the outputs of the sub-tests of a composite
+// test into a single output.
+func mergeRunOutputs(id TestID, outputs []*RunOutput) *RunOutput {
+	res := &RunOutput{ID: string(id), Finished: true}
+	withdrawn := make([]*int, len(outputs))
+	for i, o := range outputs {
+		if o == nil {
+			res.Finished = false
+			continue
+		}
+		if res.Requested == "" {
+			res.Requested = o.Requested
+		}
+		res.Finished = res.Finished && o.Finished
+		res.Items = append(res.Items, o.Items...)
+		withdrawn[i] = o.CreditsWithdrawn
+	}
+	res.CreditsWithdrawn = sumCredits(withdrawn)
+	return res
+}
+
+// mergeDNSTestOutputs merges the outputs of the sub-tests of a composite
+// test into a single output.
+func mergeDNSTestOutputs(id TestID, outputs []*DNSTestOutput) *DNSTestOutput {
+	res := &DNSTestOutput{ID: string(id), Finished: true}
+	withdrawn := make([]*int, len(outputs))
+	for i, o := range outputs {
+		if o == nil {
+			res.Finished = false
+			continue
+		}
+		if res.Requested == "" {
+			res.Requested = o.Requested
+		}
+		res.Finished = res.Finished && o.Finished
+		res.Items = append(res.Items, o.Items...)
+		withdrawn[i] = o.CreditsWithdrawn
+	}
+	res.CreditsWithdrawn = sumCredits(withdrawn)
+	return res
+}
+
+// sumCredits returns the sum of the credits withdrawn by sub-tests, nil
+// unless every sub-test reported them.
+func sumCredits(withdrawn []*int) *int {
+	sum := 0
+	for _, w := range withdrawn {
+		if w == nil {
+			return nil
+		}
+		sum += *w
+	}
+	return &sum
+}
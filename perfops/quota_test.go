@@ -0,0 +1,178 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// sequenceTransport records the requests and returns the responses in
+// order.
+type sequenceTransport struct {
+	reqs   []*http.Request
+	bodies []string
+	resps  []*http.Response
+}
+
+func (t *sequenceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := ""
+	if req.Body != nil {
+		b, _ := ioutil.ReadAll(req.Body)
+		body = string(b)
+	}
+	t.reqs = append(t.reqs, req)
+	t.bodies = append(t.bodies, body)
+	resp := t.resps[0]
+	t.resps = t.resps[1:]
+	return resp, nil
+}
+
+func TestParseLocationQuotas(t *testing.T) {
+	testCases := map[string]struct {
+		s      string
+		exp    LocationQuotas
+		expErr string
+	}{
+		"Quotas":         {"Europe:5,North America:5,Asia:3", LocationQuotas{{"Europe", 5}, {"North America", 5}, {"Asia", 3}}, ""},
+		"Spaces":         {" Europe : 2 , Asia:1", LocationQuotas{{"Europe", 2}, {"Asia", 1}}, ""},
+		"Default limit":  {"Europe:5,Asia", LocationQuotas{{"Europe", 5}, {"Asia", 0}}, ""},
+		"Invalid limit":  {"Europe:five", nil, "invalid node limit in 'Europe:five'"},
+		"Zero limit":     {"Europe:0", nil, "invalid node limit in 'Europe:0'"},
+		"Empty location": {":3", nil, "missing location in ':3'"},
+		"Trailing comma": {"Europe:3,", nil, "missing location in 'Europe:3,'"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseLocationQuotas(tc.s)
+			if tc.expErr != "" {
+				if err == nil || err.Error() != tc.expErr {
+					t.Fatalf("expected error %v; got %v", tc.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.exp) {
+				t.Fatalf("expected %v; got %v", tc.exp, got)
+			}
+		})
+	}
+	if got, exp := (LocationQuotas{{"Europe", 5}, {"Asia", 0}}).String(), "Europe:5,Asia"; got != exp {
+		t.Fatalf("expected %v; got %v", exp, got)
+	}
+}
+
+func TestRunQuotas(t *testing.T) {
+	ctx := context.Background()
+	tr := &sequenceTransport{resps: []*http.Response{
+		dummyResp(200, "POST", `{"id":"a1"}`),
+		dummyResp(200, "POST", `{"id":"b2"}`),
+	}}
+	c, err := newTestClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	req := &RunRequest{Target: "example.com", Limit: 2, Quotas: LocationQuotas{{"Europe", 5}, {"Asia", 0}}}
+	id, err := c.Run.Ping(ctx, req)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got, exp := id, TestID("a1,b2"); got != exp {
+		t.Fatalf("expected %v; got %v", exp, got)
+	}
+	exp := []string{
+		`{"target":"example.com","location":"Europe","limit":5}` + "\n",
+		`{"target":"example.com","location":"Asia","limit":2}` + "\n",
+	}
+	if !reflect.DeepEqual(tr.bodies, exp) {
+		t.Fatalf("expected %q; got %q", exp, tr.bodies)
+	}
+
+	if _, err := c.Run.Ping(ctx, &RunRequest{Target: "example.com", Quotas: LocationQuotas{{"Europe", 15}, {"Asia", 10}}}); !IsArgError(err) {
+		t.Fatalf("expected limit error for more than %d nodes; got %v", freeMaxNodeCap, err)
+	}
+}
+
+func TestRunQuotasPartial(t *testing.T) {
+	ctx := context.Background()
+	tr := &sequenceTransport{resps: []*http.Response{
+		dummyResp(200, "POST", `{"id":"a1"}`),
+		dummyResp(200, "POST", `{"id":"b2"}`),
+		dummyResp(500, "POST", `{"error":"meep"}`),
+	}}
+	c, err := newTestClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	req := &RunRequest{Target: "example.com", Limit: 2, Quotas: LocationQuotas{{"Europe", 2}, {"Asia", 2}, {"Africa", 2}}}
+	id, err := c.Run.Ping(ctx, req)
+	var fe *FanOutError
+	if !errors.As(err, &fe) || fe.Location != "Africa" || fe.TestID != "a1,b2" {
+		t.Fatalf("expected a fan-out error for Africa; got %v", err)
+	}
+	if id != "a1,b2" {
+		t.Fatalf("expected the started tests a1,b2; got %v", id)
+	}
+
+	// Invalid quotas start no sub-test.
+	tr = &sequenceTransport{}
+	if c, err = newTestClient(tr); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	req = &RunRequest{Target: "example.com", Limit: 2, Quotas: LocationQuotas{{"Europe", 2}, {" ", 2}}}
+	if _, err := c.Run.Ping(ctx, req); !IsArgError(err) || len(tr.reqs) != 0 {
+		t.Fatalf("expected an argument error without requests; got %v, %d requests", err, len(tr.reqs))
+	}
+}
+
+func TestRunQuotasOutput(t *testing.T) {
+	ctx := context.Background()
+	tr := &sequenceTransport{resps: []*http.Response{
+		dummyResp(200, "GET", `{"id":"a1","requested":"example.com","finished":true,"items":[{"id":"x","result":{"finished":true}}],"creditsWithdrawn":1}`),
+		dummyResp(200, "GET", `{"id":"b2","requested":"example.com","finished":false,"items":[{"id":"y","result":{"finished":false}}],"creditsWithdrawn":2}`),
+		dummyResp(200, "GET", `{"id":"a1","finished":true,"items":[{"id":"x","result":{"output":"\"1.2.3.4\""}}]}`),
+		dummyResp(200, "GET", `{"id":"b2","finished":true,"items":[{"id":"y","result":{"output":"\"1.2.3.4\""}}]}`),
+	}}
+	c, err := newTestClient(tr)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	o, err := c.Run.PingOutput(ctx, "a1,b2")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got, exp := tr.reqs[1].URL.Path, "/run/ping/b2"; got != exp {
+		t.Fatalf("expected %v; got %v", exp, got)
+	}
+	if o.ID != "a1,b2" || o.Requested != "example.com" || o.IsFinished() || len(o.Items) != 2 || o.CreditsWithdrawn == nil || *o.CreditsWithdrawn != 3 {
+		b, _ := json.Marshal(o)
+		t.Fatalf("unexpected merged output %s", b)
+	}
+
+	d, err := c.Run.DNSResolveOutput(ctx, "a1,b2")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !d.IsFinished() || len(d.Items) != 2 {
+		b, _ := json.Marshal(d)
+		t.Fatalf("unexpected merged output %s", b)
+	}
+}
@@ -0,0 +1,354 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfops
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+type (
+	// DNSRecord represents a record of the answer of a DNS resolve test.
+	// Besides the record data, the fields of its type are set.
+	DNSRecord struct {
+		// Name is the owner name if the node reported it.
+		Name string `json:"name,omitempty"`
+		// TTL is the time to live in seconds if the node reported it.
+		TTL  *int   `json:"ttl,omitempty"`
+		Type string `json:"type"`
+		// Data is the record data as returned.
+		Data string `json:"data"`
+
+		// IP is the address of A and AAAA records.
+		IP net.IP `json:"ip,omitempty"`
+		// Target is the domain name of CNAME, NS and PTR records.
+		Target string     `json:"target,omitempty"`
+		MX     *MXData    `json:"mx,omitempty"`
+		SOA    *SOAData   `json:"soa,omitempty"`
+		SRV    *SRVData   `json:"srv,omitempty"`
+		TXT    []string   `json:"txt,omitempty"`
+		CAA    *CAAData   `json:"caa,omitempty"`
+		NAPTR  *NAPTRData `json:"naptr,omitempty"`
+	}
+
+	// MXData represents the data of a MX record.
+	MXData struct {
+		Preference uint16 `json:"preference"`
+		Exchange   string `json:"exchange"`
+	}
+
+	// SOAData represents the data of a SOA record.
+	SOAData struct {
+		MName   string `json:"mname"`
+		RName   string `json:"rname"`
+		Serial  uint32 `json:"serial"`
+		Refresh uint32 `json:"refresh"`
+		Retry   uint32 `json:"retry"`
+		Expire  uint32 `json:"expire"`
+		Minimum uint32 `json:"minimum"`
+	}
+
+	// SRVData represents the data of a SRV record.
+	SRVData struct {
+		Priority uint16 `json:"priority"`
+		Weight   uint16 `json:"weight"`
+		Port     uint16 `json:"port"`
+		Target   string `json:"target"`
+	}
+
+	// CAAData represents the data of a CAA record.
+	CAAData struct {
+		Flags uint8  `json:"flags"`
+		Tag   string `json:"tag"`
+		Value string `json:"value"`
+	}
+
+	// NAPTRData represents the data of a NAPTR record.
+	NAPTRData struct {
+		Order       uint16 `json:"order"`
+		Preference  uint16 `json:"preference"`
+		Flags       string `json:"flags"`
+		Service     string `json:"service"`
+		Regexp      string `json:"regexp"`
+		Replacement string `json:"replacement"`
+	}
+)
+
+// dnsTypes are the record types recognized in answers in zone file format.
+var dnsTypes = map[string]bool{
+	"A": true, "AAAA": true, "CAA": true, "CNAME": true, "DNAME": true,
+	"MX": true, "NAPTR": true, "NS": true, "PTR": true, "SOA": true,
+	"SPF": true, "SRV": true, "TXT": true,
+}
+
+// ResolveRecords returns the typed records of the output of a DNS resolve
+// request for queryType, e.g., "MX". The records that can be parsed are
+// returned along with an error for the first one that cannot.
+func (r *DNSTestResult) ResolveRecords(queryType string) ([]*DNSRecord, error) {
+	var (
+		res      []*DNSRecord
+		firstErr error
+	)
+	for _, s := range r.ResolveOutput() {
+		if s = strings.TrimSpace(s); s == "" || s == "-" {
+			continue
+		}
+		rec, err := ParseDNSRecord(queryType, s)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		res = append(res, rec)
+	}
+	return res, firstErr
+}
+
+// ParseDNSRecord parses an answer to a query of queryType. The answer is
+// either the record data, e.g., "10 mail.example.com.", or a record in
+// zone file format, e.g., "example.com. 300 IN MX 10 mail.example.com.",
+// whose type takes precedence over queryType. Domain names answering an
+// A or AAAA query are taken as CNAME records. The type of an empty
+// queryType is inferred from IP addresses only.
+func ParseDNSRecord(queryType, s string) (*DNSRecord, error) {
+	rec := &DNSRecord{Type: strings.ToUpper(strings.TrimSpace(queryType)), Data: strings.TrimSpace(s)}
+	parseZoneRecord(rec)
+	if ip := net.ParseIP(rec.Data); ip != nil && (rec.Type == "" || rec.Type == "A" || rec.Type == "AAAA") {
+		rec.Type = "A"
+		if ip.To4() == nil {
+			rec.Type = "AAAA"
+		}
+	} else if (rec.Type == "A" || rec.Type == "AAAA") && isDomainName(rec.Data) {
+		rec.Type = "CNAME"
+	}
+	if err := rec.parseData(); err != nil {
+		return nil, fmt.Errorf("invalid %s record '%s': %v", rec.Type, s, err)
+	}
+	return rec, nil
+}
+
+// parseZoneRecord takes the name, TTL and type of a record in zone file
+// format out of its data, e.g., "example.com. 300 IN A 93.184.216.34".
+func parseZoneRecord(rec *DNSRecord) {
+	fields := strings.Fields(rec.Data)
+	if len(fields) < 3 || strings.ContainsAny(fields[0], `"=`) {
+		return
+	}
+	var ttl *int
+	for i := 1; i < len(fields)-1 && i <= 3; i++ {
+		f := fields[i]
+		if dnsTypes[f] {
+			rec.Name, rec.TTL, rec.Type = fields[0], ttl, f
+			// Keep the whitespace of the data, e.g., of TXT strings.
+			rest := rec.Data
+			for j := 0; j <= i; j++ {
+				rest = strings.TrimSpace(rest)
+				rest = rest[len(fields[j]):]
+			}
+			rec.Data = strings.TrimSpace(rest)
+			return
+		}
+		if n, err := strconv.Atoi(f); err == nil && n >= 0 && ttl == nil {
+			ttl = &n
+			continue
+		}
+		if f != "IN" && f != "CH" && f != "HS" {
+			return
+		}
+	}
+}
+
+// parseData sets the fields of the type of the record from its data.
+func (r *DNSRecord) parseData() error {
+	fields, err := splitRData(r.Data)
+	if err != nil {
+		return err
+	}
+	want := func(n int) error {
+		if len(fields) != n {
+			return fmt.Errorf("expected %d fields, got %d", n, len(fields))
+		}
+		return nil
+	}
+	switch r.Type {
+	case "A", "AAAA":
+		ip := net.ParseIP(r.Data)
+		if ip == nil || (r.Type == "A") != (ip.To4() != nil) {
+			return fmt.Errorf("not an IPv%s address", map[string]string{"A": "4", "AAAA": "6"}[r.Type])
+		}
+		r.IP = ip
+	case "CNAME", "DNAME", "NS", "PTR":
+		if err := want(1); err != nil {
+			return err
+		}
+		r.Target = fields[0]
+	case "MX":
+		if err := want(2); err != nil {
+			return err
+		}
+		mx := &MXData{Exchange: fields[1]}
+		if err := parseUint(fields[0], 16, &mx.Preference); err != nil {
+			return err
+		}
+		r.MX = mx
+	case "SOA":
+		if err := want(7); err != nil {
+			return err
+		}
+		soa := &SOAData{MName: fields[0], RName: fields[1]}
+		for i, v := range []*uint32{&soa.Serial, &soa.Refresh, &soa.Retry, &soa.Expire, &soa.Minimum} {
+			n, err := strconv.ParseUint(fields[i+2], 10, 32)
+			if err != nil {
+				return err
+			}
+			*v = uint32(n)
+		}
+		r.SOA = soa
+	case "SRV":
+		if err := want(4); err != nil {
+			return err
+		}
+		srv := &SRVData{Target: fields[3]}
+		for i, v := range []*uint16{&srv.Priority, &srv.Weight, &srv.Port} {
+			if err := parseUint(fields[i], 16, v); err != nil {
+				return err
+			}
+		}
+		r.SRV = srv
+	case "TXT", "SPF":
+		r.TXT = fields
+		if !strings.HasPrefix(r.Data, `"`) {
+			// Unquoted data is a single string.
+			r.TXT = []string{r.Data}
+		}
+	case "CAA":
+		if err := want(3); err != nil {
+			return err
+		}
+		flags, err := strconv.ParseUint(fields[0], 10, 8)
+		if err != nil {
+			return err
+		}
+		r.CAA = &CAAData{Flags: uint8(flags), Tag: fields[1], Value: fields[2]}
+	case "NAPTR":
+		if err := want(6); err != nil {
+			return err
+		}
+		naptr := &NAPTRData{Flags: fields[2], Service: fields[3], Regexp: fields[4], Replacement: fields[5]}
+		for i, v := range []*uint16{&naptr.Order, &naptr.Preference} {
+			if err := parseUint(fields[i], 16, v); err != nil {
+				return err
+			}
+		}
+		r.NAPTR = naptr
+	}
+	return nil
+}
+
+// Value returns the data of the record in a canonical form to compare
+// records, e.g., IP addresses in their shortest form and domain names in
+// lower case without the trailing dot.
+func (r *DNSRecord) Value() string {
+	name := func(s string) string {
+		return strings.TrimSuffix(strings.ToLower(s), ".")
+	}
+	switch {
+	case r.IP != nil:
+		return r.IP.String()
+	case r.Target != "":
+		return name(r.Target)
+	case r.MX != nil:
+		return fmt.Sprintf("%d %s", r.MX.Preference, name(r.MX.Exchange))
+	case r.SRV != nil:
+		return fmt.Sprintf("%d %d %d %s", r.SRV.Priority, r.SRV.Weight, r.SRV.Port, name(r.SRV.Target))
+	case r.SOA != nil:
+		s := r.SOA
+		return fmt.Sprintf("%s %s %d %d %d %d %d", name(s.MName), name(s.RName), s.Serial, s.Refresh, s.Retry, s.Expire, s.Minimum)
+	case r.TXT != nil:
+		return strings.Join(r.TXT, "")
+	case r.CAA != nil:
+		return fmt.Sprintf("%d %s %q", r.CAA.Flags, strings.ToLower(r.CAA.Tag), r.CAA.Value)
+	case r.NAPTR != nil:
+		n := r.NAPTR
+		return fmt.Sprintf("%d %d %q %q %q %s", n.Order, n.Preference, n.Flags, n.Service, n.Regexp, name(n.Replacement))
+	}
+	return r.Data
+}
+
+func parseUint(s string, bitSize int, v *uint16) error {
+	n, err := strconv.ParseUint(s, 10, bitSize)
+	if err != nil {
+		return err
+	}
+	*v = uint16(n)
+	return nil
+}
+
+// splitRData splits record data into its fields. Quoted character strings
+// are unquoted, e.g., `"v=spf1 -all"`.
+func splitRData(s string) ([]string, error) {
+	var (
+		res []string
+		b   strings.Builder
+	)
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '"':
+			b.Reset()
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+					if i+2 < len(s) && isDigit(s[i]) && isDigit(s[i+1]) && isDigit(s[i+2]) {
+						n, _ := strconv.Atoi(s[i : i+3])
+						b.WriteByte(byte(n))
+						i += 2
+						continue
+					}
+				}
+				b.WriteByte(s[i])
+			}
+			if i == len(s) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			i++
+			res = append(res, b.String())
+		default:
+			j := strings.IndexAny(s[i:], " \t")
+			if j < 0 {
+				j = len(s) - i
+			}
+			res = append(res, s[i:i+j])
+			i += j
+		}
+	}
+	return res, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isDomainName reports whether s looks like a domain name.
+func isDomainName(s string) bool {
+	if s == "" || strings.ContainsAny(s, " \t\"") {
+		return false
+	}
+	return strings.Contains(strings.TrimSuffix(s, "."), ".")
+}
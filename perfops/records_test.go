@@ -0,0 +1,103 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfops
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestParseDNSRecord(t *testing.T) {
+	ttl := func(n int) *int { return &n }
+	testCases := map[string]struct {
+		queryType string
+		answer    string
+		exp       *DNSRecord
+		value     string
+	}{
+		"A":          {"A", "93.184.216.34", &DNSRecord{Type: "A", Data: "93.184.216.34", IP: net.ParseIP("93.184.216.34")}, "93.184.216.34"},
+		"AAAA":       {"AAAA", "2606:2800:0220:0001::", &DNSRecord{Type: "AAAA", Data: "2606:2800:0220:0001::", IP: net.ParseIP("2606:2800:220:1::")}, "2606:2800:220:1::"},
+		"Inferred":   {"", "2606:2800:220:1::", &DNSRecord{Type: "AAAA", Data: "2606:2800:220:1::", IP: net.ParseIP("2606:2800:220:1::")}, "2606:2800:220:1::"},
+		"CNAME in A": {"A", "Edge.Example.NET.", &DNSRecord{Type: "CNAME", Data: "Edge.Example.NET.", Target: "Edge.Example.NET."}, "edge.example.net"},
+		"Zone file":  {"A", "example.com. 300 IN A 93.184.216.34", &DNSRecord{Name: "example.com.", TTL: ttl(300), Type: "A", Data: "93.184.216.34", IP: net.ParseIP("93.184.216.34")}, "93.184.216.34"},
+		"No TTL":     {"", "example.com. IN CNAME edge.example.net.", &DNSRecord{Name: "example.com.", Type: "CNAME", Data: "edge.example.net.", Target: "edge.example.net."}, "edge.example.net"},
+		"MX":         {"MX", "10 mail.example.com.", &DNSRecord{Type: "MX", Data: "10 mail.example.com.", MX: &MXData{Preference: 10, Exchange: "mail.example.com."}}, "10 mail.example.com"},
+		"SOA": {"SOA", "ns.icann.org. noc.dns.icann.org. 2024010101 7200 3600 1209600 3600", &DNSRecord{Type: "SOA", Data: "ns.icann.org. noc.dns.icann.org. 2024010101 7200 3600 1209600 3600",
+			SOA: &SOAData{MName: "ns.icann.org.", RName: "noc.dns.icann.org.", Serial: 2024010101, Refresh: 7200, Retry: 3600, Expire: 1209600, Minimum: 3600}}, "ns.icann.org noc.dns.icann.org 2024010101 7200 3600 1209600 3600"},
+		"SRV": {"SRV", "_sip._tcp.example.com. 60 IN SRV 10 5 5060 sip.example.com.", &DNSRecord{Name: "_sip._tcp.example.com.", TTL: ttl(60), Type: "SRV", Data: "10 5 5060 sip.example.com.",
+			SRV: &SRVData{Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com."}}, "10 5 5060 sip.example.com"},
+		"TXT":          {"TXT", `"v=spf1 include:_spf.example.com" " -all"`, &DNSRecord{Type: "TXT", Data: `"v=spf1 include:_spf.example.com" " -all"`, TXT: []string{"v=spf1 include:_spf.example.com", " -all"}}, "v=spf1 include:_spf.example.com -all"},
+		"Unquoted TXT": {"TXT", "v=spf1 MX -all", &DNSRecord{Type: "TXT", Data: "v=spf1 MX -all", TXT: []string{"v=spf1 MX -all"}}, "v=spf1 MX -all"},
+		"Escaped TXT":  {"TXT", `example.com. 300 IN TXT "say \"hi\"\0330"`, &DNSRecord{Name: "example.com.", TTL: ttl(300), Type: "TXT", Data: `"say \"hi\"\0330"`, TXT: []string{"say \"hi\"!0"}}, "say \"hi\"!0"},
+		"CAA":          {"CAA", `0 issue "letsencrypt.org"`, &DNSRecord{Type: "CAA", Data: `0 issue "letsencrypt.org"`, CAA: &CAAData{Flags: 0, Tag: "issue", Value: "letsencrypt.org"}}, `0 issue "letsencrypt.org"`},
+		"NAPTR": {"NAPTR", `100 10 "U" "E2U+sip" "!^.*$!sip:info@example.com!" .`, &DNSRecord{Type: "NAPTR", Data: `100 10 "U" "E2U+sip" "!^.*$!sip:info@example.com!" .`,
+			NAPTR: &NAPTRData{Order: 100, Preference: 10, Flags: "U", Service: "E2U+sip", Regexp: "!^.*$!sip:info@example.com!", Replacement: "."}}, `100 10 "U" "E2U+sip" "!^.*$!sip:info@example.com!" `},
+		"NS":    {"NS", "a.iana-servers.net.", &DNSRecord{Type: "NS", Data: "a.iana-servers.net.", Target: "a.iana-servers.net."}, "a.iana-servers.net"},
+		"Other": {"HINFO", "x y", &DNSRecord{Type: "HINFO", Data: "x y"}, "x y"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseDNSRecord(tc.queryType, tc.answer)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.exp) {
+				a, _ := json.Marshal(got)
+				b, _ := json.Marshal(tc.exp)
+				t.Fatalf("expected %s; got %s", b, a)
+			}
+			if got, exp := got.Value(), tc.value; got != exp {
+				t.Fatalf("expected value %q; got %q", exp, got)
+			}
+		})
+	}
+}
+
+func TestParseDNSRecordErrors(t *testing.T) {
+	testCases := map[string]struct {
+		queryType string
+		answer    string
+	}{
+		"A":     {"A", "not an address"},
+		"MX":    {"MX", "ten mail.example.com."},
+		"SOA":   {"SOA", "ns.icann.org. noc.dns.icann.org. 1 2 3"},
+		"SRV":   {"SRV", "10 5 99999 sip.example.com."},
+		"CAA":   {"CAA", `0 issue "letsencrypt.org`},
+		"NAPTR": {"NAPTR", `100 10 "U" "E2U+sip"`},
+		"CNAME": {"CNAME", "a b"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if got, err := ParseDNSRecord(tc.queryType, tc.answer); err == nil {
+				t.Fatalf("expected error; got %+v", got)
+			}
+		})
+	}
+}
+
+func TestDNSTestResult_ResolveRecords(t *testing.T) {
+	var r DNSTestResult
+	if err := json.Unmarshal([]byte(`{"output":"10 mx1.example.com.\nbroken\n20 mx2.example.com."}`), &r); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	records, err := r.ResolveRecords("mx")
+	if err == nil {
+		t.Fatal("expected error for the broken record")
+	}
+	if len(records) != 2 || records[0].MX.Exchange != "mx1.example.com." || records[1].MX.Preference != 20 {
+		t.Fatalf("expected the valid records; got %+v", records)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -50,6 +51,8 @@ type (
 		Limit int `json:"limit,omitempty"`
 		// IP Version
 		IPVersion int `json:"ipversion,omitempty"`
+		// Per-location node limits, overrides Location and Limit
+		Quotas LocationQuotas `json:"-"`
 	}
 
 	// RunResult represents the result of an MTR or ping run.
@@ -57,7 +60,7 @@ type (
 		Node     *Node       `json:"node,omitempty"`
 		Output   interface{} `json:"output,omitempty"`
 		Message  string      `json:"message,omitempty"`
-		Finished interface{} `json:"finished"`
+		Finished Finished    `json:"finished"`
 		Timing   *RunTiming  `json:"timing,omitempty"`
 	}
 
@@ -71,8 +74,11 @@ type (
 	RunOutput struct {
 		ID        string     `json:"id,omitempty"`
 		Requested string     `json:"requested,omitempty"`
-		Finished  bool       `json:"finished"`
+		Finished  Finished   `json:"finished"`
 		Items     []*RunItem `json:"items,omitempty"`
+		// CreditsWithdrawn is the number of credits the test used, nil
+		// if not reported.
+		CreditsWithdrawn *int `json:"creditsWithdrawn,omitempty"`
 	}
 
 	// RunTiming represents the test timings.
@@ -90,7 +96,9 @@ type (
 		Nodes     NodeIDs `json:"nodes,omitempty"`
 		Location  string  `json:"location,omitempty"`
 		Limit     int     `json:"limit,omitempty"`
-		IPVersion int     `json:"ipversion,omitempty""`
+		IPVersion int     `json:"ipversion,omitempty"`
+		// Per-location node limits, overrides Location and Limit
+		Quotas LocationQuotas `json:"-"`
 	}
 
 	// DNSResolveRequest represents the parameters for a DNS resolve request.
@@ -101,6 +109,8 @@ type (
 		Nodes     NodeIDs `json:"nodes,omitempty"`
 		Location  string  `json:"location,omitempty"`
 		Limit     int     `json:"limit,omitempty"`
+		// Per-location node limits, overrides Location and Limit
+		Quotas LocationQuotas `json:"-"`
 	}
 
 	// DNSTestResult represents the result of a DNS perf and DNS resolve output.
@@ -121,8 +131,11 @@ type (
 	DNSTestOutput struct {
 		ID        string         `json:"id,omitempty"`
 		Requested string         `json:"requested,omitempty"`
-		Finished  bool           `json:"finished"`
+		Finished  Finished       `json:"finished"`
 		Items     []*DNSTestItem `json:"items,omitempty"`
+		// CreditsWithdrawn is the number of credits the test used, nil
+		// if not reported.
+		CreditsWithdrawn *int `json:"creditsWithdrawn,omitempty"`
 	}
 
 	// CurlRequest represents the parameters for a curl request.
@@ -135,6 +148,8 @@ type (
 		Location  string  `json:"location,omitempty"`
 		Limit     int     `json:"limit,omitempty"`
 		IPVersion int     `json:"ipversion,omitempty"`
+		// Per-location node limits, overrides Location and Limit
+		Quotas LocationQuotas `json:"-"`
 	}
 
 	argError struct {
@@ -238,11 +253,15 @@ func (s *RunService) TracerouteOutput(ctx context.Context, pingID TestID) (*RunO
 
 // DNSPerf finds the time it takes to resolve a DNS record.
 func (s *RunService) DNSPerf(ctx context.Context, perf *DNSPerfRequest) (TestID, error) {
-	if !isValidTarget(perf.Target) {
-		return "", &argError{"target"}
+	if err := perf.validate(); err != nil {
+		return "", err
 	}
-	if perf.DNSServer != "" && !isValidTarget(perf.DNSServer) {
-		return "", &argError{"dns server"}
+	if len(perf.Quotas) > 0 {
+		return s.fanOut(perf.Quotas, perf.Limit, func(location string, limit int) (TestID, error) {
+			r := *perf
+			r.Location, r.Limit, r.Quotas = location, limit, nil
+			return s.DNSPerf(ctx, &r)
+		})
 	}
 	if !isValidLimit(s.client.apiKey, perf.Limit) {
 		return "", &argError{"limit"}
@@ -270,23 +289,20 @@ func (s *RunService) DNSPerf(ctx context.Context, perf *DNSPerfRequest) (TestID,
 
 // DNSPerfOutput returns the full DNS perf output under a test ID.
 func (s *RunService) DNSPerfOutput(ctx context.Context, perfID TestID) (*DNSTestOutput, error) {
-	u := s.client.BasePath + "/run/dns-perf/" + string(perfID)
-	req, _ := http.NewRequest("GET", u, nil)
-	var v *DNSTestOutput
-	err := s.client.do(req, &v)
-	return v, err
+	return s.doGetDNSTestOutput(ctx, "/run/dns-perf/", perfID)
 }
 
 // DNSResolve resolves a DNS record.
 func (s *RunService) DNSResolve(ctx context.Context, resolve *DNSResolveRequest) (TestID, error) {
-	if !isValidTarget(resolve.Target) {
-		return "", &argError{"target"}
-	}
-	if resolve.Param == "" {
-		return "", &argError{"param"}
+	if err := resolve.validate(); err != nil {
+		return "", err
 	}
-	if !isValidTarget(resolve.DNSServer) {
-		return "", &argError{"dns server"}
+	if len(resolve.Quotas) > 0 {
+		return s.fanOut(resolve.Quotas, resolve.Limit, func(location string, limit int) (TestID, error) {
+			r := *resolve
+			r.Location, r.Limit, r.Quotas = location, limit, nil
+			return s.DNSResolve(ctx, &r)
+		})
 	}
 	if !isValidLimit(s.client.apiKey, resolve.Limit) {
 		return "", &argError{"limit"}
@@ -314,17 +330,20 @@ func (s *RunService) DNSResolve(ctx context.Context, resolve *DNSResolveRequest)
 
 // DNSResolveOutput returns the full DNS resolve output under a test ID.
 func (s *RunService) DNSResolveOutput(ctx context.Context, resolveID TestID) (*DNSTestOutput, error) {
-	u := s.client.BasePath + "/run/dns-resolve/" + string(resolveID)
-	req, _ := http.NewRequest("GET", u, nil)
-	var v *DNSTestOutput
-	err := s.client.do(req, &v)
-	return v, err
+	return s.doGetDNSTestOutput(ctx, "/run/dns-resolve/", resolveID)
 }
 
 // Curl runs a curl request.
 func (s *RunService) Curl(ctx context.Context, curl *CurlRequest) (TestID, error) {
-	if !isValidTarget(curl.Target) {
-		return "", &argError{"target"}
+	if err := curl.validate(); err != nil {
+		return "", err
+	}
+	if len(curl.Quotas) > 0 {
+		return s.fanOut(curl.Quotas, curl.Limit, func(location string, limit int) (TestID, error) {
+			r := *curl
+			r.Location, r.Limit, r.Quotas = location, limit, nil
+			return s.Curl(ctx, &r)
+		})
 	}
 	if !isValidLimit(s.client.apiKey, curl.Limit) {
 		return "", &argError{"limit"}
@@ -352,35 +371,56 @@ func (s *RunService) Curl(ctx context.Context, curl *CurlRequest) (TestID, error
 
 // CurlOutput returns the full curl output under a test ID.
 func (s *RunService) CurlOutput(ctx context.Context, curlID TestID) (*RunOutput, error) {
-	u := s.client.BasePath + "/run/curl/" + string(curlID)
-	req, _ := http.NewRequest("GET", u, nil)
-	var v *RunOutput
-	err := s.client.do(req, &v)
-	return v, err
+	return s.doGetRunOutput(ctx, "/run/curl/", curlID)
 }
 
 // IsFinished returns a value indicating whether the run result is
 // complete or not.
 func (r *RunResult) IsFinished() bool {
-	if v, ok := r.Finished.(bool); ok {
-		return v
-	}
-	if v, ok := r.Finished.(string); ok {
-		return v == "true"
-	}
-	return false
+	return bool(r.Finished)
 }
 
 // IsFinished returns a value indicating whether the whole output is
 // complete or not.
 func (o *RunOutput) IsFinished() bool {
-	return o.Finished == true
+	return bool(o.Finished)
 }
 
 // IsFinished returns a value indicating whether the whole output is
 // complete or not.
 func (o *DNSTestOutput) IsFinished() bool {
-	return o.Finished == true
+	return bool(o.Finished)
+}
+
+// NodeIDs returns the sorted IDs of the nodes the test ran on.
+func (o *RunOutput) NodeIDs() NodeIDs {
+	var ids NodeIDs
+	for _, item := range o.Items {
+		if item.Result != nil && item.Result.Node != nil {
+			ids = appendNodeID(ids, item.Result.Node.ID)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// NodeIDs returns the sorted IDs of the nodes the test ran on.
+func (o *DNSTestOutput) NodeIDs() NodeIDs {
+	var ids NodeIDs
+	for _, item := range o.Items {
+		if item.Result != nil && item.Result.Node != nil {
+			ids = appendNodeID(ids, item.Result.Node.ID)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func appendNodeID(ids NodeIDs, id int) NodeIDs {
+	if containsInt(ids, id) {
+		return ids
+	}
+	return append(ids, id)
 }
 
 // PerfOutput returns the unmarshalled output for DNS perf requests.
@@ -405,6 +445,76 @@ func (r *DNSTestResult) ResolveOutput() []string {
 	return strings.Split(o2, "\n")
 }
 
+// Validate checks a test request like the run methods do without
+// submitting it. req is a *RunRequest, *DNSPerfRequest, *DNSResolveRequest
+// or *CurlRequest.
+func (s *RunService) Validate(req interface{}) error {
+	var (
+		err    error
+		quotas LocationQuotas
+		limit  int
+	)
+	switch r := req.(type) {
+	case *RunRequest:
+		err, quotas, limit = r.validate(), r.Quotas, r.Limit
+	case *DNSPerfRequest:
+		err, quotas, limit = r.validate(), r.Quotas, r.Limit
+	case *DNSResolveRequest:
+		err, quotas, limit = r.validate(), r.Quotas, r.Limit
+	case *CurlRequest:
+		err, quotas, limit = r.validate(), r.Quotas, r.Limit
+	default:
+		return fmt.Errorf("unsupported request type %T", req)
+	}
+	if err != nil {
+		return err
+	}
+	if len(quotas) > 0 {
+		limit = quotas.Total(limit)
+	}
+	if !isValidLimit(s.client.apiKey, limit) {
+		return &argError{"limit"}
+	}
+	return nil
+}
+
+func (r *RunRequest) validate() error {
+	if !isValidTarget(r.Target) {
+		return &argError{"target"}
+	}
+	return nil
+}
+
+func (r *DNSPerfRequest) validate() error {
+	if !isValidTarget(r.Target) {
+		return &argError{"target"}
+	}
+	if r.DNSServer != "" && !isValidTarget(r.DNSServer) {
+		return &argError{"dns server"}
+	}
+	return nil
+}
+
+func (r *DNSResolveRequest) validate() error {
+	if !isValidTarget(r.Target) {
+		return &argError{"target"}
+	}
+	if r.Param == "" {
+		return &argError{"param"}
+	}
+	if !isValidTarget(r.DNSServer) {
+		return &argError{"dns server"}
+	}
+	return nil
+}
+
+func (r *CurlRequest) validate() error {
+	if !isValidTarget(r.Target) {
+		return &argError{"target"}
+	}
+	return nil
+}
+
 // isValidTarget checks if a string is a valid target, i.e., a public
 // domain name or an IP address.
 func isValidTarget(s string) bool {
@@ -433,8 +543,15 @@ func isValidLimit(apiKey string, limit int) bool {
 }
 
 func (s *RunService) doPostRunRequest(ctx context.Context, path string, runReq *RunRequest) (TestID, error) {
-	if !isValidTarget(runReq.Target) {
-		return "", &argError{"target"}
+	if err := runReq.validate(); err != nil {
+		return "", err
+	}
+	if len(runReq.Quotas) > 0 {
+		return s.fanOut(runReq.Quotas, runReq.Limit, func(location string, limit int) (TestID, error) {
+			r := *runReq
+			r.Location, r.Limit, r.Quotas = location, limit, nil
+			return s.doPostRunRequest(ctx, path, &r)
+		})
 	}
 	if !isValidLimit(s.client.apiKey, runReq.Limit) {
 		return "", &argError{"limit"}
@@ -461,9 +578,39 @@ func (s *RunService) doPostRunRequest(ctx context.Context, path string, runReq *
 }
 
 func (s *RunService) doGetRunOutput(ctx context.Context, path string, testID TestID) (*RunOutput, error) {
+	if parts := testID.Parts(); len(parts) > 1 {
+		outputs := make([]*RunOutput, len(parts))
+		for i, id := range parts {
+			o, err := s.doGetRunOutput(ctx, path, id)
+			if err != nil {
+				return nil, err
+			}
+			outputs[i] = o
+		}
+		return mergeRunOutputs(testID, outputs), nil
+	}
 	u := s.client.BasePath + path + string(testID)
 	req, _ := http.NewRequest("GET", u, nil)
 	var v *RunOutput
 	err := s.client.do(req, &v)
 	return v, err
 }
+
+func (s *RunService) doGetDNSTestOutput(ctx context.Context, path string, testID TestID) (*DNSTestOutput, error) {
+	if parts := testID.Parts(); len(parts) > 1 {
+		outputs := make([]*DNSTestOutput, len(parts))
+		for i, id := range parts {
+			o, err := s.doGetDNSTestOutput(ctx, path, id)
+			if err != nil {
+				return nil, err
+			}
+			outputs[i] = o
+		}
+		return mergeDNSTestOutputs(testID, outputs), nil
+	}
+	u := s.client.BasePath + path + string(testID)
+	req, _ := http.NewRequest("GET", u, nil)
+	var v *DNSTestOutput
+	err := s.client.do(req, &v)
+	return v, err
+}
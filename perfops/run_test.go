@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"testing"
@@ -559,6 +560,37 @@ func TestResolveOuput(t *testing.T) {
 	}
 }
 
+func TestValidate(t *testing.T) {
+	testCases := map[string]struct {
+		req interface{}
+		err error
+	}{
+		"Run":                {&RunRequest{Target: "example.com", Limit: 1}, nil},
+		"Run invalid target": {&RunRequest{Target: "meep"}, &argError{"target"}},
+		"Run quotas":         {&RunRequest{Target: "example.com", Limit: 2, Quotas: LocationQuotas{{"Europe", 5}, {"Asia", 0}}}, nil},
+		"Run quotas limit":   {&RunRequest{Target: "example.com", Quotas: LocationQuotas{{"Europe", 15}, {"Asia", 10}}}, &argError{"limit"}},
+		"DNS perf server":    {&DNSPerfRequest{Target: "example.com", DNSServer: "127.0"}, &argError{"dns server"}},
+		"DNS resolve param":  {&DNSResolveRequest{Target: "example.com", DNSServer: "127.0.0.1"}, &argError{"param"}},
+		"Curl limit":         {&CurlRequest{Target: "example.com", Limit: freeMaxNodeCap + 1}, &argError{"limit"}},
+		"Unsupported":        {"ping", errors.New("unsupported request type string")},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tr := &recordingTransport{}
+			c, err := newTestClient(tr)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if err := c.Run.Validate(tc.req); !cmpError(err, tc.err) {
+				t.Fatalf("expected %v; got %v", tc.err, err)
+			}
+			if tr.req != nil {
+				t.Fatalf("expected no request; got %v", tr.req.URL)
+			}
+		})
+	}
+}
+
 func TestIsValidTarget(t *testing.T) {
 	testCases := map[string]struct {
 		t     string
@@ -611,7 +643,7 @@ func TestIsValidLimit(t *testing.T) {
 }
 
 func TestDoPostRunRequest(t *testing.T) {
-	errDummyTr := errors.New("Post https://api.perfops.net/run/test: dummy impl")
+	errDummyTr := errors.New(`Post "https://api.perfops.net/run/test": dummy impl`)
 	reqTestCases := map[string]struct {
 		runReq     RunRequest
 		tr         *recordingTransport
@@ -715,3 +747,22 @@ func reqBody(req *http.Request) string {
 	}
 	return string(bytes.TrimSpace(b))
 }
+
+func TestOutputNodeIDs(t *testing.T) {
+	o := &RunOutput{Items: []*RunItem{
+		{Result: &RunResult{Node: &Node{ID: 27}}},
+		{Result: &RunResult{Node: &Node{ID: 5}}},
+		{Result: &RunResult{Node: &Node{ID: 27}}},
+		{Result: &RunResult{}},
+	}}
+	if got, exp := fmt.Sprint(o.NodeIDs()), "[5 27]"; got != exp {
+		t.Fatalf("expected %v; got %v", exp, got)
+	}
+	d := &DNSTestOutput{Items: []*DNSTestItem{
+		{Result: &DNSTestResult{Node: &Node{ID: 7}}},
+		{},
+	}}
+	if got, exp := fmt.Sprint(d.NodeIDs()), "[7]"; got != exp {
+		t.Fatalf("expected %v; got %v", exp, got)
+	}
+}
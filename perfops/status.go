@@ -0,0 +1,105 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfops
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Status represents the normalized state of the result of a node.
+type Status string
+
+const (
+	// StatusPending is the status of a result the node is still working on.
+	StatusPending Status = "pending"
+	// StatusOK is the status of a successful result.
+	StatusOK Status = "ok"
+	// StatusTimeout is the status of a result that timed out.
+	StatusTimeout Status = "timeout"
+	// StatusError is the status of a failed result.
+	StatusError Status = "error"
+	// StatusNoData is the status of a result without data. The API
+	// reports no data for nodes that have not answered yet as well, so
+	// whether more data may follow depends on the test being finished.
+	StatusNoData Status = "no-data"
+)
+
+const (
+	// msgNoData is the message of results without data.
+	msgNoData = "NO DATA"
+	// outputTimeout is the output of results that timed out.
+	outputTimeout = "-2"
+	// reasonTimeout explains the timeout status.
+	reasonTimeout = "The command timed-out. It either took too long to execute or we could not connect to your target at all."
+)
+
+// Finished represents whether a test or the result of a node is complete.
+// The API encodes it either as a boolean or as a string.
+type Finished bool
+
+// MarshalJSON returns the JSON encoding of f as a boolean.
+func (f Finished) MarshalJSON() ([]byte, error) {
+	return json.Marshal(bool(f))
+}
+
+// UnmarshalJSON parses the JSON-encoded data. Booleans and the strings
+// "true" and "false" are accepted, anything else is not finished.
+func (f *Finished) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(bytes.TrimSpace(data)), `"`)
+	*f = Finished(strings.EqualFold(s, "true"))
+	return nil
+}
+
+// IsFailed returns a value indicating whether the status represents a
+// failed result, e.g., a timeout or an error.
+func (s Status) IsFailed() bool {
+	return s == StatusTimeout || s == StatusError
+}
+
+// Status returns the status of the result and the reason for a timeout,
+// an error or missing data.
+func (r *RunResult) Status() (Status, string) {
+	if s, ok := r.Output.(string); ok && s == outputTimeout {
+		return StatusTimeout, reasonTimeout
+	}
+	switch {
+	case r.Message == msgNoData:
+		return StatusNoData, r.Message
+	case r.Message != "":
+		return StatusError, r.Message
+	case !r.IsFinished():
+		return StatusPending, ""
+	}
+	return StatusOK, ""
+}
+
+// Status returns the status of the result and the reason for a timeout,
+// an error or missing data.
+func (r *DNSTestResult) Status() (Status, string) {
+	var s string
+	if json.Unmarshal(r.Output, &s) == nil && s == outputTimeout {
+		return StatusTimeout, reasonTimeout
+	}
+	switch {
+	case r.Message == msgNoData:
+		return StatusNoData, r.Message
+	case r.Message != "":
+		return StatusError, r.Message
+	case len(r.Output) == 0 || string(r.Output) == "null":
+		return StatusPending, ""
+	}
+	return StatusOK, ""
+}
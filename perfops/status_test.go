@@ -0,0 +1,100 @@
+// Copyright 2017 Prospect One https://prospectone.io/. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfops
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFinishedJSON(t *testing.T) {
+	testCases := map[string]struct {
+		data string
+		exp  Finished
+	}{
+		"Bool true":    {`true`, true},
+		"Bool false":   {`false`, false},
+		"String true":  {`"true"`, true},
+		"String false": {`"false"`, false},
+		"Null":         {`null`, false},
+		"Empty":        {`""`, false},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var f Finished
+			if err := json.Unmarshal([]byte(tc.data), &f); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if f != tc.exp {
+				t.Fatalf("expected %v; got %v", tc.exp, f)
+			}
+		})
+	}
+	b, err := json.Marshal(&RunResult{Finished: true})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got, exp := string(b), `{"finished":true}`; got != exp {
+		t.Fatalf("expected %v; got %v", exp, got)
+	}
+}
+
+func TestRunResultStatus(t *testing.T) {
+	testCases := map[string]struct {
+		result    RunResult
+		exp       Status
+		expReason string
+	}{
+		"OK":              {RunResult{Output: "121", Finished: true}, StatusOK, ""},
+		"Pending":         {RunResult{Output: ""}, StatusPending, ""},
+		"No data":         {RunResult{Message: "NO DATA", Finished: true}, StatusNoData, "NO DATA"},
+		"Pending no data": {RunResult{Message: "NO DATA"}, StatusNoData, "NO DATA"},
+		"Error":           {RunResult{Message: "Unknown host", Finished: true}, StatusError, "Unknown host"},
+		"Timeout":         {RunResult{Output: "-2", Finished: true}, StatusTimeout, reasonTimeout},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			status, reason := tc.result.Status()
+			if status != tc.exp || reason != tc.expReason {
+				t.Fatalf("expected %v, %q; got %v, %q", tc.exp, tc.expReason, status, reason)
+			}
+		})
+	}
+}
+
+func TestDNSTestResultStatus(t *testing.T) {
+	testCases := map[string]struct {
+		result    DNSTestResult
+		exp       Status
+		expReason string
+	}{
+		"OK":             {DNSTestResult{Output: json.RawMessage(`["1.2.3.4"]`)}, StatusOK, ""},
+		"Pending":        {DNSTestResult{}, StatusPending, ""},
+		"No data":        {DNSTestResult{Message: "NO DATA"}, StatusNoData, "NO DATA"},
+		"No data output": {DNSTestResult{Message: "NO DATA", Output: json.RawMessage(`[]`)}, StatusNoData, "NO DATA"},
+		"Error":          {DNSTestResult{Message: "SERVFAIL"}, StatusError, "SERVFAIL"},
+		"Timeout":        {DNSTestResult{Output: json.RawMessage(`"-2"`)}, StatusTimeout, reasonTimeout},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			status, reason := tc.result.Status()
+			if status != tc.exp || reason != tc.expReason {
+				t.Fatalf("expected %v, %q; got %v, %q", tc.exp, tc.expReason, status, reason)
+			}
+		})
+	}
+	if !StatusTimeout.IsFailed() || !StatusError.IsFailed() || StatusNoData.IsFailed() {
+		t.Fatal("expected only timeouts and errors to be failures")
+	}
+}